@@ -0,0 +1,144 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	cmdtpl "carvel.dev/ytt/pkg/cmd/template"
+	"carvel.dev/ytt/pkg/cmd/ui"
+	"carvel.dev/ytt/pkg/files"
+	"carvel.dev/ytt/pkg/schema"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"github.com/spf13/cobra"
+)
+
+func NewSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Work with data values schemas",
+	}
+	cmd.AddCommand(NewSchemaValidateCmd(NewSchemaValidateOptions()))
+	return cmd
+}
+
+type SchemaValidateOptions struct {
+	Schema string
+	Values string
+	Debug  bool
+}
+
+func NewSchemaValidateOptions() *SchemaValidateOptions {
+	return &SchemaValidateOptions{}
+}
+
+func NewSchemaValidateCmd(o *SchemaValidateOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check a values file against a schema",
+		Long: `Check a values file against a schema: either a JSON Schema (e.g. as exported via
+--data-values-schema-inspect -o json-schema), or a ytt schema file (i.e. annotated @data/values-schema).`,
+		RunE: func(_ *cobra.Command, _ []string) error { return o.Run() },
+	}
+	cmd.Flags().StringVar(&o.Schema, "schema", "", "Schema file (ie local path, HTTP URL, -): a JSON Schema, or a ytt schema file")
+	cmd.Flags().StringVar(&o.Values, "values", "", "Values file (ie local path, HTTP URL, -) to check")
+	cmd.Flags().BoolVar(&o.Debug, "debug", false, "Enable debug output")
+	return cmd
+}
+
+func (o *SchemaValidateOptions) Run() error {
+	if o.Schema == "" || o.Values == "" {
+		return fmt.Errorf("Expected both --schema and --values to be specified")
+	}
+
+	schemaFile, err := o.fileAt(o.Schema)
+	if err != nil {
+		return err
+	}
+	validate, err := o.validatorOf(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	valuesFile, err := o.fileAt(o.Values)
+	if err != nil {
+		return err
+	}
+	valuesDocSet, err := o.parse(valuesFile)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, doc := range valuesDocSet.Items {
+		if doc.IsEmpty() {
+			continue
+		}
+		for _, err := range validate(doc) {
+			errs = append(errs, "- "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Values in '%s' do not conform to schema '%s':\n%s", o.Values, o.Schema, strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// validatorOf gives the check of values against the schema in `file`: either a JSON Schema, or a ytt schema file
+// (whose type the values are checked against directly).
+func (o *SchemaValidateOptions) validatorOf(file *files.File) (func(doc *yamlmeta.Document) []error, error) {
+	docSet, err := o.parse(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docSet.Items {
+		if isJSONSchema(doc) {
+			return func(valuesDoc *yamlmeta.Document) []error { return schema.ValidateAgainstJSONSchema(doc, valuesDoc) }, nil
+		}
+	}
+
+	docType, err := cmdtpl.NewOptions().DataValuesSchemaType(cmdtpl.Input{Files: []*files.File{file}}, ui.NewTTY(o.Debug))
+	if err != nil {
+		return nil, err
+	}
+	jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+	if err != nil {
+		return nil, err
+	}
+	return jsonSchemaDoc.Validate, nil
+}
+
+func (o *SchemaValidateOptions) fileAt(path string) (*files.File, error) {
+	filesToProcess, err := files.NewSortedFilesFromPaths([]string{path}, files.SymlinkAllowOpts{})
+	if err != nil {
+		return nil, err
+	}
+	if len(filesToProcess) != 1 {
+		return nil, fmt.Errorf("Expected '%s' to be a single file, but found %d files", path, len(filesToProcess))
+	}
+	return filesToProcess[0], nil
+}
+
+func (o *SchemaValidateOptions) parse(file *files.File) (*yamlmeta.DocumentSet, error) {
+	data, err := file.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return yamlmeta.NewParser(yamlmeta.ParserOpts{}).ParseBytes(data, file.RelativePath())
+}
+
+// isJSONSchema indicates whether `doc` is a JSON Schema (i.e. declares its dialect via "$schema").
+func isJSONSchema(doc *yamlmeta.Document) bool {
+	mapVal, ok := doc.Value.(*yamlmeta.Map)
+	if !ok {
+		return false
+	}
+	for _, item := range mapVal.Items {
+		if item.Key == "$schema" {
+			return true
+		}
+	}
+	return false
+}
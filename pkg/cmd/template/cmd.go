@@ -31,6 +31,8 @@ type Options struct {
 	RegularFilesSourceOpts RegularFilesSourceOpts
 	FileMarksOpts          FileMarksOpts
 	DataValuesFlags        DataValuesFlags
+	JSONSchemaFlags        JSONSchemaFlags
+	OpenAPIFlags           OpenAPIFlags
 }
 
 type Input struct {
@@ -77,6 +79,8 @@ func (o *Options) BindFlags(cmdFlags CmdFlags) {
 	o.RegularFilesSourceOpts.Set(cmdFlags)
 	o.FileMarksOpts.Set(cmdFlags)
 	o.DataValuesFlags.Set(cmdFlags)
+	o.JSONSchemaFlags.Set(cmdFlags)
+	o.OpenAPIFlags.Set(cmdFlags)
 }
 
 func (o *Options) Run() error {
@@ -87,9 +91,18 @@ func (o *Options) Run() error {
 		ui.Debugf("total: %s\n", time.Now().Sub(t1))
 	}()
 
+	regularFilesSourceOpts := o.RegularFilesSourceOpts
+	if o.JSONSchemaFlags.SplitDir != "" {
+		// the files of the JSON Schema split are written to that directory, as output files are.
+		if regularFilesSourceOpts.outputDir != "" || regularFilesSourceOpts.OutputFiles != "" {
+			return fmt.Errorf("Expected at most one of --json-schema-split-dir, --output-files and --dangerous-emptied-output-directory to be specified")
+		}
+		regularFilesSourceOpts.OutputFiles = o.JSONSchemaFlags.SplitDir
+	}
+
 	srcs := []FileSource{
 		NewBulkFilesSource(o.BulkFilesSourceOpts, ui),
-		NewRegularFilesSource(o.RegularFilesSourceOpts, ui),
+		NewRegularFilesSource(regularFilesSourceOpts, ui),
 	}
 
 	in, err := o.pickSource(srcs, func(s FileSource) bool { return s.HasInput() }).Input()
@@ -121,14 +134,7 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 		return Output{Err: err}
 	}
 
-	libraryExecutionFactory := workspace.NewLibraryExecutionFactory(
-		ui,
-		workspace.TemplateLoaderOpts{
-			IgnoreUnknownComments:   o.IgnoreUnknownComments,
-			ImplicitMapKeyOverrides: o.ImplicitMapKeyOverrides,
-			StrictYAML:              o.StrictYAML,
-		},
-		o.DataValuesFlags.SkipValidation)
+	libraryExecutionFactory := o.libraryExecutionFactory(ui)
 
 	libraryCtx := workspace.LibraryExecutionContext{Current: rootLibrary, Root: rootLibrary}
 	rootLibraryExecution := libraryExecutionFactory.New(libraryCtx)
@@ -139,14 +145,27 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 	}
 
 	if o.DataValuesFlags.InspectSchema {
-		return o.inspectSchema(schema)
+		docType := schema.GetDocumentType()
+		if o.DataValuesFlags.InspectSchemaWithDataValues {
+			// the schema inspected is extended with the keys that the data values add, once overlaid (the values
+			// themselves need not be valid).
+			values, _, err := libraryExecutionFactory.ThatSkipsDataValuesValidations(true).New(libraryCtx).Values(valuesOverlays, schema.ThatAcceptsKeysOfDataValues())
+			if err != nil {
+				return Output{Err: err}
+			}
+			docType, err = docType.WithKeysOf(values.Doc)
+			if err != nil {
+				return Output{Err: err}
+			}
+		}
+		return o.inspectSchema(docType, ui)
 	}
 
 	schemaType, err := o.RegularFilesSourceOpts.OutputType.Schema()
 	if err != nil {
 		return Output{Err: err}
 	}
-	if schemaType == RegularFilesOutputTypeOpenAPI {
+	if schemaType != RegularFilesOutputTypeNone {
 		return Output{Err: fmt.Errorf("Output type currently only supported for data values schema (i.e. include --data-values-schema-inspect)")}
 	}
 
@@ -169,6 +188,33 @@ func (o *Options) RunWithFiles(in Input, ui ui.UI) Output {
 	return Output{Files: result.Files, DocSet: result.DocSet}
 }
 
+// DataValuesSchemaType gives the type of the data values declared by the schema(s) among `in` (e.g. to check values
+// against it: see schema.JSONSchemaDocument.Validate()).
+func (o *Options) DataValuesSchemaType(in Input, ui ui.UI) (*schema.DocumentType, error) {
+	inFiles, err := o.FileMarksOpts.Apply(in.Files)
+	if err != nil {
+		return nil, err
+	}
+	rootLibrary := workspace.NewRootLibrary(inFiles)
+	libraryCtx := workspace.LibraryExecutionContext{Current: rootLibrary, Root: rootLibrary}
+	dataValuesSchema, _, err := o.libraryExecutionFactory(ui).New(libraryCtx).Schemas(nil)
+	if err != nil {
+		return nil, err
+	}
+	return dataValuesSchema.GetDocumentType(), nil
+}
+
+func (o *Options) libraryExecutionFactory(ui ui.UI) *workspace.LibraryExecutionFactory {
+	return workspace.NewLibraryExecutionFactory(
+		ui,
+		workspace.TemplateLoaderOpts{
+			IgnoreUnknownComments:   o.IgnoreUnknownComments,
+			ImplicitMapKeyOverrides: o.ImplicitMapKeyOverrides,
+			StrictYAML:              o.StrictYAML,
+		},
+		o.DataValuesFlags.SkipValidation)
+}
+
 func (o *Options) inspectDataValues(values *datavalues.Envelope) Output {
 	return Output{
 		DocSet: &yamlmeta.DocumentSet{
@@ -177,21 +223,141 @@ func (o *Options) inspectDataValues(values *datavalues.Envelope) Output {
 	}
 }
 
-func (o *Options) inspectSchema(dataValuesSchema *datavalues.Schema) Output {
+func (o *Options) inspectSchema(docType *schema.DocumentType, ui ui.UI) Output {
 	format, err := o.RegularFilesSourceOpts.OutputType.Schema()
 	if err != nil {
 		return Output{Err: err}
 	}
-	if format == RegularFilesOutputTypeOpenAPI {
-		openAPIDoc := schema.NewOpenAPIDocument(dataValuesSchema.GetDocumentType())
+	err = schema.CheckFormats(docType, o.DataValuesFlags.AllowCustomFormats)
+	if err != nil {
+		return Output{Err: err}
+	}
+	switch format {
+	case RegularFilesOutputTypeOpenAPI:
+		openAPIDoc, err := schema.NewOpenAPIDocumentWithOpts(docType, o.OpenAPIFlags.AsOpts())
+		if err != nil {
+			return Output{Err: err}
+		}
 		return Output{
 			DocSet: &yamlmeta.DocumentSet{
 				Items: []*yamlmeta.Document{openAPIDoc.AsDocument()},
 			},
 		}
+	case RegularFilesOutputTypeJSONSchema, RegularFilesOutputTypeJSONSchemaYAML:
+		jsonSchemaOpts, err := o.JSONSchemaFlags.AsOpts()
+		if err != nil {
+			return Output{Err: err}
+		}
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, jsonSchemaOpts)
+		if err != nil {
+			return Output{Err: err}
+		}
+		if o.JSONSchemaFlags.SplitDir != "" && o.JSONSchemaFlags.BundleToSingleFile {
+			return Output{Err: fmt.Errorf("Expected at most one of --json-schema-split-dir and --json-schema-bundle-to-single-file to be specified")}
+		}
+		if o.JSONSchemaFlags.SplitDir != "" {
+			return o.splitSchema(jsonSchemaDoc)
+		}
+		doc := jsonSchemaDoc.AsDocument()
+		if o.JSONSchemaFlags.BundleToSingleFile {
+			doc, err = jsonSchemaDoc.AsSingleFileDocument(o.fetchExternalSchema)
+			if err != nil {
+				return Output{Err: fmt.Errorf("Bundling data values schema into a single file: %s", err)}
+			}
+		}
+		docSet := &yamlmeta.DocumentSet{
+			Items: []*yamlmeta.Document{doc},
+		}
+		extension, err := o.schemaFileExtension()
+		if err != nil {
+			return Output{Err: err}
+		}
+		outputFile, err := o.schemaAsOutputFile(doc, "data-values-schema"+extension)
+		if err != nil {
+			return Output{Err: err}
+		}
+		return Output{Files: []files.OutputFile{outputFile}, DocSet: docSet}
+	case RegularFilesOutputTypeTypeScript:
+		tsDoc := schema.NewTypeScriptDocument(docType)
+		return Output{Files: []files.OutputFile{files.NewOutputFile("data-values-schema.ts", tsDoc.AsBytes(), files.TypeText)}}
+	}
+	return Output{Err: fmt.Errorf("Data values schema export only supported in OpenAPI v3, JSON Schema or TypeScript format; specify format with --output=%s, --output=%s or --output=%s flag",
+		RegularFilesOutputTypeOpenAPI, RegularFilesOutputTypeJSONSchema, RegularFilesOutputTypeTypeScript)}
+}
+
+// splitSchema splits the exported JSON Schema into files (to be written to the directory given via
+// --json-schema-split-dir: see Run()): the root schema (as "data-values-schema"), and a file for each definition (named
+// after it) that the others refer to.
+func (o *Options) splitSchema(jsonSchemaDoc *schema.JSONSchemaDocument) Output {
+	extension, err := o.schemaFileExtension()
+	if err != nil {
+		return Output{Err: err}
+	}
+	var outputFiles []files.OutputFile
+	for _, file := range jsonSchemaDoc.AsSplitDocuments("data-values-schema"+extension, func(defName string) string { return defName + extension }) {
+		outputFile, err := o.schemaAsOutputFile(file.Document, file.Name)
+		if err != nil {
+			return Output{Err: err}
+		}
+		outputFiles = append(outputFiles, outputFile)
+	}
+	return Output{Files: outputFiles}
+}
+
+// fetchExternalSchema retrieves (over HTTP) the external schema at `uri`, to be declared in the JSON Schema bundled
+// into a single file; only if allowed via --json-schema-fetch-external-refs.
+func (o *Options) fetchExternalSchema(uri string) (*yamlmeta.Document, error) {
+	if !o.JSONSchemaFlags.FetchExternalRefs {
+		return nil, fmt.Errorf("fetching external schemas is not enabled (see --json-schema-fetch-external-refs)")
+	}
+	schemaBytes, err := files.NewHTTPSource(uri).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	docSet, err := yamlmeta.NewDocumentSetFromBytes(schemaBytes, yamlmeta.DocSetOpts{AssociatedName: uri})
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docSet.Items {
+		if !doc.IsEmpty() {
+			return doc, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a schema, but was empty")
+}
+
+// schemaAsOutputFile renders an exported schema as a file (named `fileName`) so that it can be written via
+// --output-files (or --dangerous-emptied-output-directory). As JSON, its keywords keep the order they were generated
+// in (see schema.DocumentAsJSON()), which printing documents as JSON would not.
+func (o *Options) schemaAsOutputFile(doc *yamlmeta.Document, fileName string) (files.OutputFile, error) {
+	format, err := o.RegularFilesSourceOpts.OutputType.Format()
+	if err != nil {
+		return files.OutputFile{}, err
+	}
+	if format == RegularFilesOutputTypeJSON {
+		docBytes, err := schema.DocumentAsJSON(doc, 0)
+		if err != nil {
+			return files.OutputFile{}, err
+		}
+		return files.NewOutputFile(fileName, docBytes, files.TypeJSON), nil
+	}
+	docBytes, err := (&yamlmeta.DocumentSet{Items: []*yamlmeta.Document{doc}}).AsBytes()
+	if err != nil {
+		return files.OutputFile{}, fmt.Errorf("Marshaling data values schema: %s", err)
+	}
+	return files.NewOutputFile(fileName, docBytes, files.TypeYAML), nil
+}
+
+// schemaFileExtension is the extension of files holding an exported schema, in the output format.
+func (o *Options) schemaFileExtension() (string, error) {
+	format, err := o.RegularFilesSourceOpts.OutputType.Format()
+	if err != nil {
+		return "", err
+	}
+	if format == RegularFilesOutputTypeJSON {
+		return ".json", nil
 	}
-	return Output{Err: fmt.Errorf("Data values schema export only supported in OpenAPI v3 format; specify format with --output=%s flag",
-		RegularFilesOutputTypeOpenAPI)}
+	return ".yml", nil
 }
 
 func (o *Options) pickSource(srcs []FileSource, pickFunc func(FileSource) bool) FileSource {
@@ -38,6 +38,9 @@ type DataValuesFlags struct {
 	InspectSchema  bool
 	SkipValidation bool
 
+	AllowCustomFormats          bool
+	InspectSchemaWithDataValues bool
+
 	EnvironFunc   func() []string
 	ReadFilesFunc func(paths string) ([]*files.File, error)
 
@@ -57,7 +60,9 @@ func (s *DataValuesFlags) Set(cmdFlags CmdFlags) {
 
 	cmdFlags.BoolVar(&s.Inspect, "data-values-inspect", false, "Determine the final data values (applying any overlays) and display that result")
 	cmdFlags.BoolVar(&s.SkipValidation, "dangerous-data-values-disable-validation", false, "Skip validating data values (not recommended: may result in templates failing or invalid output)")
-	cmdFlags.BoolVar(&s.InspectSchema, "data-values-schema-inspect", false, "Determine the complete schema for data values (applying any overlays) and display the result (only OpenAPI v3.0 is supported, see --output)")
+	cmdFlags.BoolVar(&s.InspectSchema, "data-values-schema-inspect", false, "Determine the complete schema for data values (applying any overlays) and display the result (OpenAPI v3.0 and JSON Schema are supported, see --output)")
+	cmdFlags.BoolVar(&s.AllowCustomFormats, "allow-custom-formats", false, "Allow @schema/format to name formats other than those defined by JSON Schema and OpenAPI (when inspecting schema)")
+	cmdFlags.BoolVar(&s.InspectSchemaWithDataValues, "data-values-schema-inspect-with-data-values", false, "Declare in the inspected schema the keys that data values (and their overlays) add to it, rather than rejecting them")
 }
 
 type dataValuesFlagsSource struct {
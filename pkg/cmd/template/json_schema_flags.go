@@ -0,0 +1,148 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"carvel.dev/ytt/pkg/schema"
+)
+
+// JSONSchemaFlags holds configuration for when data values schema is exported as JSON Schema
+// (via the --json-schema-... flags).
+type JSONSchemaFlags struct {
+	ID                        string
+	Vocabulary                []string
+	Description               string
+	Draft                     string
+	FloatFormat               bool
+	NoRefs                    bool
+	TitlesFromKeys            bool
+	SortAnyTypes              bool
+	PreserveOrder             bool
+	NoConst                   bool
+	SynthesizeDescriptions    bool
+	InferFormats              bool
+	NoPasswordFormat          bool
+	NoDefaults                bool
+	RequiredAll               bool
+	EnumRefs                  bool
+	UnitDescriptions          bool
+	DefaultsAsExamples        bool
+	ExamplesFromDefaults      bool
+	DeprecatedFromValidations bool
+	MergeValidationMessages   bool
+	MapDefaults               bool
+	FlattenSingleOneOf        bool
+	Embedded                  bool
+	DropUnsupported           bool
+	NullableStrings           bool
+	EnumLengths               bool
+	FormatBounds              bool
+	Nulls                     string
+	SplitDir                  string
+	BundleToSingleFile        bool
+	FetchExternalRefs         bool
+}
+
+// Set registers JSON Schema export flags and wires-up those flags up to this
+// JSONSchemaFlags to be set when the corresponding cobra.Command is executed.
+func (s *JSONSchemaFlags) Set(cmdFlags CmdFlags) {
+	cmdFlags.StringVar(&s.ID, "json-schema-id", "", "Set the '$id' of the exported JSON Schema (omitted, if not set)")
+	cmdFlags.StringArrayVar(&s.Vocabulary, "json-schema-vocabulary", nil, "Declare a vocabulary in the '$vocabulary' of the exported JSON Schema, for draft 2020-12 only (format: URI=true, if required, or URI=false) (can be specified multiple times)")
+	cmdFlags.StringVar(&s.Description, "json-schema-description", "", "Describe the exported JSON Schema as a whole, unless described via @schema/desc (generically, if not set)")
+	cmdFlags.StringVar(&s.Draft, "json-schema-draft", schema.JSONSchemaDrafts[0],
+		fmt.Sprintf("Configure the version of JSON Schema to export (%s)", strings.Join(schema.JSONSchemaDrafts, ", ")))
+	cmdFlags.BoolVar(&s.NoRefs, "json-schema-no-refs", false, "Inline repeated maps in the exported JSON Schema, rather than referring to a single definition")
+	cmdFlags.BoolVar(&s.FloatFormat, "json-schema-float-format", false, "Include 'format: float' for floating point numbers in the exported JSON Schema")
+	cmdFlags.BoolVar(&s.TitlesFromKeys, "json-schema-titles-from-keys", false, "Title properties of the exported JSON Schema after their (humanized) keys, unless titled via @schema/title")
+	cmdFlags.BoolVar(&s.SortAnyTypes, "json-schema-sort-any-types", false, "List the types permitted for values of any type in alphabetical order in the exported JSON Schema")
+	cmdFlags.BoolVar(&s.PreserveOrder, "json-schema-preserve-order", false, "Arrange keywords of the exported JSON Schema in reading order: documentation, type, constraints, then properties/items")
+	cmdFlags.BoolVar(&s.NoConst, "json-schema-no-const", false, "Give a single allowed value as a one-element 'enum' (rather than as 'const') in the exported JSON Schema")
+	cmdFlags.BoolVar(&s.SynthesizeDescriptions, "json-schema-synthesize-descriptions", false, "Describe undocumented properties of the exported JSON Schema by their type and default")
+	cmdFlags.BoolVar(&s.InferFormats, "json-schema-infer-formats", false, "Guess the format of strings (date-time, email, uri) from their defaults in the exported JSON Schema, unless given via @schema/format")
+	cmdFlags.BoolVar(&s.NoPasswordFormat, "json-schema-no-password-format", false, "Omit 'format: password' from write-only strings in the exported JSON Schema")
+	cmdFlags.BoolVar(&s.NoDefaults, "json-schema-no-defaults", false, "Omit the default values from the exported JSON Schema")
+	cmdFlags.BoolVar(&s.RequiredAll, "json-schema-required-all", false, "Require every key of every map in the exported JSON Schema, regardless of its default")
+	cmdFlags.BoolVar(&s.EnumRefs, "json-schema-enum-refs", false, "Declare repeated enums once among the definitions of the exported JSON Schema (for draft 2020-12), referring to them there")
+	cmdFlags.BoolVar(&s.UnitDescriptions, "json-schema-unit-descriptions", false, "Append the unit of each number given one via @schema/unit to its description in the exported JSON Schema (e.g. 'Timeout (seconds)')")
+	cmdFlags.BoolVar(&s.DefaultsAsExamples, "json-schema-defaults-as-examples", false, "Give the default values as examples (rather than as 'default') in the exported JSON Schema, so that validators do not fill them in")
+	cmdFlags.BoolVar(&s.ExamplesFromDefaults, "json-schema-examples-from-defaults", false, "Give the default of each value as its example in the exported JSON Schema, unless given examples via @schema/examples")
+	cmdFlags.BoolVar(&s.DeprecatedFromValidations, "json-schema-deprecated-from-validations", false, "Mark properties as deprecated in the exported JSON Schema when a message of their validation starts with 'DEPRECATED:'")
+	cmdFlags.BoolVar(&s.MergeValidationMessages, "json-schema-merge-validation-messages", false, "Append to the description of each property of the exported JSON Schema what its validation requires (e.g. 'Must be a value >= 1.')")
+	cmdFlags.BoolVar(&s.MapDefaults, "json-schema-map-defaults", false, "Give each map of the exported JSON Schema a 'default' made of the defaults of its keys (recursively)")
+	cmdFlags.BoolVar(&s.FlattenSingleOneOf, "json-schema-flatten-single-oneof", false, "Inline each 'oneOf', 'anyOf' or 'allOf' of a single subschema into the schema containing it in the exported JSON Schema")
+	cmdFlags.BoolVar(&s.Embedded, "json-schema-embedded", false, "Omit '$schema', '$id' and the generic description from the exported JSON Schema, to embed it as a subschema (see also --json-schema-no-refs)")
+	cmdFlags.BoolVar(&s.DropUnsupported, "json-schema-drop-unsupported", false, "Omit keywords that the targeted draft does not define (e.g. 'deprecated', in draft-07) from the exported JSON Schema, rather than failing to export it")
+	cmdFlags.BoolVar(&s.NullableStrings, "json-schema-nullable-strings", false, "Allow null for every string in the exported JSON Schema, as though each were annotated with @schema/nullable")
+	cmdFlags.BoolVar(&s.EnumLengths, "json-schema-enum-lengths", false, "Bound the length of strings allowed via one_of by their shortest and longest values in the exported JSON Schema, unless bounded via min_len/max_len")
+	cmdFlags.BoolVar(&s.FormatBounds, "json-schema-format-bounds", false, "Bound each integer given a width via @schema/format (int32, int64) by the least and greatest integers of that width in the exported JSON Schema, unless bounded via min/max")
+	cmdFlags.StringVar(&s.Nulls, "json-schema-nulls", "",
+		fmt.Sprintf("Tell apart nullable keys given as null from absent ones in the exported JSON Schema (%s): either may be absent, or must be present (if only as null)", strings.Join(schema.JSONSchemaNulls, ", ")))
+	cmdFlags.StringVar(&s.SplitDir, "json-schema-split-dir", "", "Write the exported JSON Schema to this directory, split into the root schema and a file for each definition (referred to by relative path)")
+	cmdFlags.BoolVar(&s.BundleToSingleFile, "json-schema-bundle-to-single-file", false, "Export a self-contained JSON Schema, referring only to its own definitions: external schemas (see @schema/external-ref) are declared among them (see --json-schema-fetch-external-refs)")
+	cmdFlags.BoolVar(&s.FetchExternalRefs, "json-schema-fetch-external-refs", false, "Allow fetching (over HTTP) the external schemas declared in the JSON Schema bundled into a single file")
+}
+
+// AsOpts produces the schema.JSONSchemaOpts configured by these flags.
+func (s *JSONSchemaFlags) AsOpts() (schema.JSONSchemaOpts, error) {
+	vocabulary, err := s.vocabulary()
+	if err != nil {
+		return schema.JSONSchemaOpts{}, err
+	}
+	return schema.JSONSchemaOpts{
+		ID:                        s.ID,
+		Vocabulary:                vocabulary,
+		Description:               s.Description,
+		Draft:                     s.Draft,
+		FloatFormat:               s.FloatFormat,
+		NoRefs:                    s.NoRefs,
+		TitlesFromKeys:            s.TitlesFromKeys,
+		SortAnyTypes:              s.SortAnyTypes,
+		PreserveOrder:             s.PreserveOrder,
+		NoConst:                   s.NoConst,
+		SynthesizeDescriptions:    s.SynthesizeDescriptions,
+		InferFormats:              s.InferFormats,
+		NoPasswordFormat:          s.NoPasswordFormat,
+		NoDefaults:                s.NoDefaults,
+		RequiredAll:               s.RequiredAll,
+		EnumRefs:                  s.EnumRefs,
+		UnitDescriptions:          s.UnitDescriptions,
+		DefaultsAsExamples:        s.DefaultsAsExamples,
+		ExamplesFromDefaults:      s.ExamplesFromDefaults,
+		DeprecatedFromValidations: s.DeprecatedFromValidations,
+		MergeValidationMessages:   s.MergeValidationMessages,
+		MapDefaults:               s.MapDefaults,
+		FlattenSingleOneOf:        s.FlattenSingleOneOf,
+		Embedded:                  s.Embedded,
+		DropUnsupported:           s.DropUnsupported,
+		NullableStrings:           s.NullableStrings,
+		EnumLengths:               s.EnumLengths,
+		FormatBounds:              s.FormatBounds,
+		Nulls:                     s.Nulls,
+	}, nil
+}
+
+// vocabulary parses the vocabularies given via --json-schema-vocabulary (each as URI=true or URI=false).
+func (s *JSONSchemaFlags) vocabulary() (map[string]bool, error) {
+	if len(s.Vocabulary) == 0 {
+		return nil, nil
+	}
+	vocabulary := map[string]bool{}
+	for _, kv := range s.Vocabulary {
+		// (split on the last '=', as a URI can contain one, e.g. in its query)
+		sep := strings.LastIndex(kv, "=")
+		if sep <= 0 {
+			return nil, fmt.Errorf("Expected vocabulary '%s' to be in format URI=true or URI=false", kv)
+		}
+		required, err := strconv.ParseBool(kv[sep+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Expected vocabulary '%s' to be in format URI=true or URI=false: %s", kv, err)
+		}
+		vocabulary[kv[:sep]] = required
+	}
+	return vocabulary, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"carvel.dev/ytt/pkg/schema"
+)
+
+// OpenAPIFlags holds configuration for when data values schema is exported as an OpenAPI document
+// (via the --openapi-... flags).
+type OpenAPIFlags struct {
+	Refs             bool
+	ParametersIn     string
+	NoPasswordFormat bool
+}
+
+// Set registers OpenAPI export flags and wires-up those flags up to this
+// OpenAPIFlags to be set when the corresponding cobra.Command is executed.
+func (s *OpenAPIFlags) Set(cmdFlags CmdFlags) {
+	cmdFlags.BoolVar(&s.Refs, "openapi-refs", false, "Declare repeated maps once among the components of the exported OpenAPI document, referring to them as '#/components/schemas/...'")
+	cmdFlags.StringVar(&s.ParametersIn, "openapi-parameters-in", "",
+		fmt.Sprintf("Declare each (scalar) data value as a parameter of this location (%s) among the components of the exported OpenAPI document, rather than the data values as a schema", strings.Join(schema.OpenAPIParameterLocations, ", ")))
+	cmdFlags.BoolVar(&s.NoPasswordFormat, "openapi-no-password-format", false, "Omit 'format: password' from write-only strings in the exported OpenAPI document")
+}
+
+// AsOpts produces the schema.OpenAPIOpts configured by these flags.
+func (s *OpenAPIFlags) AsOpts() schema.OpenAPIOpts {
+	return schema.OpenAPIOpts{
+		Refs:             s.Refs,
+		ParametersIn:     s.ParametersIn,
+		NoPasswordFormat: s.NoPasswordFormat,
+	}
+}
@@ -27,7 +27,7 @@ type RegularFilesSourceOpts struct {
 
 // OutputType holds the user's desire for two (2) categories of output:
 // - file format type :: yaml, json, pos
-// - schema type :: OpenAPI V3, ytt Schema
+// - schema type :: OpenAPI V3, JSON Schema, ytt Schema
 type OutputType struct {
 	Types []string
 }
@@ -84,6 +84,13 @@ func (s *RegularFilesSource) Output(out Output) error {
 		return files.NewOutputDirectory(s.opts.outputDir, out.Files, s.ui).Write()
 	case len(s.opts.OutputFiles) > 0:
 		return files.NewOutputDirectory(s.opts.OutputFiles, out.Files, s.ui).WriteFiles()
+	case out.DocSet == nil || isRenderedAsJSON(out.Files):
+		// the output is not made of documents (e.g. TypeScript type definitions), or they are already rendered (e.g. an
+		// exported schema, as JSON, keeping the order of its keywords): each file is printed as is.
+		for _, file := range out.Files {
+			s.ui.Printf("%s", file.Bytes())
+		}
+		return nil
 	default:
 		for _, file := range out.Files {
 			if file.Type() != files.TypeYAML {
@@ -92,24 +99,11 @@ func (s *RegularFilesSource) Output(out Output) error {
 		}
 	}
 
-	var printerFunc func(io.Writer) yamlmeta.DocumentPrinter
-
-	outputType, err := s.opts.OutputType.Format()
+	printerFunc, err := s.opts.OutputType.Printer()
 	if err != nil {
 		return err
 	}
 
-	switch outputType {
-	case RegularFilesOutputTypeYAML:
-		printerFunc = nil
-	case RegularFilesOutputTypeJSON:
-		printerFunc = func(w io.Writer) yamlmeta.DocumentPrinter { return yamlmeta.NewJSONPrinter(w) }
-	case RegularFilesOutputTypePos:
-		printerFunc = func(w io.Writer) yamlmeta.DocumentPrinter {
-			return yamlmeta.WrappedFilePositionPrinter{yamlmeta.NewFilePositionPrinter(w)}
-		}
-	}
-
 	combinedDocBytes, err := out.DocSet.AsBytesWithPrinter(printerFunc)
 	if err != nil {
 		return fmt.Errorf("Marshaling combined template result: %s", err)
@@ -125,6 +119,16 @@ If you want to include those results, use the --output-files or --dangerous-empt
 	return nil
 }
 
+// isRenderedAsJSON indicates whether `outputFiles` are all already rendered as JSON.
+func isRenderedAsJSON(outputFiles []files.OutputFile) bool {
+	for _, file := range outputFiles {
+		if file.Type() != files.TypeJSON {
+			return false
+		}
+	}
+	return len(outputFiles) > 0
+}
+
 // When the FileSource are RegularFilesSource, indicates which file format to use when rendering the output.
 const (
 	RegularFilesOutputTypeYAML = "yaml"
@@ -134,27 +138,71 @@ const (
 
 // When the FileSource are RegularFilesSource, indicates which schema type to use when rendering the output.
 const (
-	RegularFilesOutputTypeOpenAPI = "openapi-v3"
-	RegularFilesOutputTypeNone    = ""
+	RegularFilesOutputTypeOpenAPI        = "openapi-v3"
+	RegularFilesOutputTypeJSONSchema     = "json-schema"
+	RegularFilesOutputTypeJSONSchemaYAML = "json-schema-yaml"
+	RegularFilesOutputTypeTypeScript     = "ts"
+	RegularFilesOutputTypeNone           = ""
 )
 
 // Collections of each category of output type
 var (
 	RegularFilesOutputFormatTypes = []string{RegularFilesOutputTypeYAML, RegularFilesOutputTypeJSON, RegularFilesOutputTypePos}
-	RegularFilesOutputSchemaTypes = []string{RegularFilesOutputTypeOpenAPI}
+	RegularFilesOutputSchemaTypes = []string{RegularFilesOutputTypeOpenAPI, RegularFilesOutputTypeJSONSchema, RegularFilesOutputTypeJSONSchemaYAML, RegularFilesOutputTypeTypeScript}
 	RegularFilesOutputTypes       = append(RegularFilesOutputFormatTypes, RegularFilesOutputSchemaTypes...)
 )
 
 // Format returns which of the file format types is in effect
+//
+// JSON Schema is rendered as JSON, unless a file format is explicitly given (or the "json-schema-yaml" variant is used).
 func (o *OutputType) Format() (string, error) {
+	schemaType, err := o.Schema()
+	if err != nil {
+		return "", err
+	}
+	if schemaType == RegularFilesOutputTypeJSONSchema {
+		return o.typeFrom(RegularFilesOutputFormatTypes, RegularFilesOutputTypeJSON)
+	}
 	return o.typeFrom(RegularFilesOutputFormatTypes, RegularFilesOutputTypeYAML)
 }
 
 // Schema returns which of the schema types is in effect
 func (o *OutputType) Schema() (string, error) {
+	if err := o.validateTypes(); err != nil {
+		return "", err
+	}
+	var schemaTypes []string
+	for _, t := range o.Types {
+		if o.stringInSlice(t, RegularFilesOutputSchemaTypes) && !o.stringInSlice(t, schemaTypes) {
+			schemaTypes = append(schemaTypes, t)
+		}
+	}
+	if len(schemaTypes) > 1 {
+		return "", fmt.Errorf("Expected at most one schema type in output type, but found: %s", strings.Join(schemaTypes, ", "))
+	}
 	return o.typeFrom(RegularFilesOutputSchemaTypes, RegularFilesOutputTypeNone)
 }
 
+// Printer returns the function that creates a yamlmeta.DocumentPrinter for the file format in effect
+// (nil selects the default, YAML printer).
+func (o *OutputType) Printer() (func(io.Writer) yamlmeta.DocumentPrinter, error) {
+	format, err := o.Format()
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case RegularFilesOutputTypeJSON:
+		return func(w io.Writer) yamlmeta.DocumentPrinter { return yamlmeta.NewJSONPrinter(w) }, nil
+	case RegularFilesOutputTypePos:
+		return func(w io.Writer) yamlmeta.DocumentPrinter {
+			return yamlmeta.WrappedFilePositionPrinter{Printer: yamlmeta.NewFilePositionPrinter(w)}
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
 func (o *OutputType) typeFrom(types []string, defaultValue string) (string, error) {
 	if err := o.validateTypes(); err != nil {
 		return "", err
@@ -132,6 +132,85 @@ func Test_FileMark_YAML_Shows_No_Warning(t *testing.T) {
 	assertStdoutAndStderr(t, stdout, stderr, expectedStdOut, expectedStdErr)
 }
 
+func Test_Schema_Exported_As_JSON_Shows_No_Warning(t *testing.T) {
+	schemaData := []byte(`#@data/values-schema
+---
+foo: 0
+`)
+
+	expectedStdErr := ""
+	expectedStdOut := `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt","type":"object","additionalProperties":false,"properties":{"foo":{"type":"integer","default":0}}}`
+
+	filesToProcess := []*files.File{
+		files.MustNewFileFromSource(files.NewBytesSource("schema.yml", schemaData)),
+	}
+
+	stdout := bytes.NewBufferString("")
+	stderr := bytes.NewBufferString("")
+	ui := ui.NewCustomWriterTTY(false, stdout, stderr)
+	opts := cmdtpl.NewOptions()
+	opts.DataValuesFlags.InspectSchema = true
+	opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+	rfs := cmdtpl.NewRegularFilesSource(opts.RegularFilesSourceOpts, ui)
+
+	out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui)
+	require.NoError(t, out.Err)
+
+	err := rfs.Output(out)
+	require.NoError(t, err)
+
+	assertStdoutAndStderr(t, stdout, stderr, expectedStdOut, expectedStdErr)
+}
+
+func Test_Schema_Exported_As_JSON_Keeps_Keyword_Order(t *testing.T) {
+	schemaData := []byte(`#@data/values-schema
+---
+#@schema/desc "Port to listen on"
+#@schema/validation min=1
+port: 8080
+`)
+
+	examples := []struct {
+		desc          string
+		preserveOrder bool
+		expected      string
+	}{
+		{
+			desc:     "by default",
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt","type":"object","additionalProperties":false,"properties":{"port":{"type":"integer","description":"Port to listen on","default":8080,"minimum":1}}}`,
+		},
+		{
+			desc:          "in reading order, when preserving order",
+			preserveOrder: true,
+			expected:      `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt","type":"object","additionalProperties":false,"properties":{"port":{"description":"Port to listen on","type":"integer","minimum":1,"default":8080}}}`,
+		},
+	}
+	for _, eg := range examples {
+		t.Run(eg.desc, func(t *testing.T) {
+			filesToProcess := []*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", schemaData)),
+			}
+
+			stdout := bytes.NewBufferString("")
+			stderr := bytes.NewBufferString("")
+			ui := ui.NewCustomWriterTTY(false, stdout, stderr)
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.JSONSchemaFlags.PreserveOrder = eg.preserveOrder
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+			rfs := cmdtpl.NewRegularFilesSource(opts.RegularFilesSourceOpts, ui)
+
+			out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui)
+			require.NoError(t, out.Err)
+
+			err := rfs.Output(out)
+			require.NoError(t, err)
+
+			assertStdoutAndStderr(t, stdout, stderr, eg.expected, "")
+		})
+	}
+}
+
 func Test_OutputType_Flag(t *testing.T) {
 	type example struct {
 		desc   string
@@ -182,6 +261,24 @@ func Test_OutputType_Flag(t *testing.T) {
 			format: "pos",
 			schema: "openapi-v3",
 		},
+		{
+			desc:   "JSON_Schema_implies_JSON",
+			input:  []string{"json-schema"},
+			format: "json",
+			schema: "json-schema",
+		},
+		{
+			desc:   "explicitly_YAML,_JSON_Schema",
+			input:  []string{"yaml", "json-schema"},
+			format: "yaml",
+			schema: "json-schema",
+		},
+		{
+			desc:   "JSON_Schema_as_YAML",
+			input:  []string{"json-schema-yaml"},
+			format: "yaml",
+			schema: "json-schema-yaml",
+		},
 	}
 	for _, eg := range successExamples {
 		t.Run(eg.desc, func(t *testing.T) {
@@ -215,6 +312,12 @@ func Test_OutputType_Flag(t *testing.T) {
 			format: errors.New("Unknown output type ''"),
 			schema: errors.New("Unknown output type ''"),
 		},
+		{
+			desc:   "multiple_schema_types",
+			input:  []string{"openapi-v3", "json-schema"},
+			format: errors.New("Expected at most one schema type in output type, but found: openapi-v3, json-schema"),
+			schema: errors.New("Expected at most one schema type in output type, but found: openapi-v3, json-schema"),
+		},
 	}
 	for _, eg := range errorExamples {
 		t.Run(eg.desc, func(t *testing.T) {
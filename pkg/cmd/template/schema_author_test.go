@@ -617,6 +617,730 @@ schema.yml:
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
 	})
+	t.Run("when schema/format annotation is on a non-string", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "date"
+year: 0
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/format not supported on values of type integer
+schema.yml:
+    |
+  3 | #@schema/format "date"
+  4 | year: 0
+    |
+
+    = found: integer
+    = expected: string
+    = hint: only strings can be given a format (integers, only their width: either int32 or int64).
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/format annotation gives the width of a non-integer", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "int64"
+name: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/format "int64" not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/format "int64"
+  4 | name: ""
+    |
+
+    = found: string
+    = expected: integer
+    = hint: only integers can be given a width: either int32 or int64.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/validation multiple_of= is on a non-number", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation multiple_of=8
+memory: "64Mi"
+`
+		expectedErr := `
+Invalid schema
+==============
+
+multiple_of= not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/validation multiple_of=8
+  4 | memory: "64Mi"
+    |
+
+    = found: string
+    = expected: integer or float
+    = hint: only numbers can be required to be a multiple (of some number).
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/validation one_of= allows a value of another type", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=[1, "two", 3]
+replicas: 1
+`
+		expectedErr := `
+Invalid schema
+==============
+
+type mismatch in values of one_of=
+schema.yml:
+    |
+  3 | #@schema/validation one_of=[1, "two", 3]
+  4 | replicas: 1
+    |
+
+    = found: string "two" (at index 1)
+    = expected: integer
+    = hint: a value of another type would never be allowed: the value is only ever of its own type (e.g. that of its default).
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/validation min_props= is on a non-map", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min_props=1
+name: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+min_props= not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/validation min_props=1
+  4 | name: ""
+    |
+
+    = found: string
+    = expected: map
+    = hint: only maps have a number of keys; to bound the length of strings or arrays, use min_len= and max_len=.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/validation max_props= is given along with max_len=", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation max_props=8, max_len=4
+labels:
+  app: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+both max_props= and max_len= given
+schema.yml:
+    |
+  3 | #@schema/validation max_props=8, max_len=4
+  4 | labels:
+    |
+
+    = found: both
+    = expected: one of max_props= or max_len=
+    = hint: on a map, max_len= bounds the number of keys just as max_props= does.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/external-ref annotation", func(t *testing.T) {
+		t.Run("is on a non-map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/external-ref "https://example.com/port.json"
+port: 0
+`
+			expectedErr := `
+Invalid schema
+==============
+
+@schema/external-ref not supported on values of type integer
+schema.yml:
+    |
+  3 | #@schema/external-ref "https://example.com/port.json"
+  4 | port: 0
+    |
+
+    = found: integer
+    = expected: map
+    = hint: only maps can refer to an external schema.
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("is on a documented map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/external-ref "https://example.com/requests.json"
+#@schema/desc "resources requested"
+requests:
+  cpu: 100m
+`
+			expectedErr := `
+Invalid schema
+==============
+
+@schema/external-ref and @schema/desc are mutually exclusive
+schema.yml:
+    |
+  3 | #@schema/external-ref "https://example.com/requests.json"
+  4 | #@schema/desc "resources requested"
+  5 | requests:
+    |
+
+    = found: both @schema/external-ref and @schema/desc
+    = expected: either @schema/external-ref or documentation
+    = hint: a map referring to an external schema is documented by that schema.
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+	t.Run("when schema/content-encoding annotation is on a non-string", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/content-encoding "base64"
+certs:
+- ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/content-encoding not supported on values of type array
+schema.yml:
+    |
+  3 | #@schema/content-encoding "base64"
+  4 | certs:
+    |
+
+    = found: array
+    = expected: string
+    = hint: only strings can be given the encoding or media type of their content.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/content-media-type annotation value is not a media type", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/content-media-type "json"
+extra_config: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+invalid media type in @schema/content-media-type annotation
+schema.yml:
+    |
+  3 | #@schema/content-media-type "json"
+  4 | extra_config: ""
+    |
+
+    = found: "json" (by schema.yml:3)
+    = expected: a media type (e.g. application/json)
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/type annotation names a type other than that of a number", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/type "object"
+replicas: 0
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/type "object" not supported on values of type integer
+schema.yml:
+    |
+  3 | #@schema/type "object"
+  4 | replicas: 0
+    |
+
+    = found: object
+    = expected: integer or number
+    = hint: only numbers can be given a type: either integer or number.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/type annotation names integer on a fractional number", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/type "integer"
+num: 0.5
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/type "integer" not supported on a fractional number
+schema.yml:
+    |
+  3 | #@schema/type "integer"
+  4 | num: 0.5
+    |
+
+    = found: 0.5
+    = expected: a whole number
+    = hint: to allow fractional values, give @schema/type "number" (or none).
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/scalar-or-array annotation is on a non-array", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/scalar-or-array
+host: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/scalar-or-array not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/scalar-or-array
+  4 | host: ""
+    |
+
+    = found: string
+    = expected: array
+    = hint: only arrays can also be given as one of their items.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/nullable-items annotation is on a non-array", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable-items
+host: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/nullable-items not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/nullable-items
+  4 | host: ""
+    |
+
+    = found: string
+    = expected: array
+    = hint: only the items of arrays can be made nullable; to make a value nullable, use @schema/nullable.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/unit annotation is on a non-number", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/unit "seconds"
+timeout: 30s
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/unit not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/unit "seconds"
+  4 | timeout: 30s
+    |
+
+    = found: string
+    = expected: integer or float
+    = hint: only numbers can be given a unit.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/discriminator annotation names a key not in the map", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/discriminator "type"
+storage:
+  kind: s3
+`
+		expectedErr := `
+Invalid schema
+==============
+
+unknown key in @schema/discriminator
+schema.yml:
+    |
+  3 | #@schema/discriminator "type"
+  4 | storage:
+    |
+
+    = found: type
+    = expected: one of the keys of the map: kind
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/discriminator annotation names a key whose value is not a string", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/discriminator "kind"
+storage:
+  kind: 1
+`
+		expectedErr := `
+Invalid schema
+==============
+
+non-string key in @schema/discriminator
+schema.yml:
+    |
+  3 | #@schema/discriminator "kind"
+    | ...
+  5 |   kind: 1
+    |
+
+    = found: integer
+    = expected: string
+    = hint: the value of a discriminator names the shape of the map, so must be a string.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/no-additional-properties-key annotation is given along with schema/allow-extra-properties", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/no-additional-properties-key
+#@schema/allow-extra-properties
+spec:
+  replicas: 1
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/no-additional-properties-key and @schema/allow-extra-properties are mutually exclusive
+schema.yml:
+    |
+  3 | #@schema/no-additional-properties-key
+  4 | #@schema/allow-extra-properties
+  5 | spec:
+    |
+
+    = found: both @schema/no-additional-properties-key and @schema/allow-extra-properties
+    = expected: either @schema/no-additional-properties-key or @schema/allow-extra-properties
+    = hint: without "additionalProperties", a map is exported as permitting extra keys (of any type) anyway.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/unwrap annotation is given on a map of more than one key", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/unwrap
+server:
+  port: 0
+  host: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/unwrap not supported on a map of 2 keys
+schema.yml:
+    |
+  3 | #@schema/unwrap
+  4 | server:
+    |
+
+    = found: 2 keys
+    = expected: a map of a single key
+    = hint: a map is unwrapped into the value of its key: either leave the other keys out, or do not unwrap the map.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/tuple annotation is given on a map", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/tuple
+server:
+  port: 0
+`
+		expectedErr := `
+Invalid schema
+==============
+
+@schema/tuple not supported on map
+schema.yml:
+    |
+  3 | #@schema/tuple
+  4 | server:
+    |
+
+    = found: map
+    = expected: an array of some items
+    = hint: in a tuple, each item gives the type of the item at that position.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when schema/comment annotation value", func(t *testing.T) {
+		t.Run("is not a string", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/comment 1
+key: val
+`
+			expectedErr := `
+Invalid schema
+==============
+
+syntax error in @schema/comment annotation
+schema.yml:
+    |
+  3 | #@schema/comment 1
+  4 | key: val
+    |
+
+    = found: Non-string value in @schema/comment (by schema.yml:3)
+    = expected: string
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+	t.Run("when schema/read-only and schema/write-only annotations", func(t *testing.T) {
+		t.Run("are both given", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/read-only
+#@schema/write-only
+key: val
+`
+			expectedErr := `
+Invalid schema
+==============
+
+@schema/read-only and @schema/write-only are mutually exclusive
+schema.yml:
+    |
+  3 | #@schema/read-only
+  4 | #@schema/write-only
+  5 | key: val
+    |
+
+    = found: both @schema/read-only and @schema/write-only
+    = expected: one of @schema/read-only or @schema/write-only
+    = hint: a value cannot be both populated by the system (read-only), and never returned by it (write-only).
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("are given arguments", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/read-only True
+key: val
+`
+			expectedErr := `
+Invalid schema
+==============
+
+syntax error in @schema/read-only annotation
+schema.yml:
+    |
+  3 | #@schema/read-only True
+  4 | key: val
+    |
+
+    = found: arguments in @schema/read-only (by schema.yml:3)
+    = expected: no arguments
+    = hint: this annotation accepts no arguments.
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+	t.Run("when schema/dependent-required annotation value", func(t *testing.T) {
+		t.Run("names a key not in the map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/dependent-required {"enabled": ["cert", "key"]}
+tls:
+  enabled: false
+  cert: ""
+`
+			expectedErr := `
+Invalid schema
+==============
+
+unknown key in @schema/dependent-required
+schema.yml:
+    |
+  3 | #@schema/dependent-required {"enabled": ["cert", "key"]}
+  4 | tls:
+    |
+
+    = found: key
+    = expected: one of the keys of the map: enabled, cert
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
+	t.Run("when schema/when annotation value", func(t *testing.T) {
+		t.Run("names a key not in the map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/when "mode", "advanced", require=["threads"]
+tuning:
+  level: basic
+`
+			expectedErr := `
+Invalid schema
+==============
+
+unknown key in @schema/when
+schema.yml:
+    |
+  3 | #@schema/when "mode", "advanced", require=["threads"]
+  4 | tuning:
+    |
+
+    = found: mode
+    = expected: one of the keys of the map: level
+`
+
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
 	t.Run("when schema/title annotation value", func(t *testing.T) {
 		t.Run("is empty", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
@@ -729,10 +1453,10 @@ schema.yml:
 		})
 	})
 	t.Run("when schema/deprecated annotation value", func(t *testing.T) {
-		t.Run("is empty", func(t *testing.T) {
+		t.Run("has more than one arg", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
 ---
-#@schema/deprecated
+#@schema/deprecated "two", "strings"
 key: val
 `
 			expectedErr := `
@@ -742,11 +1466,11 @@ Invalid schema
 syntax error in @schema/deprecated annotation
 schema.yml:
     |
-  3 | #@schema/deprecated
+  3 | #@schema/deprecated "two", "strings"
   4 | key: val
     |
 
-    = found: missing value in @schema/deprecated (by schema.yml:3)
+    = found: 2 values in @schema/deprecated (by schema.yml:3)
     = expected: string
 `
 
@@ -756,10 +1480,10 @@ schema.yml:
 
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
-		t.Run("has more than one arg", func(t *testing.T) {
+		t.Run("is not a string", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
 ---
-#@schema/deprecated "two", "strings"
+#@schema/deprecated 1
 key: val
 `
 			expectedErr := `
@@ -769,11 +1493,11 @@ Invalid schema
 syntax error in @schema/deprecated annotation
 schema.yml:
     |
-  3 | #@schema/deprecated "two", "strings"
+  3 | #@schema/deprecated 1
   4 | key: val
     |
 
-    = found: 2 values in @schema/deprecated (by schema.yml:3)
+    = found: Non-string value in @schema/deprecated (by schema.yml:3)
     = expected: string
 `
 
@@ -783,10 +1507,10 @@ schema.yml:
 
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
-		t.Run("is not a string", func(t *testing.T) {
+		t.Run("is key=value pair", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
 ---
-#@schema/deprecated 1
+#@schema/deprecated key=True
 key: val
 `
 			expectedErr := `
@@ -796,12 +1520,13 @@ Invalid schema
 syntax error in @schema/deprecated annotation
 schema.yml:
     |
-  3 | #@schema/deprecated 1
+  3 | #@schema/deprecated key=True
   4 | key: val
     |
 
-    = found: Non-string value in @schema/deprecated (by schema.yml:3)
-    = expected: string
+    = found: unknown keyword argument 'key' in @schema/deprecated (by schema.yml:3)
+    = expected: a string and, optionally, since= and remove= (dates)
+    = hint: Supported kwargs are 'since', 'remove'
 `
 
 			filesToProcess := files.NewSortedFiles([]*files.File{
@@ -810,26 +1535,25 @@ schema.yml:
 
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
-		t.Run("is key=value pair", func(t *testing.T) {
+		t.Run("gives a date that is not one", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
 ---
-#@schema/deprecated key=True
+#@schema/deprecated since="June 2024"
 key: val
 `
 			expectedErr := `
 Invalid schema
 ==============
 
-syntax error in @schema/deprecated annotation
+invalid date in @schema/deprecated annotation
 schema.yml:
     |
-  3 | #@schema/deprecated key=True
+  3 | #@schema/deprecated since="June 2024"
   4 | key: val
     |
 
-    = found: keyword argument in @schema/deprecated (by schema.yml:3)
-    = expected: string
-    = hint: this annotation only accepts one argument: a string.
+    = found: since="June 2024" (by schema.yml:3)
+    = expected: a date (RFC 3339), e.g. "2024-06-01" or "2024-06-01T00:00:00Z"
 `
 
 			filesToProcess := files.NewSortedFiles([]*files.File{
@@ -869,6 +1593,62 @@ schema.yml:
 			assertFails(t, filesToProcess, expectedErr, opts)
 		})
 	})
+	t.Run("when schema/allow-extra-properties annotation", func(t *testing.T) {
+		t.Run("is not on a map", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/allow-extra-properties
+foo: ""
+`
+			expectedErr := `
+Invalid schema
+==============
+
+@schema/allow-extra-properties not supported on values of type string
+schema.yml:
+    |
+  3 | #@schema/allow-extra-properties
+  4 | foo: ""
+    |
+
+    = found: string
+    = expected: map
+    = hint: only maps can be allowed to contain keys beyond those declared.
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+		t.Run("has more than one value", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/allow-extra-properties "", 0
+foo: {}
+`
+			expectedErr := `
+Invalid schema
+==============
+
+syntax error in @schema/allow-extra-properties annotation
+schema.yml:
+    |
+  3 | #@schema/allow-extra-properties "", 0
+  4 | foo: {}
+    |
+
+    = found: 2 values in @schema/allow-extra-properties (by schema.yml:3)
+    = expected: at most one argument
+    = hint: this annotation accepts either no argument or one: a value of the type extra keys must have.
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertFails(t, filesToProcess, expectedErr, opts)
+		})
+	})
 	t.Run("when schema/examples annotation value", func(t *testing.T) {
 		t.Run("is empty", func(t *testing.T) {
 			schemaYAML := `#@data/values-schema
@@ -225,6 +225,37 @@ rendered: #@ data.values
 			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
 		})
 
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
+	t.Run("when numbers are of the type named via @schema/type", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/type "integer"
+replicas: 1.0
+#@schema/type "number"
+weight: 0
+`
+		dataValuesYAML := `#@data/values
+---
+replicas: 3.0
+weight: 2.5
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+rendered: #@ data.values
+`
+
+		expected := `rendered:
+  replicas: 3
+  weight: 2.5
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
 		assertSucceeds(t, filesToProcess, expected, opts)
 	})
 }
@@ -889,6 +920,108 @@ values.yml:
 
     = found: map
     = expected: boolean (by schema.yml:3)
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a tuple is given some other number of items", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/tuple
+endpoint:
+- localhost
+- 8080
+`
+		dataValuesYAML := `
+#@data/values
+---
+endpoint:
+- example.com
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+		})
+
+		expectedErr := `
+One or more data values were invalid
+====================================
+
+wrong number of items in tuple
+schema.yml:
+    |
+  4 | endpoint:
+    |
+
+    = found: 3 array items
+    = expected: 2 array items (by schema.yml:4)
+    = hint: to replace the items of a tuple (rather than append to them), annotate with @overlay/replace.
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a tuple item's value is the wrong type", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/tuple
+endpoint:
+- localhost
+- 8080
+`
+		dataValuesYAML := `
+#@data/values
+---
+#@overlay/replace
+endpoint:
+- example.com
+- http
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+		})
+
+		expectedErr := `
+One or more data values were invalid
+====================================
+
+values.yml:
+    |
+  7 | - http
+    |
+
+    = found: string
+    = expected: integer (by schema.yml:6)
+`
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a number given @schema/type \"integer\" is fractional", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/type "integer"
+replicas: 1.0
+`
+		dataValuesYAML := `#@data/values
+---
+replicas: 1.5
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(dataValuesYAML))),
+		})
+
+		expectedErr := `
+One or more data values were invalid
+====================================
+
+values.yml:
+    |
+  3 | replicas: 1.5
+    |
+
+    = found: float
+    = expected: integer (by schema.yml:4)
 `
 		assertFails(t, filesToProcess, expectedErr, opts)
 	})
@@ -1208,6 +1341,45 @@ array: #@ data.values.array
 
 		assertSucceeds(t, filesToProcess, expected, opts)
 	})
+	t.Run("on the items of an array (via @schema/nullable-items), as well as the array", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/nullable-items
+given:
+- ""
+#@schema/nullable
+#@schema/nullable-items
+omitted:
+- ""
+`
+		dataValuesYAML := `#@data/values
+---
+given:
+- one
+- null
+-
+`
+		templateYAML := `#@ load("@ytt:data", "data")
+---
+given: #@ data.values.given
+omitted: #@ data.values.omitted
+`
+		expected := `given:
+- one
+- null
+- null
+omitted: null
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("dataValues.yml", []byte(dataValuesYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("template.yml", []byte(templateYAML))),
+		})
+
+		assertSucceeds(t, filesToProcess, expected, opts)
+	})
 	t.Run("on a scalar", func(t *testing.T) {
 		schemaYAML := `#@data/values-schema
 ---
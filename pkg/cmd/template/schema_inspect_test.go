@@ -4,11 +4,14 @@
 package template_test
 
 import (
+	"regexp"
 	"testing"
 
 	cmdtpl "carvel.dev/ytt/pkg/cmd/template"
 	"carvel.dev/ytt/pkg/cmd/ui"
 	"carvel.dev/ytt/pkg/files"
+	"carvel.dev/ytt/pkg/orderedmap"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
@@ -564,6 +567,9 @@ foo:
   #@schema/validation max=100
   max_key: 0
 
+  #@schema/validation min=0, max=1, exclusive=True
+  exclusive_range_key: 0.5
+
   #@schema/validation min_len=1, max_len=10
   string_key: ""
 
@@ -571,6 +577,10 @@ foo:
   array_key:
   - ""
 
+  #@schema/validation unique=True
+  unique_array_key:
+  - 0
+
   #@schema/validation min_len=2, max_len=5
   map_key: {}
 
@@ -618,6 +628,14 @@ components:
               type: integer
               default: 10
               maximum: 100
+            exclusive_range_key:
+              type: number
+              format: float
+              default: 0.5
+              minimum: 0
+              maximum: 1
+              exclusiveMinimum: true
+              exclusiveMaximum: true
             string_key:
               type: string
               default: ""
@@ -631,6 +649,13 @@ components:
               default: []
               minItems: 3
               maxItems: 4
+            unique_array_key:
+              type: array
+              items:
+                type: integer
+                default: 0
+              default: []
+              uniqueItems: true
             map_key:
               type: object
               additionalProperties: false
@@ -729,6 +754,395 @@ components:
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
 
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including the discriminator of maps (only in OpenAPI)", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/discriminator "kind"
+storage:
+  #@schema/validation one_of=["s3", "gcs"]
+  kind: s3
+  bucket: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("as OpenAPI", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        storage:
+          type: object
+          additionalProperties: false
+          properties:
+            kind:
+              type: string
+              default: s3
+              enum:
+              - s3
+              - gcs
+            bucket:
+              type: string
+              default: ""
+          discriminator:
+            propertyName: kind
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("but not as JSON Schema", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  storage:
+    type: object
+    additionalProperties: false
+    properties:
+      kind:
+        type: string
+        default: s3
+        enum:
+        - s3
+        - gcs
+      bucket:
+        type: string
+        default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("including examples, as each document type expresses them", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples ("local", "localhost"), ("remote", "db.example.com")
+host: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("in OpenAPI v3.0, as the first one only", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          x-example-description: local
+          example: localhost
+          default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("in JSON Schema, as all of them", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    examples:
+    - localhost
+    - db.example.com
+    default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("including vendor extensions, as given", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+#@schema/extension "x-generated-by", "ytt"
+---
+#@schema/extension ("x-kubernetes-preserve-unknown-fields", True), ("x-order", {"after": ["name"], "weight": 2})
+host: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("in OpenAPI v3.0", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: ""
+          x-kubernetes-preserve-unknown-fields: true
+          x-order:
+            after:
+            - name
+            weight: 2
+      x-generated-by: ytt
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("in JSON Schema", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    default: ""
+    x-kubernetes-preserve-unknown-fields: true
+    x-order:
+      after:
+      - name
+      weight: 2
+x-generated-by: ytt
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("including the width of integers", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "int64"
+size: 0
+#@schema/format "int32"
+#@schema/nullable
+port: 8080
+#@schema/type "integer"
+#@schema/format "int32"
+count: 1.0
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        size:
+          type: integer
+          format: int64
+          default: 0
+        port:
+          type: integer
+          format: int32
+          nullable: true
+          default: null
+        count:
+          type: integer
+          format: int32
+          default: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("referring to repeated maps among the components, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		opts.OpenAPIFlags.Refs = true
+
+		schemaYAML := `#@data/values-schema
+---
+primary:
+  host: ""
+  port: 5432
+#@schema/nullable
+replica:
+  host: ""
+  port: 5432
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        primary:
+          $ref: '#/components/schemas/Type1'
+        replica:
+          nullable: true
+          allOf:
+          - $ref: '#/components/schemas/Type1'
+    Type1:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: ""
+        port:
+          type: integer
+          default: 5432
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("declaring data values as parameters among the components, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		opts.OpenAPIFlags.ParametersIn = "query"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Number of items per page"
+#@schema/validation min=1, max=100
+limit: 20
+#@schema/nullable
+cursor: ""
+verbose: false
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  parameters:
+    limit:
+      name: limit
+      in: query
+      required: false
+      schema:
+        type: integer
+        description: Number of items per page
+        default: 20
+        minimum: 1
+        maximum: 100
+    cursor:
+      name: cursor
+      in: query
+      required: false
+      schema:
+        type: string
+        nullable: true
+        default: null
+    verbose:
+      name: verbose
+      in: query
+      required: false
+      schema:
+        type: boolean
+        default: false
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("giving write-only strings (only) the password format", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/write-only
+token: ""
+#@schema/write-only
+pin: 1234
+#@schema/write-only
+#@schema/nullable
+passphrase: ""
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        token:
+          type: string
+          format: password
+          writeOnly: true
+          default: ""
+        pin:
+          type: integer
+          writeOnly: true
+          default: 1234
+        passphrase:
+          type: string
+          format: password
+          nullable: true
+          writeOnly: true
+          default: null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
 }
@@ -1122,36 +1536,4705 @@ components:
 
 		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
-}
-
-func TestSchemaInspect_errors(t *testing.T) {
-	t.Run("when --output is anything other than 'openapi-v3'", func(t *testing.T) {
+	t.Run("when extra properties are allowed by @schema/allow-extra-properties", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
 		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
 
 		schemaYAML := `#@data/values-schema
+#@schema/allow-extra-properties
 ---
-foo: doesn't matter
+#@schema/allow-extra-properties 0
+limits: {}
+`
+		expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: true
+      properties:
+        limits:
+          type: object
+          additionalProperties:
+            type: integer
+            default: 0
+          properties: {}
 `
-		expectedErr := "Data values schema export only supported in OpenAPI v3 format; specify format with --output=openapi-v3 flag"
 
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
 		})
 
-		assertFails(t, filesToProcess, expectedErr, opts)
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
 	})
+}
 
-	t.Run("when --output is set to 'openapi-v3' but not inspecting schema", func(t *testing.T) {
+func TestSchemaInspect_exports_a_JSON_Schema_doc(t *testing.T) {
+	t.Run("for all inferred types with their inferred defaults", func(t *testing.T) {
 		opts := cmdtpl.NewOptions()
-		opts.DataValuesFlags.InspectSchema = false
-		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
 
 		schemaYAML := `#@data/values-schema
 ---
-foo: doesn't matter
+foo:
+  int_key: 10
+  bool_key: true
+  string_key: some text
+  float_key: 9.1
+  array_of_scalars:
+  - ""
+  array_of_maps:
+  - foo: ""
 `
-		expectedErr := "Output type currently only supported for data values schema (i.e. include --data-values-schema-inspect)"
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  foo:
+    type: object
+    additionalProperties: false
+    properties:
+      int_key:
+        type: integer
+        default: 10
+      bool_key:
+        type: boolean
+        default: true
+      string_key:
+        type: string
+        default: some text
+      float_key:
+        type: number
+        default: 9.1
+      array_of_scalars:
+        type: array
+        items:
+          type: string
+          default: ""
+        default: []
+      array_of_maps:
+        type: array
+        items:
+          type: object
+          additionalProperties: false
+          properties:
+            foo:
+              type: string
+              default: ""
+          default:
+            foo: ""
+        default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including nullable and 'any' values", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+nullable_key: ""
+#@schema/type any=True
+any_key: anything
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  nullable_key:
+    type:
+    - string
+    - "null"
+    default: null
+  any_key:
+    type:
+    - "null"
+    - string
+    - integer
+    - number
+    - object
+    - array
+    - boolean
+    default: anything
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("distinguishing integers from numbers", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+int_key: 1
+float_key: 1.5
+whole_float_key: 1.0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("without formats", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  int_key:
+    type: integer
+    default: 1
+  float_key:
+    type: number
+    default: 1.5
+  whole_float_key:
+    type: number
+    default: 1
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("with float format, when requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.FloatFormat = true
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  int_key:
+    type: integer
+    default: 1
+  float_key:
+    type: number
+    format: float
+    default: 1.5
+  whole_float_key:
+    type: number
+    format: float
+    default: 1
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("rendered as JSON by default", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+foo: 0
+`
+		expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt","type":"object","additionalProperties":false,"properties":{"foo":{"type":"integer","default":0}}}`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, "data-values-schema.json", out.Files[0].RelativePath())
+		require.Equal(t, files.TypeJSON, out.Files[0].Type())
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+	t.Run("rendered as YAML when asked", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+foo: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, "data-values-schema.yml", out.Files[0].RelativePath())
+		require.Equal(t, files.TypeYAML, out.Files[0].Type())
+	})
+	t.Run("allowing extra properties on annotated maps", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+strict:
+  name: ""
+#@schema/allow-extra-properties
+open:
+  name: ""
+#@schema/allow-extra-properties ""
+labels: {}
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  strict:
+    type: object
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        default: ""
+  open:
+    type: object
+    additionalProperties: true
+    properties:
+      name:
+        type: string
+        default: ""
+  labels:
+    type: object
+    additionalProperties:
+      type: string
+      default: ""
+    properties: {}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("constraining the keys of annotated maps to a pattern", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/allow-extra-properties 0
+#@schema/key-pattern "^[a-z]+$"
+ports: {}
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  ports:
+    type: object
+    additionalProperties:
+      type: integer
+      default: 0
+    propertyNames:
+      pattern: ^[a-z]+$
+    properties: {}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("permitting keys matching a pattern beside those declared", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/pattern-property "^x-", ""
+api:
+  version: v1
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  api:
+    type: object
+    additionalProperties: false
+    properties:
+      version:
+        type: string
+        default: v1
+    patternProperties:
+      ^x-:
+        type: string
+        default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("requiring keys when others are given", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/dependent-required {"enabled": ["cert", "key"]}
+tls:
+  enabled: false
+  cert: ""
+  key: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  tls:
+    type: object
+    additionalProperties: false
+    properties:
+      enabled:
+        type: boolean
+        default: false
+      cert:
+        type: string
+        default: ""
+      key:
+        type: string
+        default: ""
+    dependentRequired:
+      enabled:
+      - cert
+      - key
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("requiring keys when another has a given value", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/when "mode", "advanced", require=["threads", "cache_size"]
+tuning:
+  mode: basic
+  #@schema/nullable
+  threads: 0
+  #@schema/nullable
+  cache_size: 0
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  tuning:
+    type: object
+    additionalProperties: false
+    properties:
+      mode:
+        type: string
+        default: basic
+      threads:
+        type:
+        - integer
+        - "null"
+        default: null
+      cache_size:
+        type:
+        - integer
+        - "null"
+        default: null
+    if:
+      properties:
+        mode:
+          const: advanced
+      required:
+      - mode
+    then:
+      required:
+      - threads
+      - cache_size
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("requiring keys of the items of an array by their discriminator", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+listeners:
+#@schema/discriminator "protocol"
+- protocol: https
+  port: 443
+  cert: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  listeners:
+    type: array
+    items:
+      type: object
+      additionalProperties: false
+      properties:
+        protocol:
+          type: string
+          default: https
+        port:
+          type: integer
+          default: 443
+        cert:
+          type: string
+          default: ""
+      if:
+        properties:
+          protocol:
+            const: https
+        required:
+        - protocol
+      then:
+        required:
+        - port
+        - cert
+      default:
+        protocol: https
+        port: 443
+        cert: ""
+    default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("giving the default of each item of arrays, to scaffold new items", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+servers:
+- host: localhost
+  port: 8080
+  tags: [""]
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  servers:
+    type: array
+    items:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: localhost
+        port:
+          type: integer
+          default: 8080
+        tags:
+          type: array
+          items:
+            type: string
+            default: ""
+          default: []
+      default:
+        host: localhost
+        port: 8080
+        tags: []
+    default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("documenting the items of arrays apart from the arrays themselves", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Hosts to serve"
+hosts:
+#@schema/title "Host"
+#@schema/desc "A host name"
+- ""
+#@schema/desc "Servers"
+servers:
+#@schema/desc "A server"
+- name: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  hosts:
+    type: array
+    description: Hosts to serve
+    items:
+      title: Host
+      type: string
+      description: A host name
+      default: ""
+    default: []
+  servers:
+    type: array
+    description: Servers
+    items:
+      type: object
+      additionalProperties: false
+      description: A server
+      properties:
+        name:
+          type: string
+          default: ""
+      default:
+        name: ""
+    default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("keeping line breaks in descriptions, without trailing whitespace", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "The port to listen on.  \nMust not be in use.\t\r\nDefaults to 8080.\n"
+port: 8080
+`
+		expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt","type":"object","additionalProperties":false,"properties":{"port":{"type":"integer","description":"The port to listen on.\nMust not be in use.\nDefaults to 8080.","default":8080}}}`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+	t.Run("listing all examples of a value", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/examples ("local", "localhost"), ("remote", "db.example.com")
+host: ""
+#@schema/examples ("a pool", {"size": 5, "nested": {"timeouts": [1, 2.5]}})
+pool:
+  size: 0
+  nested:
+    timeouts: [0.0]
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    examples:
+    - localhost
+    - db.example.com
+    default: ""
+  pool:
+    type: object
+    additionalProperties: false
+    examples:
+    - size: 5
+      nested:
+        timeouts:
+        - 1
+        - 2.5
+    properties:
+      size:
+        type: integer
+        default: 0
+      nested:
+        type: object
+        additionalProperties: false
+        properties:
+          timeouts:
+            type: array
+            items:
+              type: number
+              default: 0
+            default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including formats of strings", func(t *testing.T) {
+		t.Run("that are known", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			schemaYAML := `#@data/values-schema
+---
+#@schema/format "date-time"
+created: ""
+`
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  created:
+    type: string
+    format: date-time
+    default: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("that are known, as they are in either draft", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+#@schema/format "email"
+contact: ""
+#@schema/format "hostname"
+host: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+			properties := `properties:
+  contact:
+    type: string
+    format: email
+    default: ""
+  host:
+    type: string
+    format: hostname
+    default: ""
+`
+			for draft, uri := range map[string]string{
+				"2020-12":  "https://json-schema.org/draft/2020-12/schema",
+				"draft-07": "http://json-schema.org/draft-07/schema#",
+			} {
+				t.Run(draft, func(t *testing.T) {
+					opts := cmdtpl.NewOptions()
+					opts.DataValuesFlags.InspectSchema = true
+					opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+					opts.JSONSchemaFlags.Draft = draft
+
+					expected := `$schema: ` + uri + `
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+` + properties
+					assertSucceedsDocSet(t, filesToProcess, expected, opts)
+				})
+			}
+		})
+		t.Run("that are custom, when allowed", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.DataValuesFlags.AllowCustomFormats = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			schemaYAML := `#@data/values-schema
+---
+#@schema/format "semver"
+version: ""
+`
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  version:
+    type: string
+    format: semver
+    default: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("including the encoding and media type of the content of strings", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/content-encoding "base64"
+ca_bundle: ""
+#@schema/content-media-type "application/json"
+#@schema/nullable
+extra_config: "{}"
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  ca_bundle:
+    type: string
+    contentEncoding: base64
+    default: ""
+  extra_config:
+    type:
+    - string
+    - "null"
+    contentMediaType: application/json
+    default: null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("leaving out additionalProperties of maps, when asked", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/no-additional-properties-key
+spec:
+  replicas: 1
+  template:
+    name: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  spec:
+    type: object
+    properties:
+      replicas:
+        type: integer
+        default: 1
+      template:
+        type: object
+        additionalProperties: false
+        properties:
+          name:
+            type: string
+            default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("collapsing a wrapper map into the schema of its only key", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/unwrap
+server:
+  #@schema/desc "Ports to listen on"
+  #@schema/validation min_len=1
+  ports: [8080]
+name: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  server:
+    type: array
+    description: Ports to listen on
+    items:
+      type: integer
+      default: 8080
+    default: []
+    minItems: 1
+  name:
+    type: string
+    default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("allowing a single item in place of an array", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Hosts to serve"
+#@schema/scalar-or-array
+#@schema/validation min_len=1
+hosts:
+- ""
+#@schema/scalar-or-array
+#@schema/nullable
+ports:
+- 80
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  hosts:
+    description: Hosts to serve
+    default: []
+    minItems: 1
+    anyOf:
+    - type: string
+      default: ""
+    - type: array
+      items:
+        type: string
+        default: ""
+  ports:
+    default: null
+    anyOf:
+    - anyOf:
+      - type: integer
+        default: 80
+      - type: array
+        items:
+          type: integer
+          default: 80
+    - type: "null"
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("allowing null for the items of an array, apart from the array itself", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/nullable-items
+tags:
+- ""
+#@schema/nullable-items
+ports:
+- 80
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  tags:
+    type:
+    - array
+    - "null"
+    items:
+      type:
+      - string
+      - "null"
+      default: null
+    default: null
+  ports:
+    type: array
+    items:
+      type:
+      - integer
+      - "null"
+      default: null
+    default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("giving the unit of numbers as a vendor extension", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Timeout of requests"
+#@schema/unit "seconds"
+timeout: 30
+#@schema/unit "bytes"
+#@schema/nullable
+max_body: 1024
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  timeout:
+    type: integer
+    description: Timeout of requests
+    default: 30
+    x-unit: seconds
+  max_body:
+    type:
+    - integer
+    - "null"
+    default: null
+    x-unit: bytes
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("giving numbers the type named via @schema/type", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+ratio: 0.0
+#@schema/type "integer"
+replicas: 0.0
+#@schema/type "number"
+weight: 0
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  ratio:
+    type: number
+    default: 0
+  replicas:
+    type: integer
+    default: 0
+  weight:
+    type: number
+    default: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("including comments for maintainers only as $comment", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/comment "kept in step with the chart's values.yaml"
+---
+#@schema/title "Database"
+#@schema/comment "see ADR-12"
+db:
+  #@schema/desc "Replicas to connect to"
+  #@schema/comment "order matters to the driver"
+  replicas:
+  #@schema/comment "host names only"
+  - ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+$comment: kept in step with the chart's values.yaml
+properties:
+  db:
+    title: Database
+    type: object
+    additionalProperties: false
+    $comment: see ADR-12
+    properties:
+      replicas:
+        type: array
+        description: Replicas to connect to
+        $comment: order matters to the driver
+        items:
+          type: string
+          $comment: host names only
+          default: ""
+        default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("marking deprecated values, along with the dates of their deprecation", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/deprecated "use 'hosts' instead", since="2024-06-01", remove="2025-01-01"
+#@schema/extension "x-owner", "team-a"
+host: ""
+#@schema/deprecated since="2024-06-01T12:00:00Z"
+port: 0
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    deprecated: true
+    description: 'Deprecated: use ''hosts'' instead'
+    default: ""
+    x-deprecation-date: "2024-06-01"
+    x-removal-date: "2025-01-01"
+    x-owner: team-a
+  port:
+    type: integer
+    deprecated: true
+    default: 0
+    x-deprecation-date: "2024-06-01T12:00:00Z"
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("marking deprecated values, noting why in their description", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Connection settings"
+#@schema/deprecated "use 'database' instead"
+db_conn:
+  host: ""
+#@schema/deprecated
+legacy: [""]
+#@schema/deprecated "no longer read"
+port: 0
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  db_conn:
+    type: object
+    additionalProperties: false
+    deprecated: true
+    description: |-
+      Connection settings
+      Deprecated: use 'database' instead
+    properties:
+      host:
+        type: string
+        default: ""
+  legacy:
+    type: array
+    deprecated: true
+    items:
+      type: string
+      default: ""
+    default: []
+  port:
+    type: integer
+    deprecated: true
+    description: 'Deprecated: no longer read'
+    default: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("marking read-only values", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/read-only
+status:
+  phase: ""
+  #@schema/read-only
+  observed_generation: 0
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  status:
+    type: object
+    additionalProperties: false
+    readOnly: true
+    properties:
+      phase:
+        type: string
+        default: ""
+      observed_generation:
+        type: integer
+        readOnly: true
+        default: 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("marking a document read-only, as a whole", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/read-only
+---
+name: app
+#@schema/read-only
+id: ""
+nested:
+  a: 1
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+readOnly: true
+properties:
+  name:
+    type: string
+    default: app
+  id:
+    type: string
+    readOnly: true
+    default: ""
+  nested:
+    type: object
+    additionalProperties: false
+    properties:
+      a:
+        type: integer
+        default: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("marking write-only values", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/write-only
+credentials:
+  username: ""
+  #@schema/write-only
+  password: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  credentials:
+    type: object
+    additionalProperties: false
+    writeOnly: true
+    properties:
+      username:
+        type: string
+        default: ""
+      password:
+        type: string
+        format: password
+        writeOnly: true
+        default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("giving write-only strings (only) the password format", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/write-only
+token: ""
+#@schema/write-only
+pin: 1234
+#@schema/write-only
+#@schema/nullable
+passphrase: ""
+#@schema/write-only
+#@schema/format "uri"
+callback: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  token:
+    type: string
+    format: password
+    writeOnly: true
+    default: ""
+  pin:
+    type: integer
+    writeOnly: true
+    default: 1234
+  passphrase:
+    type:
+    - string
+    - "null"
+    format: password
+    writeOnly: true
+    default: null
+  callback:
+    type: string
+    format: uri
+    writeOnly: true
+    default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("refers to an external schema in place of a map so annotated", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/external-ref "https://example.com/requests.json"
+requests:
+  cpu: 100m
+  memory: 128Mi
+#@schema/external-ref "https://example.com/requests.json"
+#@schema/nullable
+limits:
+  cpu: ""
+  memory: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  requests:
+    $ref: https://example.com/requests.json
+  limits:
+    anyOf:
+    - $ref: https://example.com/requests.json
+    - type: "null"
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("typing an array as a tuple of its items", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/tuple
+endpoint:
+- localhost
+- 8080
+`
+		t.Run("in draft 2020-12", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  endpoint:
+    type: array
+    prefixItems:
+    - type: string
+      default: localhost
+    - type: integer
+      default: 8080
+    items: false
+    default:
+    - localhost
+    - 8080
+    minItems: 2
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("in draft-07", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Draft = "draft-07"
+
+			expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  endpoint:
+    type: array
+    items:
+    - type: string
+      default: localhost
+    - type: integer
+      default: 8080
+    additionalItems: false
+    default:
+    - localhost
+    - 8080
+    minItems: 2
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+}
+
+func TestSchemaInspect_JSON_Schema_validations(t *testing.T) {
+	t.Run("constrain the length of strings", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+names:
+  #@schema/validation min_len=3, max_len=64
+  constrained: ""
+  unconstrained: ""
+  #@schema/nullable
+  #@schema/validation max_len=8
+  nullable: ""
+  #@schema/validation min_len=1
+  not_a_string: true
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  names:
+    type: object
+    additionalProperties: false
+    properties:
+      constrained:
+        type: string
+        default: ""
+        minLength: 3
+        maxLength: 64
+      unconstrained:
+        type: string
+        default: ""
+      nullable:
+        type:
+        - string
+        - "null"
+        default: null
+        maxLength: 8
+      not_a_string:
+        type: boolean
+        default: true
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("bound numbers", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=1, max=5
+int_key: 1
+#@schema/validation min=-1.5, max=2.5, exclusive=True
+float_key: 0.0
+#@schema/nullable
+#@schema/validation min=0
+nullable_key: 0
+#@schema/validation min="a"
+string_key: b
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  int_key:
+    type: integer
+    default: 1
+    minimum: 1
+    maximum: 5
+  float_key:
+    type: number
+    default: 0
+    exclusiveMinimum: -1.5
+    exclusiveMaximum: 2.5
+  nullable_key:
+    type:
+    - integer
+    - "null"
+    default: null
+    minimum: 0
+  string_key:
+    type: string
+    default: b
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("bound numbers exclusively, as the targeted draft expresses it", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=0, exclusive=True
+replicas: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+		for draft, expected := range map[string]string{
+			"draft-04": `$schema: http://json-schema.org/draft-04/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    default: 1
+    minimum: 0
+    exclusiveMinimum: true
+`,
+			"2020-12": `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    default: 1
+    exclusiveMinimum: 0
+`,
+		} {
+			t.Run(draft, func(t *testing.T) {
+				opts := cmdtpl.NewOptions()
+				opts.DataValuesFlags.InspectSchema = true
+				opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+				opts.JSONSchemaFlags.Draft = draft
+
+				assertSucceedsDocSet(t, filesToProcess, expected, opts)
+			})
+		}
+	})
+	t.Run("keep the numeric type of bounds", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=1, max=5
+int_key: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		get := func(m interface{}, key string) interface{} {
+			val, found := m.(*orderedmap.Map).Get(key)
+			require.True(t, found, "expected key %q", key)
+			return val
+		}
+		intKey := get(get(out.DocSet.Items[0].AsInterface(), "properties"), "int_key")
+		require.Equal(t, int64(1), get(intKey, "minimum"))
+		require.Equal(t, int64(5), get(intKey, "maximum"))
+	})
+	t.Run("match strings against patterns", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation pattern="^\\d+\\.\\d+\\.\\d+$"
+version: 1.0.0
+#@schema/validation pattern=["^[a-z]", "[a-z0-9]$"]
+hostname: localhost
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  version:
+    type: string
+    default: 1.0.0
+    pattern: ^\d+\.\d+\.\d+$
+  hostname:
+    type: string
+    default: localhost
+    allOf:
+    - pattern: ^[a-z]
+    - pattern: '[a-z0-9]$'
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("match strings against patterns they must not match", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation not_pattern="^reserved"
+name: app
+#@schema/validation pattern="^[a-z]", not_pattern="^reserved"
+hostname: localhost
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type: string
+    default: app
+    not:
+      pattern: ^reserved
+  hostname:
+    type: string
+    default: localhost
+    allOf:
+    - pattern: ^[a-z]
+    - not:
+        pattern: ^reserved
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("keep patterns verbatim in JSON", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation pattern="^\\d+$"
+port: "80"
+`
+		expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt","type":"object","additionalProperties":false,"properties":{"port":{"type":"string","default":"80","pattern":"^\\d+$"}}}`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+	t.Run("enumerate allowed values", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info", "warn"]
+log_level: info
+#@schema/validation one_of=[3, 1, 2]
+replicas: 1
+#@schema/nullable
+#@schema/validation one_of=["tcp", "udp"]
+protocol: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  log_level:
+    type: string
+    default: info
+    enum:
+    - debug
+    - info
+    - warn
+  replicas:
+    type: integer
+    default: 1
+    enum:
+    - 3
+    - 1
+    - 2
+  protocol:
+    type:
+    - string
+    - "null"
+    default: null
+    enum:
+    - tcp
+    - udp
+    - null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("document allowed values, when described", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info", "warn"]
+#@schema/enum-descriptions {"debug": ("Debug", "Logs everything"), "info": "Logs requests"}
+log_level: info
+#@schema/validation one_of=["tcp", "udp"]
+protocol: tcp
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  log_level:
+    oneOf:
+    - title: Debug
+      description: Logs everything
+      const: debug
+    - description: Logs requests
+      const: info
+    - const: warn
+    type: string
+    default: info
+  protocol:
+    type: string
+    default: tcp
+    enum:
+    - tcp
+    - udp
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("allow values of the type of the scalar via one_of", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=[1, 2, 3]
+replicas: 1
+#@schema/validation one_of=[0.5, 1]
+ratio: 0.5
+#@schema/nullable
+#@schema/validation one_of=["a", None]
+mode: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    default: 1
+    enum:
+    - 1
+    - 2
+    - 3
+  ratio:
+    type: number
+    default: 0.5
+    enum:
+    - 0.5
+    - 1
+  mode:
+    type:
+    - string
+    - "null"
+    default: null
+    enum:
+    - a
+    - null
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("emit every constraint of a validation on the same node", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min_len=3, max_len=16, pattern="^[a-z][a-z0-9-]*$"
+name: app
+#@schema/validation min_len=4, max_len=5, pattern="^[a-z]+$", one_of=["info", "debug"]
+log_level: info
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type: string
+    default: app
+    minLength: 3
+    maxLength: 16
+    pattern: ^[a-z][a-z0-9-]*$
+  log_level:
+    type: string
+    default: info
+    minLength: 4
+    maxLength: 5
+    enum:
+    - info
+    - debug
+    pattern: ^[a-z]+$
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("bound and deduplicate the items of arrays", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/default [80, 443]
+#@schema/validation min_len=1, max_len=3, unique=True
+ports: [0]
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  ports:
+    type: array
+    items:
+      type: integer
+      default: 0
+    default:
+    - 80
+    - 443
+    minItems: 1
+    maxItems: 3
+    uniqueItems: true
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("require arrays to contain some item, a bounded number of times", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/default ["http", "https"]
+#@schema/validation contains="http", min_contains=1, max_contains=1
+protocols: [""]
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  protocols:
+    type: array
+    items:
+      type: string
+      default: ""
+    default:
+    - http
+    - https
+    contains:
+      const: http
+    minContains: 1
+    maxContains: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("keep the constraints of nullable arrays and maps", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/validation min_len=1
+hosts:
+- ""
+#@schema/nullable
+#@schema/validation min_props=1
+db:
+  host: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("alongside a list of types, in 2020-12", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  hosts:
+    type:
+    - array
+    - "null"
+    items:
+      type: string
+      default: ""
+    default: null
+    minItems: 1
+  db:
+    type:
+    - object
+    - "null"
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+    minProperties: 1
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("alongside a choice of schemas, in draft-07", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Draft = "draft-07"
+
+			expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  hosts:
+    default: null
+    minItems: 1
+    anyOf:
+    - type: array
+      items:
+        type: string
+        default: ""
+    - type: "null"
+  db:
+    minProperties: 1
+    anyOf:
+    - type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: ""
+    - type: "null"
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("require numbers to be multiples", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min=8, multiple_of=8
+memory_mb: 64
+#@schema/validation multiple_of=0.5
+#@schema/nullable
+ratio: 1.5
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  memory_mb:
+    type: integer
+    default: 64
+    minimum: 8
+    multipleOf: 8
+  ratio:
+    type:
+    - number
+    - "null"
+    default: null
+    multipleOf: 0.5
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("bound the number of keys of open maps", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/allow-extra-properties
+#@schema/validation min_props=1, max_props=8
+labels:
+  app: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  labels:
+    type: object
+    additionalProperties: true
+    properties:
+      app:
+        type: string
+        default: ""
+    minProperties: 1
+    maxProperties: 8
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("give a single allowed value as a constant", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["v1"]
+apiVersion: v1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("by default", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  apiVersion:
+    type: string
+    default: v1
+    const: v1
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("unless enumerations are preferred", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.NoConst = true
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  apiVersion:
+    type: string
+    default: v1
+    enum:
+    - v1
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("require keys that must not be null", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/nullable
+#@schema/validation not_null=True
+username: ""
+#@schema/type any=True
+password: null
+#@schema/nullable
+email: ""
+port: 8080
+#@schema/validation not_null=True, when=lambda v: False
+home: /
+optional:
+  key: value
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  username:
+    type:
+    - string
+    - "null"
+    default: null
+  password:
+    type:
+    - "null"
+    - string
+    - integer
+    - number
+    - object
+    - array
+    - boolean
+    default: null
+  email:
+    type:
+    - string
+    - "null"
+    default: null
+  port:
+    type: integer
+    default: 8080
+  home:
+    type: string
+    default: /
+  optional:
+    type: object
+    additionalProperties: false
+    properties:
+      key:
+        type: string
+        default: value
+required:
+- password
+- username
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+}
+
+func TestSchemaInspect_JSON_Schema_opts(t *testing.T) {
+	t.Run("sets $id when provided", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.ID = "https://example.com/schemas/my-app.json"
+		opts.JSONSchemaFlags.Draft = "2020-12"
+
+		schemaYAML := `#@data/values-schema
+---
+foo: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+$id: https://example.com/schemas/my-app.json
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  foo:
+    type: string
+    default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("describes the schema as a whole", func(t *testing.T) {
+		expectedWith := func(description string) string {
+			return `$schema: https://json-schema.org/draft/2020-12/schema
+description: ` + description + `
+type: object
+additionalProperties: false
+properties:
+  foo:
+    type: string
+    default: ""
+`
+		}
+		t.Run("as configured", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Description = "Configuration of my-app"
+
+			schemaYAML := `#@data/values-schema
+---
+foo: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expectedWith("Configuration of my-app"), opts)
+		})
+		t.Run("as the document is itself described, even when configured", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Description = "Configuration of my-app"
+
+			schemaYAML := `#@data/values-schema
+#@schema/desc "Values of my-app"
+---
+foo: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			// (in its place among the keywords of the document)
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+additionalProperties: false
+description: Values of my-app
+properties:
+  foo:
+    type: string
+    default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("generically, by default", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			schemaYAML := `#@data/values-schema
+---
+foo: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expectedWith("Schema for data values, generated by ytt"), opts)
+		})
+	})
+	t.Run("titles properties", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+#@schema/title "App config"
+---
+db_conn:
+  maxConnections: 1
+  #@schema/title "Host name"
+  host: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("only via @schema/title, by default", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+title: App config
+type: object
+additionalProperties: false
+properties:
+  db_conn:
+    type: object
+    additionalProperties: false
+    properties:
+      maxConnections:
+        type: integer
+        default: 1
+      host:
+        title: Host name
+        type: string
+        default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("otherwise, after their keys, when requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.TitlesFromKeys = true
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+title: App config
+type: object
+additionalProperties: false
+properties:
+  db_conn:
+    title: Db Conn
+    type: object
+    additionalProperties: false
+    properties:
+      maxConnections:
+        title: Max Connections
+        type: integer
+        default: 1
+      host:
+        title: Host name
+        type: string
+        default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("not at all, when not annotated nor requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			schemaYAML := `#@data/values-schema
+---
+db_conn:
+  host: ""
+`
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  db_conn:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("describes undocumented properties, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.SynthesizeDescriptions = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Name of the app"
+name: foo
+image: foo
+replicas: 1
+#@schema/nullable
+tag: ""
+ports: [0]
+db:
+  #@schema/desc "Host of the database"
+  host: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type: string
+    description: Name of the app
+    default: foo
+  image:
+    type: string
+    description: 'string (default: "foo")'
+    default: foo
+  replicas:
+    type: integer
+    description: 'integer (default: 1)'
+    default: 1
+  tag:
+    type:
+    - string
+    - "null"
+    description: 'string or null (default: null)'
+    default: null
+  ports:
+    type: array
+    description: 'array of integer (default: [])'
+    items:
+      type: integer
+      default: 0
+    default: []
+  db:
+    type: object
+    additionalProperties: false
+    description: object
+    properties:
+      host:
+        type: string
+        description: Host of the database
+        default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("infers formats of strings from their defaults, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.InferFormats = true
+
+		schemaYAML := `#@data/values-schema
+---
+expires: "2024-01-02T15:04:05Z"
+contact: admin@example.com
+homepage: https://example.com/docs
+name: example.com
+#@schema/format "hostname"
+host: https://example.com
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  expires:
+    type: string
+    format: date-time
+    default: "2024-01-02T15:04:05Z"
+  contact:
+    type: string
+    format: email
+    default: admin@example.com
+  homepage:
+    type: string
+    format: uri
+    default: https://example.com/docs
+  name:
+    type: string
+    default: example.com
+  host:
+    type: string
+    format: hostname
+    default: https://example.com
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("gives defaults as examples, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.ExamplesFromDefaults = true
+
+		schemaYAML := `#@data/values-schema
+---
+port: 8080
+#@schema/examples ("the usual port", 443)
+tls_port: 8443
+#@schema/nullable
+name: ""
+db:
+  hosts:
+  - localhost
+  #@schema/default ["a.example.com", "b.example.com"]
+  replicas:
+  - ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  port:
+    type: integer
+    examples:
+    - 8080
+    default: 8080
+  tls_port:
+    type: integer
+    examples:
+    - 443
+    default: 8443
+  name:
+    type:
+    - string
+    - "null"
+    default: null
+  db:
+    type: object
+    additionalProperties: false
+    properties:
+      hosts:
+        type: array
+        items:
+          type: string
+          examples:
+          - localhost
+          default: localhost
+        default: []
+      replicas:
+        type: array
+        examples:
+        - - a.example.com
+          - b.example.com
+        items:
+          type: string
+          examples:
+          - ""
+          default: ""
+        default:
+        - a.example.com
+        - b.example.com
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("marks properties deprecated by their validations, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.DeprecatedFromValidations = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation ("DEPRECATED: use replicas instead", lambda v: True), min=1
+instances: 1
+#@schema/validation ("at least one replica", lambda v: v > 0), min=1
+replicas: 1
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  instances:
+    type: integer
+    deprecated: true
+    default: 1
+    minimum: 1
+  replicas:
+    type: integer
+    default: 1
+    minimum: 1
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("appends validation messages to descriptions, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.MergeValidationMessages = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Number of replicas"
+#@schema/validation min=1, max=100
+replicas: 1
+#@schema/validation ("a DNS name", lambda v: "." in v)
+host: a.com
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    description: Number of replicas. Must be a value >= 1 and a value <= 100.
+    default: 1
+    minimum: 1
+    maximum: 100
+  host:
+    type: string
+    description: Must be a DNS name.
+    default: a.com
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("requires every key, regardless of its default, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.RequiredAll = true
+
+		schemaYAML := `#@data/values-schema
+---
+server:
+  port: 8080
+  host: localhost
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  server:
+    type: object
+    additionalProperties: false
+    properties:
+      port:
+        type: integer
+        default: 8080
+      host:
+        type: string
+        default: localhost
+    required:
+    - host
+    - port
+required:
+- server
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("appends the unit of numbers to their descriptions, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.UnitDescriptions = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Timeout of requests"
+#@schema/unit "seconds"
+timeout: 30
+#@schema/unit "bytes"
+#@schema/nullable
+max_body: 1024
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  timeout:
+    type: integer
+    description: Timeout of requests (seconds)
+    default: 30
+    x-unit: seconds
+  max_body:
+    type:
+    - integer
+    - "null"
+    description: (bytes)
+    default: null
+    x-unit: bytes
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("refers to repeated enums, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.EnumRefs = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info", "error"]
+log_level: info
+audit:
+  #@schema/validation one_of=["error", "info", "debug"]
+  level: error
+#@schema/validation one_of=["tcp", "udp"]
+protocol: tcp
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  log_level:
+    $ref: '#/$defs/Enum1'
+    type: string
+    default: info
+  audit:
+    type: object
+    additionalProperties: false
+    properties:
+      level:
+        $ref: '#/$defs/Enum1'
+        type: string
+        default: error
+  protocol:
+    type: string
+    default: tcp
+    enum:
+    - tcp
+    - udp
+$defs:
+  Enum1:
+    enum:
+    - debug
+    - info
+    - error
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("omits the password format of write-only strings, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		cmd := &cobra.Command{}
+		opts.BindFlags(cmd.Flags())
+		err := cmd.Flags().Parse([]string{"--data-values-schema-inspect", "-o", "json-schema-yaml", "--json-schema-no-password-format"})
+		require.NoError(t, err)
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/write-only
+token: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  token:
+    type: string
+    writeOnly: true
+    default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("declares the vocabularies given, for draft 2020-12 only", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+replicas: 1
+`
+		vocabulary := []string{
+			"https://json-schema.org/draft/2020-12/vocab/validation=true",
+			"https://json-schema.org/draft/2020-12/vocab/core=true",
+			"https://example.com/vocab/units=false",
+		}
+		t.Run("for draft 2020-12", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Vocabulary = vocabulary
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+$vocabulary:
+  https://example.com/vocab/units: false
+  https://json-schema.org/draft/2020-12/vocab/core: true
+  https://json-schema.org/draft/2020-12/vocab/validation: true
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    default: 1
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("omitted for draft-07", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Vocabulary = vocabulary
+			opts.JSONSchemaFlags.Draft = "draft-07"
+
+			expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    default: 1
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("gives defaults", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+port: 8080
+#@schema/nullable
+name: ""
+servers:
+- host: localhost
+#@schema/type any=True
+extra: {}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("by default", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  port:
+    type: integer
+    default: 8080
+  name:
+    type:
+    - string
+    - "null"
+    default: null
+  servers:
+    type: array
+    items:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: localhost
+      default:
+        host: localhost
+    default: []
+  extra:
+    type:
+    - "null"
+    - string
+    - integer
+    - number
+    - object
+    - array
+    - boolean
+    default: {}
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("not at all, when requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.NoDefaults = true
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  port:
+    type: integer
+  name:
+    type:
+    - string
+    - "null"
+  servers:
+    type: array
+    items:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+  extra:
+    type:
+    - "null"
+    - string
+    - integer
+    - number
+    - object
+    - array
+    - boolean
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("of maps too, built from the defaults of their keys, when requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.MapDefaults = true
+
+			schemaYAML := `#@data/values-schema
+---
+db:
+  port: 5432
+  tls:
+    enabled: false
+    #@schema/nullable
+    ca: ""
+`
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  db:
+    type: object
+    additionalProperties: false
+    properties:
+      port:
+        type: integer
+        default: 5432
+      tls:
+        type: object
+        additionalProperties: false
+        properties:
+          enabled:
+            type: boolean
+            default: false
+          ca:
+            type:
+            - string
+            - "null"
+            default: null
+        default:
+          enabled: false
+          ca: null
+    default:
+      port: 5432
+      tls:
+        enabled: false
+        ca: null
+default:
+  db:
+    port: 5432
+    tls:
+      enabled: false
+      ca: null
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("sorts the types of 'any' values, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.SortAnyTypes = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/type any=True
+anything: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  anything:
+    type:
+    - array
+    - boolean
+    - integer
+    - "null"
+    - number
+    - object
+    - string
+    default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("orders keywords", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "Connection settings"
+#@schema/validation min_len=1
+servers:
+- host: ""
+  #@schema/validation min=1, max=65535
+  port: 8080
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("as in OpenAPI documents, by default", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  servers:
+    type: array
+    description: Connection settings
+    items:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: ""
+        port:
+          type: integer
+          default: 8080
+          minimum: 1
+          maximum: 65535
+      default:
+        host: ""
+        port: 8080
+    default: []
+    minItems: 1
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("in reading order, when requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.PreserveOrder = true
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  servers:
+    description: Connection settings
+    type: array
+    minItems: 1
+    default: []
+    items:
+      type: object
+      default:
+        host: ""
+        port: 8080
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: ""
+        port:
+          type: integer
+          minimum: 1
+          maximum: 65535
+          default: 8080
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("expresses nullable values according to the draft (or specification)", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+#@schema/desc "The name"
+#@schema/nullable
+name: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("in 2020-12, as a list of types", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Draft = "2020-12"
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type:
+    - string
+    - "null"
+    description: The name
+    default: null
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("in draft-07, as a choice of schemas", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Draft = "draft-07"
+
+			expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    description: The name
+    default: null
+    anyOf:
+    - type: string
+    - type: "null"
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("unlike OpenAPI v3.0, marked nullable (with a single type)", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+			expected := `openapi: 3.0.0
+info:
+  version: 0.1.0
+  title: Schema for data values, generated by ytt
+paths: {}
+components:
+  schemas:
+    dataValues:
+      type: object
+      additionalProperties: false
+      properties:
+        name:
+          type: string
+          nullable: true
+          description: The name
+          default: null
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("refers to repeated maps", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+primary:
+  host: ""
+  port: 5432
+  tls: false
+replica:
+  port: 5432
+  host: ""
+  tls: false
+#@schema/nullable
+backup:
+  host: ""
+  port: 5432
+  tls: false
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		t.Run("declared in $defs", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  primary:
+    $ref: '#/$defs/Type1'
+  replica:
+    $ref: '#/$defs/Type1'
+  backup:
+    anyOf:
+    - $ref: '#/$defs/Type1'
+    - type: "null"
+$defs:
+  Type1:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+      port:
+        type: integer
+        default: 5432
+      tls:
+        type: boolean
+        default: false
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("declared in definitions, in draft-07", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Draft = "draft-07"
+
+			expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  primary:
+    $ref: '#/definitions/Type1'
+  replica:
+    $ref: '#/definitions/Type1'
+  backup:
+    anyOf:
+    - $ref: '#/definitions/Type1'
+    - type: "null"
+definitions:
+  Type1:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+      port:
+        type: integer
+        default: 5432
+      tls:
+        type: boolean
+        default: false
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("unless references are disabled", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+			opts.JSONSchemaFlags.NoRefs = true
+
+			out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+			require.NoError(t, out.Err)
+
+			require.Len(t, out.Files, 1)
+			require.NotContains(t, string(out.Files[0].Bytes()), "$ref")
+			require.NotContains(t, string(out.Files[0].Bytes()), "$defs")
+		})
+	})
+	t.Run("keeps keywords alongside references in draft-07", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.Draft = "draft-07"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation min_len=1
+labels:
+  app: ""
+annotations:
+  app: ""
+`
+		expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  labels:
+    minProperties: 1
+    allOf:
+    - $ref: '#/definitions/Type1'
+  annotations:
+    $ref: '#/definitions/Type1'
+definitions:
+  Type1:
+    type: object
+    additionalProperties: false
+    properties:
+      app:
+        type: string
+        default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("dropping keywords the draft does not define, when asked", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.Draft = "draft-04"
+		opts.JSONSchemaFlags.DropUnsupported = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/deprecated "use 'hosts' instead"
+host: ""
+#@schema/format "uuid"
+#@schema/comment "generated on install"
+id: ""
+#@schema/when "mode", "advanced", require=["threads"]
+config:
+  mode: basic
+  threads: 0
+  #@schema/read-only
+  deprecated: false
+`
+		expected := `$schema: http://json-schema.org/draft-04/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    description: 'Deprecated: use ''hosts'' instead'
+    default: ""
+  id:
+    type: string
+    default: ""
+  config:
+    type: object
+    additionalProperties: false
+    properties:
+      mode:
+        type: string
+        default: basic
+      threads:
+        type: integer
+        default: 0
+      deprecated:
+        type: boolean
+        default: false
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("rewrites what draft-07 expresses otherwise", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.Draft = "draft-07"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/schema-name "Endpoint"
+#@schema/dependent-required {"port": ["host"]}
+api:
+  host: ""
+  port: 0
+#@schema/schema-name "Endpoint"
+#@schema/dependent-required {"port": ["host"]}
+metrics:
+  host: ""
+  port: 0
+`
+		expected := `$schema: http://json-schema.org/draft-07/schema#
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  api:
+    $ref: '#/definitions/Endpoint'
+  metrics:
+    $ref: '#/definitions/Endpoint'
+definitions:
+  Endpoint:
+    $id: '#Endpoint'
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+      port:
+        type: integer
+        default: 0
+    dependencies:
+      port:
+      - host
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("allows null for every string, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.NullableStrings = true
+
+		schemaYAML := `#@data/values-schema
+---
+name: app
+replicas: 1
+#@schema/nullable
+owner: ""
+#@schema/validation one_of=["debug", "info"]
+log_level: info
+tags:
+- ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type:
+    - string
+    - "null"
+    default: app
+  replicas:
+    type: integer
+    default: 1
+  owner:
+    type:
+    - string
+    - "null"
+    default: null
+  log_level:
+    type:
+    - string
+    - "null"
+    default: info
+    enum:
+    - debug
+    - info
+    - null
+  tags:
+    type: array
+    items:
+      type:
+      - string
+      - "null"
+      default: ""
+    default: []
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("defines named maps under their name, regardless of their siblings", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		endpointSchemaYAML := `#@schema/schema-name "Endpoint"
+api:
+  host: ""
+  port: 0
+`
+		endpointDef := `  Endpoint:
+    $anchor: Endpoint
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+      port:
+        type: integer
+        default: 0
+`
+		t.Run("when alone", func(t *testing.T) {
+			schemaYAML := "#@data/values-schema\n---\n" + endpointSchemaYAML
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  api:
+    $ref: '#/$defs/Endpoint'
+$defs:
+` + endpointDef
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("when unrelated (repeated) maps are added", func(t *testing.T) {
+			schemaYAML := `#@data/values-schema
+---
+primary:
+  user: ""
+replica:
+  user: ""
+` + endpointSchemaYAML
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  primary:
+    $ref: '#/$defs/Type1'
+  replica:
+    $ref: '#/$defs/Type1'
+  api:
+    $ref: '#/$defs/Endpoint'
+$defs:
+  Type1:
+    type: object
+    additionalProperties: false
+    properties:
+      user:
+        type: string
+        default: ""
+` + endpointDef
+			filesToProcess := files.NewSortedFiles([]*files.File{
+				files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			})
+
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("identifies named maps by their own $id, when given", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/schema-name "Endpoint", id="https://example.com/schemas/endpoint.json"
+api:
+  host: ""
+#@schema/schema-name "Endpoint", id="https://example.com/schemas/endpoint.json"
+metrics:
+  host: ""
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  api:
+    $ref: '#/$defs/Endpoint'
+  metrics:
+    $ref: '#/$defs/Endpoint'
+$defs:
+  Endpoint:
+    $id: https://example.com/schemas/endpoint.json
+    $anchor: Endpoint
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("declares keys added by data values, when requested", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+db:
+  host: ""
+`
+		valuesYAML := `#@ load("@ytt:overlay", "overlay")
+#@data/values
+---
+db:
+  #@overlay/match missing_ok=True
+  port: 5432
+#@overlay/match missing_ok=True
+replicas:
+- host: replica-1
+  tls: true
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+			files.MustNewFileFromSource(files.NewBytesSource("values.yml", []byte(valuesYAML))),
+		})
+
+		t.Run("by default, not", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  db:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("when requested", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.DataValuesFlags.InspectSchemaWithDataValues = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  db:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+      port:
+        type: integer
+        default: 5432
+  replicas:
+    type: array
+    items:
+      type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: replica-1
+        tls:
+          type: boolean
+          default: true
+      default:
+        host: replica-1
+        tls: true
+    default: []
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("bounds the length of strings allowed via one_of, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.EnumLengths = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info", "warning"]
+log_level: info
+#@schema/validation one_of=["ab", "abcd"], max_len=3
+#@schema/nullable
+code: ab
+#@schema/validation one_of=[1, 22]
+level: 1
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  log_level:
+    type: string
+    default: info
+    minLength: 4
+    maxLength: 7
+    enum:
+    - debug
+    - info
+    - warning
+  code:
+    type:
+    - string
+    - "null"
+    default: null
+    minLength: 2
+    maxLength: 3
+    enum:
+    - ab
+    - abcd
+    - null
+  level:
+    type: integer
+    default: 1
+    enum:
+    - 1
+    - 22
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("bounds integers given a width by those of that width, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.FormatBounds = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "int32"
+replicas: 1
+#@schema/format "int32"
+#@schema/validation max=10
+workers: 2
+#@schema/format "int64"
+#@schema/nullable
+offset: 0
+timeout: 30
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  replicas:
+    type: integer
+    format: int32
+    default: 1
+    minimum: -2147483648
+    maximum: 2147483647
+  workers:
+    type: integer
+    format: int32
+    default: 2
+    minimum: -2147483648
+    maximum: 10
+  offset:
+    type:
+    - integer
+    - "null"
+    format: int64
+    default: null
+    minimum: -9223372036854775808
+    maximum: 9223372036854775807
+  timeout:
+    type: integer
+    default: 30
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("tells apart nullable keys given as null from absent ones, when requested", func(t *testing.T) {
+		schemaYAML := `#@data/values-schema
+---
+name: app
+#@schema/nullable
+version: ""
+#@schema/nullable
+db:
+  host: ""
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+		properties := `properties:
+  name:
+    type: string
+    default: app
+  version:
+    default: null
+    anyOf:
+    - type: string
+    - type: "null"
+  db:
+    anyOf:
+    - type: object
+      additionalProperties: false
+      properties:
+        host:
+          type: string
+          default: ""
+    - type: "null"
+`
+
+		t.Run("leniently: a nullable key may be absent", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Nulls = "lenient"
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+` + properties
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+		t.Run("strictly: a nullable key must be present, if only as null", func(t *testing.T) {
+			opts := cmdtpl.NewOptions()
+			opts.DataValuesFlags.InspectSchema = true
+			opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+			opts.JSONSchemaFlags.Nulls = "strict"
+
+			expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+` + properties + `required:
+- db
+- version
+`
+			assertSucceedsDocSet(t, filesToProcess, expected, opts)
+		})
+	})
+	t.Run("gives defaults as examples, when requested", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.DefaultsAsExamples = true
+
+		schemaYAML := `#@data/values-schema
+---
+host: localhost
+#@schema/examples ("HTTPS", 443)
+port: 80
+#@schema/nullable
+timeout: 0
+hosts:
+- name: ""
+  port: 0
+`
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    examples:
+    - localhost
+  port:
+    type: integer
+    examples:
+    - 80
+    - 443
+  timeout:
+    type:
+    - integer
+    - "null"
+    examples:
+    - null
+  hosts:
+    type: array
+    examples:
+    - []
+    items:
+      type: object
+      additionalProperties: false
+      examples:
+      - name: ""
+        port: 0
+      properties:
+        name:
+          type: string
+          examples:
+          - ""
+        port:
+          type: integer
+          examples:
+          - 0
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertSucceedsDocSet(t, filesToProcess, expected, opts)
+	})
+	t.Run("splitting the schema into a file per definition, when asked", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema-yaml"}
+		opts.JSONSchemaFlags.SplitDir = "schemas"
+
+		schemaYAML := `#@data/values-schema
+---
+primary:
+  host: ""
+  port: 0
+replica:
+  host: ""
+  port: 0
+#@schema/schema-name "Volume"
+volume:
+  size: ""
+`
+		expectedFiles := map[string]string{
+			"data-values-schema.yml": `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  primary:
+    $ref: Type1.yml
+  replica:
+    $ref: Type1.yml
+  volume:
+    $ref: Volume.yml
+`,
+			"Type1.yml": `$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+    default: ""
+  port:
+    type: integer
+    default: 0
+`,
+			"Volume.yml": `$schema: https://json-schema.org/draft/2020-12/schema
+$anchor: Volume
+type: object
+additionalProperties: false
+properties:
+  size:
+    type: string
+    default: ""
+`,
+		}
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Nil(t, out.DocSet)
+		require.Len(t, out.Files, len(expectedFiles))
+		fileNames := map[string]bool{}
+		for _, file := range out.Files {
+			fileNames[file.RelativePath()] = true
+		}
+		for _, file := range out.Files {
+			expected, found := expectedFiles[file.RelativePath()]
+			require.True(t, found, "unexpected file %s", file.RelativePath())
+			require.Equal(t, expected, string(file.Bytes()))
+
+			// each reference resolves to a file alongside the one referring to it.
+			for _, ref := range regexp.MustCompile(`\$ref: (.+)`).FindAllStringSubmatch(string(file.Bytes()), -1) {
+				require.True(t, fileNames[ref[1]], "reference to missing file %s", ref[1])
+			}
+		}
+	})
+}
+
+func TestSchemaInspect_exports_TypeScript_types(t *testing.T) {
+	t.Run("of a nested document", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"ts"}
+
+		schemaYAML := `#@data/values-schema
+#@schema/desc "Configuration of the app"
+---
+#@schema/desc "Name of the app"
+name: app
+replicas: 1
+ratio: 0.5
+enabled: true
+#@schema/validation one_of=["debug", "info"]
+log_level: info
+#@schema/nullable
+version: ""
+db:
+  #@schema/desc "Host name,\nor IP address"
+  host: localhost
+  ports:
+  - name: ""
+    port: 0
+tags:
+- ""
+#@schema/validation one_of=[1, 2]
+#@schema/nullable
+level: 1
+#@schema/type any=True
+extra: {}
+#@schema/allow-extra-properties
+labels:
+  app.kubernetes.io/name: ""
+`
+		expected := `// Types of data values, generated by ytt
+
+/** Configuration of the app */
+export interface DataValues {
+  /** Name of the app */
+  name: string;
+  replicas: number;
+  ratio: number;
+  enabled: boolean;
+  log_level: "debug" | "info";
+  version: string | null;
+  db: DataValuesDb;
+  tags: string[];
+  level: 1 | 2 | null;
+  extra: any;
+  labels: DataValuesLabels;
+}
+
+export interface DataValuesDb {
+  /**
+   * Host name,
+   * or IP address
+   */
+  host: string;
+  ports: DataValuesDbPortsItem[];
+}
+
+export interface DataValuesDbPortsItem {
+  name: string;
+  port: number;
+}
+
+export interface DataValuesLabels {
+  "app.kubernetes.io/name": string;
+  [key: string]: any;
+}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, "data-values-schema.ts", out.Files[0].RelativePath())
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+	t.Run("declaring named maps once, under their schema name", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"ts"}
+
+		schemaYAML := `#@data/values-schema
+---
+- #@schema/schema-name "Port"
+  port: 0
+`
+		expected := `// Types of data values, generated by ytt
+
+export type DataValues = Port[];
+
+export interface Port {
+  port: number;
+}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+	t.Run("declaring tuples by the types of their items", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"ts"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/tuple
+endpoint:
+- localhost
+- 8080
+`
+		expected := `// Types of data values, generated by ytt
+
+export interface DataValues {
+  endpoint: [string, number];
+}
+`
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		out := opts.RunWithFiles(cmdtpl.Input{Files: filesToProcess}, ui.NewTTY(false))
+		require.NoError(t, out.Err)
+
+		require.Len(t, out.Files, 1)
+		require.Equal(t, expected, string(out.Files[0].Bytes()))
+	})
+}
+
+func TestSchemaInspect_errors(t *testing.T) {
+	t.Run("when --output is anything other than 'openapi-v3', 'json-schema' or 'ts'", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := "Data values schema export only supported in OpenAPI v3, JSON Schema or TypeScript format; specify format with --output=openapi-v3, --output=json-schema or --output=ts flag"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+
+	t.Run("when --output is set to 'openapi-v3' but not inspecting schema", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = false
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := "Output type currently only supported for data values schema (i.e. include --data-values-schema-inspect)"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when --output is set to both 'openapi-v3' and 'json-schema'", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3", "json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := "Expected at most one schema type in output type, but found: openapi-v3, json-schema"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a data value to be declared as an OpenAPI parameter is not a scalar", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"openapi-v3"}
+		opts.OpenAPIFlags.ParametersIn = "query"
+
+		schemaYAML := `#@data/values-schema
+---
+limit: 20
+filter:
+  name: ""
+`
+		expectedErr := "Expected data value 'filter' to be a scalar, to be declared as an OpenAPI parameter, but was map"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a JSON Schema vocabulary is given without whether it is required", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Vocabulary = []string{"https://example.com/vocab/units"}
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := "Expected vocabulary 'https://example.com/vocab/units' to be in format URI=true or URI=false"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when JSON Schema draft is unknown", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "2000-01"
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := "Unknown JSON Schema draft '2000-01' (supported drafts: 2020-12, draft-07, draft-04)"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when the interpretation of nulls is unknown", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Nulls = "loose"
+
+		schemaYAML := `#@data/values-schema
+---
+foo: doesn't matter
+`
+		expectedErr := "Unknown interpretation of nulls 'loose' (supported: lenient, strict)"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a schema name is given to maps that differ", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/schema-name "Endpoint"
+api:
+  host: ""
+#@schema/schema-name "Endpoint"
+metrics:
+  port: 0
+`
+		expectedErr := "Schema name 'Endpoint' is given to maps that differ (a name can only identify one kind of map)"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a schema name is given an unknown keyword argument", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/schema-name "Endpoint", uri="https://example.com/schemas/endpoint.json"
+api:
+  host: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+syntax error in @schema/schema-name annotation
+schema.yml:
+    |
+  3 | #@schema/schema-name "Endpoint", uri="https://example.com/schemas/endpoint.json"
+  4 | api:
+    |
+
+    = found: unknown keyword argument 'uri' in @schema/schema-name (by schema.yml:3)
+    = expected: a name and, optionally, id= (a URI)
+    = hint: Supported kwargs are 'id'
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a value described via @schema/enum-descriptions is not allowed", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info"]
+#@schema/enum-descriptions {"trace": "Logs even more"}
+log_level: info
+`
+		expectedErr := `
+Invalid schema
+==============
+
+value described in @schema/enum-descriptions is not allowed
+schema.yml:
+    |
+  4 | #@schema/enum-descriptions {"trace": "Logs even more"}
+  5 | log_level: info
+    |
+
+    = found: trace
+    = expected: one of the values allowed via one_of= (in @schema/validation)
+    = hint: only the values of an (unconditional) one_of= can be described.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a validation allows values via one_of= more than once", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation one_of=["debug", "info"], one_of=["warn"]
+log_level: info
+`
+		expectedErr := `keyword argument one_of repeated
+    schema.yml:3 | #@schema/validation one_of=["debug", "info"], one_of=["warn"]`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a format is unknown (and custom formats are not allowed)", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "date-tme"
+expires: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+unknown format in @schema/format
+schema.yml:
+    |
+  3 | #@schema/format "date-tme"
+  4 | expires: ""
+    |
+
+    = found: date-tme
+    = expected: one of: date-time, date, time, duration, email, idn-email, hostname, idn-hostname, ipv4, ipv6, uri, uri-reference, iri, iri-reference, uri-template, uuid, json-pointer, relative-json-pointer, regex, byte, binary, password
+    = hint: to give a format of your own, allow custom formats (i.e. --allow-custom-formats).
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when an array is required to contain some item, in draft-07", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "draft-07"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/validation contains="http", min_contains=1
+protocols: [""]
+`
+		expectedErr := `
+Invalid schema
+==============
+
+contains (via @schema/validation) not expressed by JSON Schema draft-07
+schema.yml:
+    |
+  4 | protocols: [""]
+    |
+
+    = found: contains
+    = expected: a keyword of draft-07
+    = hint: to require arrays to contain some items, target JSON Schema 2020-12 (i.e. --json-schema-draft=2020-12).
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when bundling into a single file a schema that refers to an external one, without fetching it", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.BundleToSingleFile = true
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/external-ref "https://example.com/requests.json"
+requests:
+  cpu: 100m
+`
+		expectedErr := "Bundling data values schema into a single file: Fetching external schema 'https://example.com/requests.json': fetching external schemas is not enabled (see --json-schema-fetch-external-refs)"
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a value is deprecated, in draft-07", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "draft-07"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/deprecated "use 'hosts' instead"
+host: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+deprecation (via @schema/deprecated) not expressed by JSON Schema draft-07
+schema.yml:
+    |
+  4 | host: ""
+    |
+
+    = found: deprecated
+    = expected: a keyword of draft-07
+    = hint: to mark values as deprecated, target JSON Schema 2020-12 (i.e. --json-schema-draft=2020-12).
+    = hint: to export the schema without it (as draft-07 validators would ignore it), set --json-schema-drop-unsupported.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a format is defined since draft-07, in draft-07", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "draft-07"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/format "uuid"
+id: ""
+`
+		expectedErr := `
+Invalid schema
+==============
+
+format in @schema/format not defined by JSON Schema draft-07
+schema.yml:
+    |
+  3 | #@schema/format "uuid"
+  4 | id: ""
+    |
+
+    = found: uuid
+    = expected: a format of draft-07
+    = hint: to give that format, target JSON Schema 2020-12 (i.e. --json-schema-draft=2020-12).
+    = hint: to export the schema without it (as draft-07 validators would ignore it), set --json-schema-drop-unsupported.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when keys are required conditionally, in draft-04", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "draft-04"
+
+		schemaYAML := `#@data/values-schema
+---
+#@schema/when "mode", "advanced", require=["threads"]
+config:
+  mode: basic
+  threads: 0
+`
+		expectedErr := `
+Invalid schema
+==============
+
+if (via @schema/when) not expressed by JSON Schema draft-04
+schema.yml:
+    |
+  4 | config:
+    |
+
+    = found: if
+    = expected: a keyword of draft-04
+    = hint: to give 'if', target JSON Schema 2020-12 (i.e. --json-schema-draft=2020-12).
+    = hint: to export the schema without it (as draft-04 validators would ignore it), set --json-schema-drop-unsupported.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when the items of an array are told apart by a discriminator, in draft-04", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "draft-04"
+
+		schemaYAML := `#@data/values-schema
+---
+listeners:
+#@schema/discriminator "protocol"
+- protocol: https
+  port: 443
+`
+		expectedErr := `
+Invalid schema
+==============
+
+if (via @schema/discriminator) not expressed by JSON Schema draft-04
+schema.yml:
+    |
+  3 | listeners:
+    |
+
+    = found: if
+    = expected: a keyword of draft-04
+    = hint: to give 'if', target JSON Schema 2020-12 (i.e. --json-schema-draft=2020-12).
+    = hint: to export the schema without it (as draft-04 validators would ignore it), set --json-schema-drop-unsupported.
+`
+
+		filesToProcess := files.NewSortedFiles([]*files.File{
+			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+		})
+
+		assertFails(t, filesToProcess, expectedErr, opts)
+	})
+	t.Run("when a keyword is defined since draft-04, in draft-04", func(t *testing.T) {
+		tests := []struct {
+			annotation  string
+			value       string
+			expectedErr string
+		}{
+			{`#@schema/key-pattern "^[a-z]+$"`, "labels:\n  app: web", "propertyNames (via @schema/key-pattern) not expressed by JSON Schema draft-04"},
+			{`#@schema/comment "keep in sync with the chart"`, `name: ""`, "$comment (via @schema/comment) not expressed by JSON Schema draft-04"},
+			{`#@schema/examples ("web", "web")`, `name: ""`, "examples (via @schema/examples) not expressed by JSON Schema draft-04"},
+			{`#@schema/read-only`, `name: ""`, "readOnly (via @schema/read-only) not expressed by JSON Schema draft-04"},
+			{`#@schema/write-only`, `token: ""`, "writeOnly (via @schema/write-only) not expressed by JSON Schema draft-04"},
+			{`#@schema/content-encoding "base64"`, `cert: ""`, "contentEncoding (via @schema/content-encoding) not expressed by JSON Schema draft-04"},
+			{`#@schema/content-media-type "application/json"`, `config: ""`, "contentMediaType (via @schema/content-media-type) not expressed by JSON Schema draft-04"},
+		}
+		for _, test := range tests {
+			t.Run(test.annotation, func(t *testing.T) {
+				opts := cmdtpl.NewOptions()
+				opts.DataValuesFlags.InspectSchema = true
+				opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+				opts.JSONSchemaFlags.Draft = "draft-04"
+
+				schemaYAML := "#@data/values-schema\n---\n" + test.annotation + "\n" + test.value + "\n"
+				filesToProcess := files.NewSortedFiles([]*files.File{
+					files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
+				})
+
+				assertFails(t, filesToProcess, test.expectedErr, opts)
+			})
+		}
+	})
+	t.Run("when defaults are given as examples, in draft-04", func(t *testing.T) {
+		opts := cmdtpl.NewOptions()
+		opts.DataValuesFlags.InspectSchema = true
+		opts.RegularFilesSourceOpts.OutputType.Types = []string{"json-schema"}
+		opts.JSONSchemaFlags.Draft = "draft-04"
+		opts.JSONSchemaFlags.ExamplesFromDefaults = true
+
+		schemaYAML := `#@data/values-schema
+---
+name: web
+`
+		expectedErr := "Unable to give defaults as examples in JSON Schema draft-04, which has no 'examples' keyword (to export the schema without them, set --json-schema-drop-unsupported)"
 
 		filesToProcess := files.NewSortedFiles([]*files.File{
 			files.MustNewFileFromSource(files.NewBytesSource("schema.yml", []byte(schemaYAML))),
@@ -44,6 +44,7 @@ Docs for data values: https://carvel.dev/ytt/docs/latest/ytt-data-values/`
 	cmd.AddCommand(NewVersionCmd(NewVersionOptions()))
 	cmd.AddCommand(NewCmd(cmdtpl.NewOptions())) // for backwards compat
 	cmd.AddCommand(NewFmtCmd(NewFmtOptions()))
+	cmd.AddCommand(NewSchemaCmd())
 	cmd.AddCommand(NewWebsiteCmd(NewWebsiteOptions()))
 
 	// Reconfigure Commands
@@ -25,6 +25,7 @@ const (
 	TypeYAML
 	TypeText
 	TypeStarlark
+	TypeJSON // only marks output files (e.g. an exported schema, rendered as JSON)
 )
 
 type File struct {
@@ -5,9 +5,15 @@ package schema
 
 import (
 	"fmt"
+	"math"
+	"mime"
+	"net/url"
+	"regexp"
 	"strings"
+	"time"
 
 	"carvel.dev/ytt/pkg/filepos"
+	"carvel.dev/ytt/pkg/orderedmap"
 	"carvel.dev/ytt/pkg/template"
 	"carvel.dev/ytt/pkg/template/core"
 	"carvel.dev/ytt/pkg/validations"
@@ -22,10 +28,37 @@ const (
 	AnnotationDefault      template.AnnotationName = "schema/default"
 	AnnotationDescription  template.AnnotationName = "schema/desc"
 	AnnotationTitle        template.AnnotationName = "schema/title"
+	AnnotationComment      template.AnnotationName = "schema/comment"
 	AnnotationExamples     template.AnnotationName = "schema/examples"
 	AnnotationDeprecated   template.AnnotationName = "schema/deprecated"
 	TypeAnnotationKwargAny string                  = "any"
 	AnnotationValidation   template.AnnotationName = "schema/validation"
+
+	AnnotationAllowExtraProperties  template.AnnotationName = "schema/allow-extra-properties"
+	AnnotationFormat                template.AnnotationName = "schema/format"
+	AnnotationReadOnly              template.AnnotationName = "schema/read-only"
+	AnnotationWriteOnly             template.AnnotationName = "schema/write-only"
+	AnnotationKeyPattern            template.AnnotationName = "schema/key-pattern"
+	AnnotationSchemaName            template.AnnotationName = "schema/schema-name"
+	AnnotationExternalRef           template.AnnotationName = "schema/external-ref"
+	AnnotationContentEncoding       template.AnnotationName = "schema/content-encoding"
+	AnnotationContentMediaType      template.AnnotationName = "schema/content-media-type"
+	AnnotationScalarOrArray         template.AnnotationName = "schema/scalar-or-array"
+	AnnotationNullableItems         template.AnnotationName = "schema/nullable-items"
+	AnnotationUnit                  template.AnnotationName = "schema/unit"
+	AnnotationDiscriminator         template.AnnotationName = "schema/discriminator"
+	AnnotationNoAdditionalPropsKey  template.AnnotationName = "schema/no-additional-properties-key"
+	AnnotationUnwrap                template.AnnotationName = "schema/unwrap"
+	AnnotationTuple                 template.AnnotationName = "schema/tuple"
+	AnnotationDependentRequired     template.AnnotationName = "schema/dependent-required"
+	AnnotationWhen                  template.AnnotationName = "schema/when"
+	AnnotationPatternProperty       template.AnnotationName = "schema/pattern-property"
+	AnnotationExtension             template.AnnotationName = "schema/extension"
+	AnnotationEnumDescriptions      template.AnnotationName = "schema/enum-descriptions"
+	WhenAnnotationKwargRequire      string                  = "require"
+	SchemaNameAnnotationKwargID     string                  = "id"
+	DeprecatedAnnotationKwargSince  string                  = "since"
+	DeprecatedAnnotationKwargRemove string                  = "remove"
 )
 
 type Annotation interface {
@@ -34,9 +67,10 @@ type Annotation interface {
 }
 
 type TypeAnnotation struct {
-	any  bool
-	node yamlmeta.Node
-	pos  *filepos.Position
+	any      bool
+	typeName string // the JSON Schema type of a number (e.g. "integer"), overriding the one inferred
+	node     yamlmeta.Node
+	pos      *filepos.Position
 }
 
 type NullableAnnotation struct {
@@ -62,12 +96,63 @@ type TitleAnnotation struct {
 	pos   *filepos.Position
 }
 
+// CommentAnnotation notes something about a node for maintainers of the schema (rather than its users)
+type CommentAnnotation struct {
+	comment string
+	pos     *filepos.Position
+}
+
 // DeprecatedAnnotation is a wrapper for a value provided via @schema/deprecated annotation
 type DeprecatedAnnotation struct {
 	notice string
+	since  string // when not empty, the date (RFC 3339) since which the value is deprecated
+	remove string // when not empty, the date (RFC 3339) on which the value is to be removed
 	pos    *filepos.Position
 }
 
+// ReadOnlyAnnotation marks a node as populated by the system that consumes the values (rather than by the user); on a
+// document, marks its values as a whole (e.g. a document entirely computed by the system).
+type ReadOnlyAnnotation struct {
+	pos *filepos.Position
+}
+
+// NoAdditionalPropsKeyAnnotation marks a map as exported without saying whether it permits extra keys (i.e. without
+// "additionalProperties"), for consumers that reject that keyword
+type NoAdditionalPropsKeyAnnotation struct {
+	pos *filepos.Position
+}
+
+// UnwrapAnnotation marks a map of a single key as exported as the value of that key, in place of the map (e.g. for a
+// wrapper that only groups values in YAML)
+type UnwrapAnnotation struct {
+	pos *filepos.Position
+}
+
+// TupleAnnotation types an array as a tuple: of exactly the items it lists, each of the type of the item at that
+// position (e.g. a pair of a host and a port)
+type TupleAnnotation struct {
+	node yamlmeta.Node
+	pos  *filepos.Position
+}
+
+// ScalarOrArrayAnnotation marks an array as also given (when exported as JSON Schema) by a single item, in place of a
+// list of them (e.g. `hosts: a.com` rather than `hosts: [a.com]`)
+type ScalarOrArrayAnnotation struct {
+	pos *filepos.Position
+}
+
+// NullableItemsAnnotation marks the items of an array as nullable (as though the item were annotated with
+// @schema/nullable), independently of whether the array itself is.
+type NullableItemsAnnotation struct {
+	pos *filepos.Position
+}
+
+// WriteOnlyAnnotation marks a node as given to the system that consumes the values, but never echoed back (e.g. a
+// secret)
+type WriteOnlyAnnotation struct {
+	pos *filepos.Position
+}
+
 // ExampleAnnotation provides the Examples of a node
 type ExampleAnnotation struct {
 	examples []Example
@@ -80,23 +165,175 @@ type ValidationAnnotation struct {
 	pos        *filepos.Position
 }
 
+// FormatAnnotation names the format of a string (e.g. "date-time") or the width of an integer (e.g. "int64"), given via
+// @schema/format annotation
+type FormatAnnotation struct {
+	format string
+	pos    *filepos.Position
+}
+
+// UnitAnnotation names the unit a number is given in (e.g. "seconds"), given via @schema/unit annotation
+type UnitAnnotation struct {
+	unit string
+	pos  *filepos.Position
+}
+
+// ContentEncodingAnnotation names the encoding of the content of a string, given via @schema/content-encoding
+// annotation (e.g. "base64")
+type ContentEncodingAnnotation struct {
+	encoding string
+	pos      *filepos.Position
+}
+
+// ContentMediaTypeAnnotation names the media type of the content of a string, given via @schema/content-media-type
+// annotation (e.g. "application/json")
+type ContentMediaTypeAnnotation struct {
+	mediaType string
+	pos       *filepos.Position
+}
+
+// DiscriminatorAnnotation names the key of a map whose value tells apart the shapes the map takes (i.e. of a tagged
+// union), given via @schema/discriminator annotation (e.g. "kind")
+//
+// JSON Schema has no discriminator: the items of an array of such maps are exported with an "if"/"then" requiring the
+// keys of the shape declared when tagged as it (unless the map gives its own condition, via @schema/when).
+type DiscriminatorAnnotation struct {
+	key string
+	pos *filepos.Position
+}
+
+// KeyPatternAnnotation is a wrapper for the regular expression given via @schema/key-pattern annotation: the keys of a
+// map so annotated, when exported, must match it.
+type KeyPatternAnnotation struct {
+	pattern string
+	pos     *filepos.Position
+}
+
+// SchemaNameAnnotation is a wrapper for the name (and, optionally, the URI) given via @schema/schema-name annotation: a
+// map so annotated, when exported, is defined under (and anchored at) that name, and identified by that URI.
+type SchemaNameAnnotation struct {
+	name string
+	id   string
+	pos  *filepos.Position
+}
+
+// ExternalRefAnnotation is a wrapper for the URI given via @schema/external-ref annotation: a map so annotated, when
+// exported as JSON Schema, refers to the schema published at that URI rather than being described in place.
+type ExternalRefAnnotation struct {
+	uri string
+	pos *filepos.Position
+}
+
+// DependentRequiredAnnotation is a wrapper for the dependencies between keys given via @schema/dependent-required
+// annotation: when a map so annotated (as exported) has one of those keys, it must also have the keys depending on it.
+type DependentRequiredAnnotation struct {
+	dependencies []keyDependency
+	pos          *filepos.Position
+}
+
+// keyDependency names the keys required when `key` is given.
+type keyDependency struct {
+	key      string
+	requires []string
+}
+
+// WhenAnnotation is a wrapper for the condition given via @schema/when annotation: when a key of a map so annotated (as
+// exported) has a given value, other keys are required.
+type WhenAnnotation struct {
+	condition *keyCondition
+	pos       *filepos.Position
+}
+
+// keyCondition names the keys required when `key` is given, with the value `equals`.
+type keyCondition struct {
+	key      string
+	equals   interface{}
+	requires []string
+}
+
+// AllowExtraPropertiesAnnotation is a wrapper for the type of values (if any) given via @schema/allow-extra-properties
+// annotation: a map so annotated, when exported, also permits keys beyond those declared.
+type AllowExtraPropertiesAnnotation struct {
+	valueType Type
+	pos       *filepos.Position
+}
+
+// PatternPropertyAnnotation is a wrapper for the regular expression and the type of values given via
+// @schema/pattern-property annotation: a map so annotated, when exported, also permits keys (beyond those declared)
+// matching that expression, with values of that type.
+type PatternPropertyAnnotation struct {
+	property *patternProperty
+	pos      *filepos.Position
+}
+
+// patternProperty describes the values of keys matching `pattern`.
+type patternProperty struct {
+	pattern   string
+	valueType Type
+}
+
+// EnumDescriptionsAnnotation is a wrapper for the documentation of allowed values given via @schema/enum-descriptions
+// annotation: a value so annotated, when exported as JSON Schema, is allowed one of its values (see one_of=) via a
+// "oneOf" of those values, each documented.
+type EnumDescriptionsAnnotation struct {
+	descriptions []enumDescription
+	pos          *filepos.Position
+}
+
+// enumDescription documents one of the values allowed via one_of= (in @schema/validation).
+type enumDescription struct {
+	value       interface{}
+	title       string
+	description string
+}
+
+// ExtensionAnnotation is a wrapper for the vendor extensions given via @schema/extension annotation: each is included,
+// as given, in the exported schema of the annotated node.
+type ExtensionAnnotation struct {
+	extensions []Extension
+	pos        *filepos.Position
+}
+
 // Example contains a yaml example and its description
 type Example struct {
 	description string
 	example     interface{}
 }
 
+// Extension contains the name (starting with extensionPrefix) and the value of a vendor extension
+type Extension struct {
+	name  string
+	value interface{}
+}
+
+// extensionPrefix starts the name of every vendor extension (as required by OpenAPI).
+const extensionPrefix = "x-"
+
 // documentation holds metadata about a Type, provided via documentation annotations
 type documentation struct {
 	title             string
 	description       string
+	comment           string
 	deprecated        bool
 	deprecationNotice string
 	examples          []Example
+	readOnly          bool
+	writeOnly         bool
+	extensions        []Extension
 }
 
-// NewTypeAnnotation checks the keyword argument provided via @schema/type annotation, and returns wrapper for the annotated node.
+// NewTypeAnnotation checks the keyword argument (or the name of a type) provided via @schema/type annotation, and
+// returns wrapper for the annotated node.
 func NewTypeAnnotation(ann template.NodeAnnotation, node yamlmeta.Node) (*TypeAnnotation, error) {
+	if len(ann.Args) > 0 {
+		if _, isTypeName := ann.Args[0].(starlark.String); isTypeName {
+			typeName, err := stringArgOf(ann, AnnotationType, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return &TypeAnnotation{typeName: typeName, node: node, pos: ann.Position}, nil
+		}
+	}
 	if len(ann.Kwargs) == 0 {
 		return nil, schemaAssertionError{
 			annPositions: []*filepos.Position{ann.Position},
@@ -159,27 +396,57 @@ func NewNullableAnnotation(ann template.NodeAnnotation, node yamlmeta.Node) (*Nu
 	return &NullableAnnotation{node, ann.Position}, nil
 }
 
-// NewDeprecatedAnnotation validates the value from the AnnotationDeprecated, and returns the value
+// deprecationExtensions lists the vendor extensions giving the dates of a deprecation (see @schema/deprecated), in the
+// order of the keyword arguments giving them.
+var deprecationExtensions = []struct {
+	kwarg string
+	name  string
+}{
+	{DeprecatedAnnotationKwargSince, "x-deprecation-date"},
+	{DeprecatedAnnotationKwargRemove, "x-removal-date"},
+}
+
+// NewDeprecatedAnnotation validates the value (an optional deprecation notice) from the AnnotationDeprecated, and
+// returns the value
 func NewDeprecatedAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*DeprecatedAnnotation, error) {
-	if len(ann.Kwargs) != 0 {
-		return nil, schemaAssertionError{
-			annPositions: []*filepos.Position{ann.Position},
-			position:     pos,
-			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationDeprecated),
-			expected:     "string",
-			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationDeprecated, ann.Position.AsCompactString()),
-			hints:        []string{"this annotation only accepts one argument: a string."},
+	deprecatedAnn := &DeprecatedAnnotation{pos: ann.Position}
+	for _, kwarg := range ann.Kwargs {
+		argName, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return nil, err
+		}
+		if argName != DeprecatedAnnotationKwargSince && argName != DeprecatedAnnotationKwargRemove {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationDeprecated),
+				expected:     fmt.Sprintf("a string and, optionally, %s= and %s= (dates)", DeprecatedAnnotationKwargSince, DeprecatedAnnotationKwargRemove),
+				found:        fmt.Sprintf("unknown keyword argument '%s' in @%v (by %v)", argName, AnnotationDeprecated, ann.Position.AsCompactString()),
+				hints:        []string{fmt.Sprintf("Supported kwargs are '%v', '%v'", DeprecatedAnnotationKwargSince, DeprecatedAnnotationKwargRemove)},
+			}
+		}
+		date, err := core.NewStarlarkValue(kwarg[1]).AsString()
+		if err == nil && !isRFC3339Date(date) {
+			err = fmt.Errorf("not a date")
+		}
+		if err != nil {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("invalid date in @%v annotation", AnnotationDeprecated),
+				expected:     "a date (RFC 3339), e.g. \"2024-06-01\" or \"2024-06-01T00:00:00Z\"",
+				found:        fmt.Sprintf("%v=%v (by %v)", argName, kwarg[1], ann.Position.AsCompactString()),
+			}
+		}
+		if argName == DeprecatedAnnotationKwargSince {
+			deprecatedAnn.since = date
+		} else {
+			deprecatedAnn.remove = date
 		}
 	}
 	switch numArgs := len(ann.Args); {
 	case numArgs == 0:
-		return nil, schemaAssertionError{
-			annPositions: []*filepos.Position{ann.Position},
-			position:     pos,
-			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationDeprecated),
-			expected:     "string",
-			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationDeprecated, ann.Position.AsCompactString()),
-		}
+		return deprecatedAnn, nil
 	case numArgs > 1:
 		return nil, schemaAssertionError{
 			annPositions: []*filepos.Position{ann.Position},
@@ -200,7 +467,119 @@ func NewDeprecatedAnnotation(ann template.NodeAnnotation, pos *filepos.Position)
 			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationDeprecated, ann.Position.AsCompactString()),
 		}
 	}
-	return &DeprecatedAnnotation{strVal, ann.Position}, nil
+	deprecatedAnn.notice = strVal
+	return deprecatedAnn, nil
+}
+
+// isRFC3339Date indicates whether `value` is a date (i.e. a full-date) or a date-time, as defined by RFC 3339.
+func isRFC3339Date(value string) bool {
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// extensions gives the dates of this deprecation as vendor extensions (see deprecationExtensions).
+func (d *DeprecatedAnnotation) extensions() []Extension {
+	var extensions []Extension
+	for _, extension := range deprecationExtensions {
+		date := d.since
+		if extension.kwarg == DeprecatedAnnotationKwargRemove {
+			date = d.remove
+		}
+		if date != "" {
+			extensions = append(extensions, Extension{extension.name, date})
+		}
+	}
+	return extensions
+}
+
+// NewNoAdditionalPropsKeyAnnotation checks that there are no arguments, and returns wrapper for the annotated node.
+func NewNoAdditionalPropsKeyAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*NoAdditionalPropsKeyAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationNoAdditionalPropsKey, pos); err != nil {
+		return nil, err
+	}
+	return &NoAdditionalPropsKeyAnnotation{ann.Position}, nil
+}
+
+// NewUnwrapAnnotation checks that there are no arguments, and returns wrapper for the annotated node.
+func NewUnwrapAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*UnwrapAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationUnwrap, pos); err != nil {
+		return nil, err
+	}
+	return &UnwrapAnnotation{ann.Position}, nil
+}
+
+// NewTupleAnnotation checks that there are no arguments and that `node` holds an array of some items, and returns
+// wrapper for the annotated node.
+func NewTupleAnnotation(ann template.NodeAnnotation, node yamlmeta.Node) (*TupleAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationTuple, node.GetPosition()); err != nil {
+		return nil, err
+	}
+	array, isArray := node.GetValues()[0].(*yamlmeta.Array)
+	if !isArray || len(array.Items) == 0 {
+		found := yamlmeta.TypeName(node.GetValues()[0])
+		if isArray {
+			found = "an empty array"
+		}
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on %s", AnnotationTuple, found),
+			expected:     "an array of some items",
+			found:        found,
+			hints:        []string{"in a tuple, each item gives the type of the item at that position."},
+		}
+	}
+	return &TupleAnnotation{node, ann.Position}, nil
+}
+
+// NewScalarOrArrayAnnotation checks that there are no arguments, and returns wrapper for the annotated node.
+func NewScalarOrArrayAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ScalarOrArrayAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationScalarOrArray, pos); err != nil {
+		return nil, err
+	}
+	return &ScalarOrArrayAnnotation{ann.Position}, nil
+}
+
+// NewNullableItemsAnnotation checks that there are no arguments, and returns wrapper for the annotated node.
+func NewNullableItemsAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*NullableItemsAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationNullableItems, pos); err != nil {
+		return nil, err
+	}
+	return &NullableItemsAnnotation{ann.Position}, nil
+}
+
+// NewReadOnlyAnnotation checks that there are no arguments, and returns wrapper for the annotated node.
+func NewReadOnlyAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ReadOnlyAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationReadOnly, pos); err != nil {
+		return nil, err
+	}
+	return &ReadOnlyAnnotation{ann.Position}, nil
+}
+
+// NewWriteOnlyAnnotation checks that there are no arguments, and returns wrapper for the annotated node.
+func NewWriteOnlyAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*WriteOnlyAnnotation, error) {
+	if err := checkNoArgs(ann, AnnotationWriteOnly, pos); err != nil {
+		return nil, err
+	}
+	return &WriteOnlyAnnotation{ann.Position}, nil
+}
+
+// checkNoArgs reports an error if `ann` (an annotation that merely marks a node) is given any arguments.
+func checkNoArgs(ann template.NodeAnnotation, name template.AnnotationName, pos *filepos.Position) error {
+	if len(ann.Args) != 0 || len(ann.Kwargs) != 0 {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "no arguments",
+			found:        fmt.Sprintf("arguments in @%v (by %v)", name, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation accepts no arguments."},
+		}
+	}
+	return nil
 }
 
 // NewDefaultAnnotation checks the argument provided via @schema/default annotation, and returns wrapper for that value.
@@ -285,7 +664,17 @@ func NewDescriptionAnnotation(ann template.NodeAnnotation, pos *filepos.Position
 			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationDescription, ann.Position.AsCompactString()),
 		}
 	}
-	return &DescriptionAnnotation{strVal, ann.Position}, nil
+	return &DescriptionAnnotation{normalizeDescription(strVal), ann.Position}, nil
+}
+
+// normalizeDescription keeps the intentional line breaks in `desc` (as "\n"), dropping only whitespace that trails
+// each line and the description as a whole.
+func normalizeDescription(desc string) string {
+	lines := strings.Split(strings.ReplaceAll(desc, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
 }
 
 // NewTitleAnnotation validates the value from the AnnotationTitle, and returns the value
@@ -332,96 +721,788 @@ func NewTitleAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*Ti
 	return &TitleAnnotation{strVal, ann.Position}, nil
 }
 
-// NewExampleAnnotation validates the value(s) from the AnnotationExamples, and returns the value(s)
-func NewExampleAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ExampleAnnotation, error) {
+// NewCommentAnnotation validates the value from the AnnotationComment, and returns the value
+func NewCommentAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*CommentAnnotation, error) {
 	if len(ann.Kwargs) != 0 {
 		return nil, schemaAssertionError{
 			annPositions: []*filepos.Position{ann.Position},
 			position:     pos,
-			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-			expected:     "2-tuple containing description (string) and example value (of expected type)",
-			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationComment),
+			expected:     "string",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationComment, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation only accepts one argument: a string."},
 		}
 	}
-	if len(ann.Args) == 0 {
+	switch numArgs := len(ann.Args); {
+	case numArgs == 0:
 		return nil, schemaAssertionError{
 			annPositions: []*filepos.Position{ann.Position},
 			position:     pos,
-			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-			expected:     "2-tuple containing description (string) and example value (of expected type)",
-			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
-		}
-	}
-
-	var examples []Example
-	for _, ex := range ann.Args {
-		exampleTuple, ok := ex.(starlark.Tuple)
-		if !ok {
-			return nil, schemaAssertionError{
-				annPositions: []*filepos.Position{ann.Position},
-				position:     pos,
-				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-				expected:     "2-tuple containing description (string) and example value (of expected type)",
-				found:        fmt.Sprintf("%v for @%v (at %v)", ex.Type(), AnnotationExamples, ann.Position.AsCompactString()),
-			}
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationComment),
+			expected:     "string",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationComment, ann.Position.AsCompactString()),
 		}
-		switch {
-		case len(exampleTuple) == 0:
-			return nil, schemaAssertionError{
-				annPositions: []*filepos.Position{ann.Position},
-				position:     pos,
-				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-				expected:     "2-tuple containing description (string) and example value (of expected type)",
-				found:        fmt.Sprintf("empty tuple in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
-			}
-		case len(exampleTuple) == 1:
-			return nil, schemaAssertionError{
-				annPositions: []*filepos.Position{ann.Position},
-				position:     pos,
-				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-				expected:     "2-tuple containing description (string) and example value (of expected type)",
-				found:        fmt.Sprintf("empty tuple in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
-			}
-		case len(exampleTuple) > 2:
-			return nil, schemaAssertionError{
-				annPositions: []*filepos.Position{ann.Position},
-				position:     pos,
-				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-				expected:     "2-tuple containing description (string) and example value (of expected type)",
-				found:        fmt.Sprintf("%v-tuple argument in @%v (by %v)", len(exampleTuple), AnnotationExamples, ann.Position.AsCompactString()),
-			}
-		default:
-			description, err := core.NewStarlarkValue(exampleTuple[0]).AsString()
-			if err != nil {
-				return nil, schemaAssertionError{
-					annPositions: []*filepos.Position{ann.Position},
-					position:     pos,
-					description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
-					expected:     "2-tuple containing description (string) and example value (of expected type)",
-					found:        fmt.Sprintf("%v value for @%v (at %v)", exampleTuple[0].Type(), AnnotationExamples, ann.Position.AsCompactString()),
-				}
-			}
-			exampleVal, err := core.NewStarlarkValue(exampleTuple[1]).AsGoValue()
-			if err != nil {
-				panic(err)
-			}
-			examples = append(examples, Example{description, yamlmeta.NewASTFromInterfaceWithPosition(exampleVal, pos)})
+	case numArgs > 1:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationComment),
+			expected:     "string",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", numArgs, AnnotationComment, ann.Position.AsCompactString()),
 		}
 	}
-	return &ExampleAnnotation{examples, ann.Position}, nil
-}
 
-// NewValidationAnnotation checks the values provided via @schema/validation annotation, and returns wrapper for the validation defined
-func NewValidationAnnotation(ann template.NodeAnnotation) (*ValidationAnnotation, error) {
-	validation, err := validations.NewValidationFromAnn(ann)
+	strVal, err := core.NewStarlarkValue(ann.Args[0]).AsString()
 	if err != nil {
-		return nil, fmt.Errorf("Invalid @%s annotation - %s", AnnotationValidation, err.Error())
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationComment),
+			expected:     "string",
+			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationComment, ann.Position.AsCompactString()),
+		}
 	}
-
-	return &ValidationAnnotation{validation, ann.Position}, nil
+	return &CommentAnnotation{strVal, ann.Position}, nil
 }
 
-// NewTypeFromAnn returns type information given by annotation.
+// NewFormatAnnotation validates the value from the AnnotationFormat, and returns the value
+//
+// Whether the format is one known to JSON Schema (or OpenAPI) is checked on export; see CheckFormats().
+func NewFormatAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*FormatAnnotation, error) {
+	if len(ann.Kwargs) != 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationFormat),
+			expected:     "string",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationFormat, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation only accepts one argument: a string."},
+		}
+	}
+	switch numArgs := len(ann.Args); {
+	case numArgs == 0:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationFormat),
+			expected:     "string",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationFormat, ann.Position.AsCompactString()),
+		}
+	case numArgs > 1:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationFormat),
+			expected:     "string",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", numArgs, AnnotationFormat, ann.Position.AsCompactString()),
+		}
+	}
+
+	strVal, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationFormat),
+			expected:     "string",
+			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationFormat, ann.Position.AsCompactString()),
+		}
+	}
+	return &FormatAnnotation{strVal, ann.Position}, nil
+}
+
+// NewUnitAnnotation checks the argument provided via @schema/unit annotation, and returns wrapper for it.
+func NewUnitAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*UnitAnnotation, error) {
+	unit, err := stringArgOf(ann, AnnotationUnit, pos)
+	if err != nil {
+		return nil, err
+	}
+	return &UnitAnnotation{unit, ann.Position}, nil
+}
+
+// NewDiscriminatorAnnotation checks the argument provided via @schema/discriminator annotation, and returns wrapper
+// for it.
+func NewDiscriminatorAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*DiscriminatorAnnotation, error) {
+	key, err := stringArgOf(ann, AnnotationDiscriminator, pos)
+	if err != nil {
+		return nil, err
+	}
+	return &DiscriminatorAnnotation{key, ann.Position}, nil
+}
+
+// NewContentEncodingAnnotation checks the argument provided via @schema/content-encoding annotation, and returns
+// wrapper for it.
+func NewContentEncodingAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ContentEncodingAnnotation, error) {
+	encoding, err := stringArgOf(ann, AnnotationContentEncoding, pos)
+	if err != nil {
+		return nil, err
+	}
+	return &ContentEncodingAnnotation{encoding, ann.Position}, nil
+}
+
+// NewContentMediaTypeAnnotation checks the argument provided via @schema/content-media-type annotation is a media
+// type, and returns wrapper for it.
+func NewContentMediaTypeAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ContentMediaTypeAnnotation, error) {
+	mediaType, err := stringArgOf(ann, AnnotationContentMediaType, pos)
+	if err != nil {
+		return nil, err
+	}
+	// (a media type is a type and subtype, e.g. "application/json"; parameters are allowed)
+	if _, _, err := mime.ParseMediaType(mediaType); err != nil || !strings.Contains(mediaType, "/") {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("invalid media type in @%v annotation", AnnotationContentMediaType),
+			expected:     "a media type (e.g. application/json)",
+			found:        fmt.Sprintf("%q (by %v)", mediaType, ann.Position.AsCompactString()),
+		}
+	}
+	return &ContentMediaTypeAnnotation{mediaType, ann.Position}, nil
+}
+
+// stringArgOf checks that `ann` (i.e. the annotation named `name`) has a single argument, a string, and returns it.
+func stringArgOf(ann template.NodeAnnotation, name template.AnnotationName, pos *filepos.Position) (string, error) {
+	if len(ann.Kwargs) != 0 {
+		return "", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "string",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", name, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation only accepts one argument: a string."},
+		}
+	}
+	switch numArgs := len(ann.Args); {
+	case numArgs == 0:
+		return "", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "string",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", name, ann.Position.AsCompactString()),
+		}
+	case numArgs > 1:
+		return "", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "string",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", numArgs, name, ann.Position.AsCompactString()),
+		}
+	}
+
+	strVal, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return "", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", name),
+			expected:     "string",
+			found:        fmt.Sprintf("Non-string value in @%v (by %v)", name, ann.Position.AsCompactString()),
+		}
+	}
+	return strVal, nil
+}
+
+// NewKeyPatternAnnotation checks the argument provided via @schema/key-pattern annotation is a valid regular
+// expression, and returns wrapper for it.
+func NewKeyPatternAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*KeyPatternAnnotation, error) {
+	if len(ann.Kwargs) != 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationKeyPattern),
+			expected:     "string",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationKeyPattern, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation only accepts one argument: a string."},
+		}
+	}
+	switch numArgs := len(ann.Args); {
+	case numArgs == 0:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationKeyPattern),
+			expected:     "string",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationKeyPattern, ann.Position.AsCompactString()),
+		}
+	case numArgs > 1:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationKeyPattern),
+			expected:     "string",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", numArgs, AnnotationKeyPattern, ann.Position.AsCompactString()),
+		}
+	}
+
+	strVal, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationKeyPattern),
+			expected:     "string",
+			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationKeyPattern, ann.Position.AsCompactString()),
+		}
+	}
+	if _, err := regexp.Compile(strVal); err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("invalid pattern in @%v annotation", AnnotationKeyPattern),
+			expected:     "regular expression",
+			found:        fmt.Sprintf("%v (by %v)", err, ann.Position.AsCompactString()),
+		}
+	}
+	return &KeyPatternAnnotation{strVal, ann.Position}, nil
+}
+
+// NewDependentRequiredAnnotation checks the argument provided via @schema/dependent-required annotation is a dict of
+// keys to the lists of keys they require, and returns wrapper for those dependencies.
+func NewDependentRequiredAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*DependentRequiredAnnotation, error) {
+	syntaxError := func(found string) error {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationDependentRequired),
+			expected:     "dict of keys to lists of keys (e.g. {\"enabled\": [\"cert\", \"key\"]})",
+			found:        fmt.Sprintf("%s in @%v (by %v)", found, AnnotationDependentRequired, ann.Position.AsCompactString()),
+		}
+	}
+	if len(ann.Kwargs) != 0 {
+		return nil, syntaxError("keyword argument")
+	}
+	if numArgs := len(ann.Args); numArgs != 1 {
+		return nil, syntaxError(fmt.Sprintf("%v values", numArgs))
+	}
+	val, err := core.NewStarlarkValue(ann.Args[0]).AsGoValue()
+	if err != nil {
+		return nil, syntaxError(err.Error())
+	}
+	dict, ok := val.(*orderedmap.Map)
+	if !ok {
+		return nil, syntaxError(fmt.Sprintf("%T value", val))
+	}
+
+	depAnn := &DependentRequiredAnnotation{pos: ann.Position}
+	err = dict.IterateErr(func(key, requires interface{}) error {
+		keyStr, ok := key.(string)
+		if !ok {
+			return syntaxError(fmt.Sprintf("non-string key %v", key))
+		}
+		requiresList, ok := requires.([]interface{})
+		if !ok || len(requiresList) == 0 {
+			return syntaxError(fmt.Sprintf("%v as the keys required by '%s'", requires, keyStr))
+		}
+		dependency := keyDependency{key: keyStr}
+		for _, required := range requiresList {
+			requiredStr, ok := required.(string)
+			if !ok {
+				return syntaxError(fmt.Sprintf("non-string key %v required by '%s'", required, keyStr))
+			}
+			dependency.requires = append(dependency.requires, requiredStr)
+		}
+		depAnn.dependencies = append(depAnn.dependencies, dependency)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return depAnn, nil
+}
+
+// NewWhenAnnotation checks the arguments provided via @schema/when annotation (the key and value the condition is on, and
+// the keys thereby required), and returns wrapper for that condition.
+func NewWhenAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*WhenAnnotation, error) {
+	syntaxError := func(found string) error {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationWhen),
+			expected:     fmt.Sprintf("a key, its value, and the keys required then (e.g. \"mode\", \"advanced\", %s=[\"threads\"])", WhenAnnotationKwargRequire),
+			found:        fmt.Sprintf("%s in @%v (by %v)", found, AnnotationWhen, ann.Position.AsCompactString()),
+		}
+	}
+	if numArgs := len(ann.Args); numArgs != 2 {
+		return nil, syntaxError(fmt.Sprintf("%v values", numArgs))
+	}
+	key, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, syntaxError("non-string key")
+	}
+	equals, err := core.NewStarlarkValue(ann.Args[1]).AsGoValue()
+	if err != nil {
+		return nil, syntaxError(err.Error())
+	}
+	switch equals.(type) {
+	case *orderedmap.Map, []interface{}:
+		return nil, syntaxError("non-scalar value")
+	}
+
+	condition := &keyCondition{key: key, equals: equals}
+	for _, kwarg := range ann.Kwargs {
+		argName, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return nil, err
+		}
+		if argName != WhenAnnotationKwargRequire {
+			return nil, syntaxError(fmt.Sprintf("unknown keyword argument '%s'", argName))
+		}
+		val, err := core.NewStarlarkValue(kwarg[1]).AsGoValue()
+		if err != nil {
+			return nil, syntaxError(err.Error())
+		}
+		requires, ok := val.([]interface{})
+		if !ok {
+			return nil, syntaxError(fmt.Sprintf("%v as the keys required", val))
+		}
+		for _, required := range requires {
+			requiredStr, ok := required.(string)
+			if !ok {
+				return nil, syntaxError(fmt.Sprintf("non-string key %v required", required))
+			}
+			condition.requires = append(condition.requires, requiredStr)
+		}
+	}
+	if len(condition.requires) == 0 {
+		return nil, syntaxError(fmt.Sprintf("no keys required (via %s=)", WhenAnnotationKwargRequire))
+	}
+	return &WhenAnnotation{condition, ann.Position}, nil
+}
+
+// schemaNamePattern is the syntax of a name that can be used as a JSON Schema anchor (i.e. a plain name fragment).
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][-A-Za-z0-9._]*$`)
+
+// NewSchemaNameAnnotation checks the argument provided via @schema/schema-name annotation is a valid name, and returns
+// wrapper for it.
+func NewSchemaNameAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*SchemaNameAnnotation, error) {
+	var id string
+	for _, kwarg := range ann.Kwargs {
+		argName, err := core.NewStarlarkValue(kwarg[0]).AsString()
+		if err != nil {
+			return nil, err
+		}
+		if argName != SchemaNameAnnotationKwargID {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationSchemaName),
+				expected:     fmt.Sprintf("a name and, optionally, %s= (a URI)", SchemaNameAnnotationKwargID),
+				found:        fmt.Sprintf("unknown keyword argument '%s' in @%v (by %v)", argName, AnnotationSchemaName, ann.Position.AsCompactString()),
+				hints:        []string{fmt.Sprintf("Supported kwargs are '%v'", SchemaNameAnnotationKwargID)},
+			}
+		}
+		id, err = core.NewStarlarkValue(kwarg[1]).AsString()
+		if _, parseErr := url.Parse(id); err != nil || parseErr != nil || id == "" {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("invalid URI in @%v annotation", AnnotationSchemaName),
+				expected:     "a URI (e.g. https://example.com/schemas/address.json)",
+				found:        fmt.Sprintf("%v=%v (by %v)", SchemaNameAnnotationKwargID, kwarg[1], ann.Position.AsCompactString()),
+			}
+		}
+	}
+	switch numArgs := len(ann.Args); {
+	case numArgs == 0:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationSchemaName),
+			expected:     "string",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationSchemaName, ann.Position.AsCompactString()),
+		}
+	case numArgs > 1:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationSchemaName),
+			expected:     "string",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", numArgs, AnnotationSchemaName, ann.Position.AsCompactString()),
+		}
+	}
+
+	strVal, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationSchemaName),
+			expected:     "string",
+			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationSchemaName, ann.Position.AsCompactString()),
+		}
+	}
+	if !schemaNamePattern.MatchString(strVal) {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("invalid name in @%v annotation", AnnotationSchemaName),
+			expected:     fmt.Sprintf("a name matching %s", schemaNamePattern),
+			found:        fmt.Sprintf("%q (by %v)", strVal, ann.Position.AsCompactString()),
+			hints:        []string{"names start with a letter or an underscore, followed by letters, digits, '-', '.' or '_'."},
+		}
+	}
+	return &SchemaNameAnnotation{strVal, id, ann.Position}, nil
+}
+
+// NewExternalRefAnnotation checks the argument provided via @schema/external-ref annotation is a URI, and returns
+// wrapper for it.
+func NewExternalRefAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ExternalRefAnnotation, error) {
+	if len(ann.Kwargs) != 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExternalRef),
+			expected:     "string",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationExternalRef, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation only accepts one argument: a string."},
+		}
+	}
+	switch numArgs := len(ann.Args); {
+	case numArgs == 0:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExternalRef),
+			expected:     "string",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationExternalRef, ann.Position.AsCompactString()),
+		}
+	case numArgs > 1:
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExternalRef),
+			expected:     "string",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", numArgs, AnnotationExternalRef, ann.Position.AsCompactString()),
+		}
+	}
+
+	strVal, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExternalRef),
+			expected:     "string",
+			found:        fmt.Sprintf("Non-string value in @%v (by %v)", AnnotationExternalRef, ann.Position.AsCompactString()),
+		}
+	}
+	if _, err := url.Parse(strVal); err != nil || strVal == "" {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("invalid URI in @%v annotation", AnnotationExternalRef),
+			expected:     "a URI (e.g. https://example.com/schema.json)",
+			found:        fmt.Sprintf("%q (by %v)", strVal, ann.Position.AsCompactString()),
+		}
+	}
+	return &ExternalRefAnnotation{strVal, ann.Position}, nil
+}
+
+// NewExampleAnnotation validates the value(s) from the AnnotationExamples, and returns the value(s)
+func NewExampleAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ExampleAnnotation, error) {
+	if len(ann.Kwargs) != 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+			expected:     "2-tuple containing description (string) and example value (of expected type)",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
+		}
+	}
+	if len(ann.Args) == 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+			expected:     "2-tuple containing description (string) and example value (of expected type)",
+			found:        fmt.Sprintf("missing value in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
+		}
+	}
+
+	var examples []Example
+	for _, ex := range ann.Args {
+		exampleTuple, ok := ex.(starlark.Tuple)
+		if !ok {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+				expected:     "2-tuple containing description (string) and example value (of expected type)",
+				found:        fmt.Sprintf("%v for @%v (at %v)", ex.Type(), AnnotationExamples, ann.Position.AsCompactString()),
+			}
+		}
+		switch {
+		case len(exampleTuple) == 0:
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+				expected:     "2-tuple containing description (string) and example value (of expected type)",
+				found:        fmt.Sprintf("empty tuple in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
+			}
+		case len(exampleTuple) == 1:
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+				expected:     "2-tuple containing description (string) and example value (of expected type)",
+				found:        fmt.Sprintf("empty tuple in @%v (by %v)", AnnotationExamples, ann.Position.AsCompactString()),
+			}
+		case len(exampleTuple) > 2:
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+				expected:     "2-tuple containing description (string) and example value (of expected type)",
+				found:        fmt.Sprintf("%v-tuple argument in @%v (by %v)", len(exampleTuple), AnnotationExamples, ann.Position.AsCompactString()),
+			}
+		default:
+			description, err := core.NewStarlarkValue(exampleTuple[0]).AsString()
+			if err != nil {
+				return nil, schemaAssertionError{
+					annPositions: []*filepos.Position{ann.Position},
+					position:     pos,
+					description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExamples),
+					expected:     "2-tuple containing description (string) and example value (of expected type)",
+					found:        fmt.Sprintf("%v value for @%v (at %v)", exampleTuple[0].Type(), AnnotationExamples, ann.Position.AsCompactString()),
+				}
+			}
+			exampleVal, err := core.NewStarlarkValue(exampleTuple[1]).AsGoValue()
+			if err != nil {
+				panic(err)
+			}
+			examples = append(examples, Example{description, yamlmeta.NewASTFromInterfaceWithPosition(exampleVal, pos)})
+		}
+	}
+	return &ExampleAnnotation{examples, ann.Position}, nil
+}
+
+// NewExtensionAnnotation checks the arguments provided via @schema/extension annotation (either a name and a value, or
+// any number of 2-tuples of a name and a value), and returns wrapper for the extensions given.
+func NewExtensionAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*ExtensionAnnotation, error) {
+	syntaxError := func(found string) error {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationExtension),
+			expected:     "a name (string) and a value, or 2-tuples of a name and a value",
+			found:        fmt.Sprintf("%s in @%v (by %v)", found, AnnotationExtension, ann.Position.AsCompactString()),
+			hints:        []string{"to give several extensions at once, give a 2-tuple for each (e.g. (\"x-a\", 1), (\"x-b\", 2))."},
+		}
+	}
+	if len(ann.Kwargs) != 0 {
+		return nil, syntaxError("keyword argument")
+	}
+	if len(ann.Args) == 0 {
+		return nil, syntaxError("missing value")
+	}
+
+	var pairs []starlark.Tuple
+	if _, isName := ann.Args[0].(starlark.String); isName {
+		if len(ann.Args) != 2 {
+			return nil, syntaxError(fmt.Sprintf("%v values", len(ann.Args)))
+		}
+		pairs = append(pairs, starlark.Tuple(ann.Args))
+	} else {
+		for _, arg := range ann.Args {
+			pair, ok := arg.(starlark.Tuple)
+			if !ok || len(pair) != 2 {
+				return nil, syntaxError(fmt.Sprintf("%v value", arg.Type()))
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+
+	var extensions []Extension
+	for _, pair := range pairs {
+		name, err := core.NewStarlarkValue(pair[0]).AsString()
+		if err != nil {
+			return nil, syntaxError(fmt.Sprintf("%v name", pair[0].Type()))
+		}
+		if !strings.HasPrefix(name, extensionPrefix) {
+			return nil, schemaAssertionError{
+				annPositions: []*filepos.Position{ann.Position},
+				position:     pos,
+				description:  fmt.Sprintf("invalid name in @%v annotation", AnnotationExtension),
+				expected:     fmt.Sprintf("a name starting with \"%s\"", extensionPrefix),
+				found:        name,
+				hints:        []string{"that prefix tells vendor extensions apart from the keywords of OpenAPI and JSON Schema."},
+			}
+		}
+		value, err := core.NewStarlarkValue(pair[1]).AsGoValue()
+		if err != nil {
+			// at this point the annotation is processed, and the Starlark evaluated
+			panic(err)
+		}
+		extensions = append(extensions, Extension{name, yamlmeta.NewASTFromInterfaceWithPosition(value, pos)})
+	}
+	return &ExtensionAnnotation{extensions, ann.Position}, nil
+}
+
+// NewAllowExtraPropertiesAnnotation checks the argument (if any) provided via @schema/allow-extra-properties
+// annotation, and returns wrapper for the type of extra values inferred from that argument.
+func NewAllowExtraPropertiesAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*AllowExtraPropertiesAnnotation, error) {
+	if len(ann.Kwargs) != 0 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationAllowExtraProperties),
+			expected:     "at most one argument",
+			found:        fmt.Sprintf("keyword argument in @%v (by %v)", AnnotationAllowExtraProperties, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation accepts either no argument or one: a value of the type extra keys must have."},
+		}
+	}
+	if len(ann.Args) > 1 {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationAllowExtraProperties),
+			expected:     "at most one argument",
+			found:        fmt.Sprintf("%v values in @%v (by %v)", len(ann.Args), AnnotationAllowExtraProperties, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation accepts either no argument or one: a value of the type extra keys must have."},
+		}
+	}
+	if len(ann.Args) == 0 {
+		return &AllowExtraPropertiesAnnotation{pos: ann.Position}, nil
+	}
+
+	val, err := core.NewStarlarkValue(ann.Args[0]).AsGoValue()
+	if err != nil {
+		// at this point the annotation is processed, and the Starlark evaluated
+		panic(err)
+	}
+	valueType, err := InferTypeFromValue(yamlmeta.NewASTFromInterfaceWithPosition(val, pos), pos)
+	if err != nil {
+		return nil, err
+	}
+	if valueType == nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationAllowExtraProperties),
+			expected:     "non-null value",
+			found:        fmt.Sprintf("null value in @%v (by %v)", AnnotationAllowExtraProperties, ann.Position.AsCompactString()),
+			hints:        []string{"to allow extra keys of any value, give no argument."},
+		}
+	}
+	return &AllowExtraPropertiesAnnotation{valueType, ann.Position}, nil
+}
+
+// NewPatternPropertyAnnotation checks the arguments provided via @schema/pattern-property annotation (a regular
+// expression, then a value), and returns wrapper for that expression and the type inferred from that value.
+func NewPatternPropertyAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*PatternPropertyAnnotation, error) {
+	syntaxError := func(found string) error {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationPatternProperty),
+			expected:     "two arguments: a string, then a value",
+			found:        fmt.Sprintf("%s in @%v (by %v)", found, AnnotationPatternProperty, ann.Position.AsCompactString()),
+			hints:        []string{"this annotation accepts a pattern keys must match, then a value of the type those keys must have (e.g. \"^x-\", \"\")."},
+		}
+	}
+	if len(ann.Kwargs) != 0 {
+		return nil, syntaxError("keyword argument")
+	}
+	if len(ann.Args) != 2 {
+		return nil, syntaxError(fmt.Sprintf("%v values", len(ann.Args)))
+	}
+
+	pattern, err := core.NewStarlarkValue(ann.Args[0]).AsString()
+	if err != nil {
+		return nil, syntaxError("Non-string pattern")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("invalid pattern in @%v annotation", AnnotationPatternProperty),
+			expected:     "regular expression",
+			found:        fmt.Sprintf("%v (by %v)", err, ann.Position.AsCompactString()),
+		}
+	}
+
+	val, err := core.NewStarlarkValue(ann.Args[1]).AsGoValue()
+	if err != nil {
+		// at this point the annotation is processed, and the Starlark evaluated
+		panic(err)
+	}
+	valueType, err := InferTypeFromValue(yamlmeta.NewASTFromInterfaceWithPosition(val, pos), pos)
+	if err != nil {
+		return nil, err
+	}
+	if valueType == nil {
+		return nil, syntaxError("null value")
+	}
+	return &PatternPropertyAnnotation{&patternProperty{pattern, valueType}, ann.Position}, nil
+}
+
+// NewEnumDescriptionsAnnotation checks the argument provided via @schema/enum-descriptions annotation (a dict of each
+// allowed value to either its description, or a 2-tuple of its title and its description), and returns wrapper for
+// those descriptions.
+func NewEnumDescriptionsAnnotation(ann template.NodeAnnotation, pos *filepos.Position) (*EnumDescriptionsAnnotation, error) {
+	syntaxError := func(found string) error {
+		return schemaAssertionError{
+			annPositions: []*filepos.Position{ann.Position},
+			position:     pos,
+			description:  fmt.Sprintf("syntax error in @%v annotation", AnnotationEnumDescriptions),
+			expected:     "a dict of each allowed value to its description (e.g. {\"info\": \"Logs requests\"})",
+			found:        fmt.Sprintf("%s in @%v (by %v)", found, AnnotationEnumDescriptions, ann.Position.AsCompactString()),
+			hints:        []string{"to title a value too, give a 2-tuple of its title and its description (e.g. {\"info\": (\"Info\", \"Logs requests\")})."},
+		}
+	}
+	if len(ann.Kwargs) != 0 {
+		return nil, syntaxError("keyword argument")
+	}
+	if len(ann.Args) != 1 {
+		return nil, syntaxError(fmt.Sprintf("%v values", len(ann.Args)))
+	}
+	val, err := core.NewStarlarkValue(ann.Args[0]).AsGoValue()
+	if err != nil {
+		return nil, syntaxError(err.Error())
+	}
+	descriptionsMap, ok := val.(*orderedmap.Map)
+	if !ok || descriptionsMap.Len() == 0 {
+		return nil, syntaxError(fmt.Sprintf("%v", val))
+	}
+
+	enumAnn := &EnumDescriptionsAnnotation{pos: ann.Position}
+	err = descriptionsMap.IterateErr(func(value, doc interface{}) error {
+		switch typedDoc := doc.(type) {
+		case string:
+			enumAnn.descriptions = append(enumAnn.descriptions, enumDescription{value: value, description: typedDoc})
+			return nil
+		case []interface{}:
+			if len(typedDoc) == 2 {
+				title, isTitle := typedDoc[0].(string)
+				description, isDescription := typedDoc[1].(string)
+				if isTitle && isDescription {
+					enumAnn.descriptions = append(enumAnn.descriptions, enumDescription{value, title, description})
+					return nil
+				}
+			}
+		}
+		return syntaxError(fmt.Sprintf("%v as the documentation of %v", doc, value))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enumAnn, nil
+}
+
+// NewValidationAnnotation checks the values provided via @schema/validation annotation, and returns wrapper for the validation defined
+func NewValidationAnnotation(ann template.NodeAnnotation) (*ValidationAnnotation, error) {
+	validation, err := validations.NewValidationFromAnn(ann)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid @%s annotation - %s", AnnotationValidation, err.Error())
+	}
+
+	return &ValidationAnnotation{validation, ann.Position}, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation.
 func (t *TypeAnnotation) NewTypeFromAnn() (Type, error) {
 	if t.any {
 		return &AnyType{defaultValue: t.node.GetValues()[0], Position: t.node.GetPosition()}, nil
@@ -453,6 +1534,45 @@ func (d *DeprecatedAnnotation) NewTypeFromAnn() (Type, error) {
 	return nil, nil
 }
 
+// NewTypeFromAnn returns type information given by annotation. NoAdditionalPropsKeyAnnotation only affects how a map
+// is exported, it does not type it.
+func (n *NoAdditionalPropsKeyAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation.
+func (t *TupleAnnotation) NewTypeFromAnn() (Type, error) {
+	return NewTupleType(t.node.GetValues()[0].(*yamlmeta.Array))
+}
+
+// NewTypeFromAnn returns type information given by annotation. UnwrapAnnotation only affects how a map is exported, it
+// does not type it.
+func (u *UnwrapAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. ScalarOrArrayAnnotation relaxes an array, it does not
+// type it.
+func (s *ScalarOrArrayAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. NullableItemsAnnotation relaxes the items of an array,
+// it does not type the array.
+func (n *NullableItemsAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. ReadOnlyAnnotation has no type information.
+func (r *ReadOnlyAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. WriteOnlyAnnotation has no type information.
+func (w *WriteOnlyAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
 // NewTypeFromAnn returns type information given by annotation. DescriptionAnnotation has no type information.
 func (d *DescriptionAnnotation) NewTypeFromAnn() (Type, error) {
 	return nil, nil
@@ -463,11 +1583,97 @@ func (t *TitleAnnotation) NewTypeFromAnn() (Type, error) {
 	return nil, nil
 }
 
+// NewTypeFromAnn returns type information given by annotation. CommentAnnotation has no type information.
+func (c *CommentAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
 // NewTypeFromAnn returns type information given by annotation.
 func (v *ValidationAnnotation) NewTypeFromAnn() (Type, error) {
 	return nil, nil
 }
 
+// NewTypeFromAnn returns type information given by annotation. DiscriminatorAnnotation documents a map, it does not
+// type it.
+func (d *DiscriminatorAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. ContentEncodingAnnotation refines a string, it does not
+// type it.
+func (c *ContentEncodingAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. ContentMediaTypeAnnotation refines a string, it does not
+// type it.
+func (c *ContentMediaTypeAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. FormatAnnotation refines a string (or an integer), it does not type it.
+func (f *FormatAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. UnitAnnotation documents a number, it does not type it.
+func (u *UnitAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. KeyPatternAnnotation constrains keys, not the annotated
+// node.
+func (k *KeyPatternAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. SchemaNameAnnotation names a type, it does not type it.
+func (n *SchemaNameAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. ExternalRefAnnotation refers to a type defined elsewhere,
+// it does not type the annotated node.
+func (e *ExternalRefAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. DependentRequiredAnnotation constrains keys, it does not
+// type the annotated node.
+func (d *DependentRequiredAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. WhenAnnotation constrains keys, it does not type the
+// annotated node.
+func (w *WhenAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. AllowExtraPropertiesAnnotation describes extra values,
+// not the annotated node.
+func (a *AllowExtraPropertiesAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. ExtensionAnnotation documents a type, it does not type
+// it.
+func (e *ExtensionAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. PatternPropertyAnnotation describes the values of some
+// keys, not the annotated node.
+func (p *PatternPropertyAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
+// NewTypeFromAnn returns type information given by annotation. EnumDescriptionsAnnotation documents the values allowed,
+// it does not type them.
+func (e *EnumDescriptionsAnnotation) NewTypeFromAnn() (Type, error) {
+	return nil, nil
+}
+
 // GetPosition returns position of the source comment used to create this annotation.
 func (n *NullableAnnotation) GetPosition() *filepos.Position {
 	return n.pos
@@ -488,6 +1694,41 @@ func (d *DeprecatedAnnotation) GetPosition() *filepos.Position {
 	return d.pos
 }
 
+// GetPosition returns position of the source comment used to create this annotation.
+func (n *NoAdditionalPropsKeyAnnotation) GetPosition() *filepos.Position {
+	return n.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (u *UnwrapAnnotation) GetPosition() *filepos.Position {
+	return u.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (t *TupleAnnotation) GetPosition() *filepos.Position {
+	return t.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (s *ScalarOrArrayAnnotation) GetPosition() *filepos.Position {
+	return s.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (n *NullableItemsAnnotation) GetPosition() *filepos.Position {
+	return n.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (r *ReadOnlyAnnotation) GetPosition() *filepos.Position {
+	return r.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (w *WriteOnlyAnnotation) GetPosition() *filepos.Position {
+	return w.pos
+}
+
 // GetPosition returns position of the source comment used to create this annotation.
 func (d *DescriptionAnnotation) GetPosition() *filepos.Position {
 	return d.pos
@@ -503,20 +1744,198 @@ func (t *TitleAnnotation) GetPosition() *filepos.Position {
 	return t.pos
 }
 
+// GetPosition returns position of the source comment used to create this annotation.
+func (c *CommentAnnotation) GetPosition() *filepos.Position {
+	return c.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (d *DiscriminatorAnnotation) GetPosition() *filepos.Position {
+	return d.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (c *ContentEncodingAnnotation) GetPosition() *filepos.Position {
+	return c.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (c *ContentMediaTypeAnnotation) GetPosition() *filepos.Position {
+	return c.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (f *FormatAnnotation) GetPosition() *filepos.Position {
+	return f.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (u *UnitAnnotation) GetPosition() *filepos.Position {
+	return u.pos
+}
+
 // GetPosition returns position of the source comment used to create this annotation.
 func (v *ValidationAnnotation) GetPosition() *filepos.Position {
 	return nil
 }
 
+// GetPosition returns position of the source comment used to create this annotation.
+func (k *KeyPatternAnnotation) GetPosition() *filepos.Position {
+	return k.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (e *ExternalRefAnnotation) GetPosition() *filepos.Position {
+	return e.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (n *SchemaNameAnnotation) GetPosition() *filepos.Position {
+	return n.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (d *DependentRequiredAnnotation) GetPosition() *filepos.Position {
+	return d.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (w *WhenAnnotation) GetPosition() *filepos.Position {
+	return w.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (a *AllowExtraPropertiesAnnotation) GetPosition() *filepos.Position {
+	return a.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (p *PatternPropertyAnnotation) GetPosition() *filepos.Position {
+	return p.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (e *EnumDescriptionsAnnotation) GetPosition() *filepos.Position {
+	return e.pos
+}
+
+// GetPosition returns position of the source comment used to create this annotation.
+func (e *ExtensionAnnotation) GetPosition() *filepos.Position {
+	return e.pos
+}
+
 // GetValidation gets the NodeValidation created from @schema/validation annotation
 func (v *ValidationAnnotation) GetValidation() *validations.NodeValidation {
 	return v.validation
 }
 
+// checkAppliesTo reports rules of this validation that can never be satisfied by a value of type `typeOfValue`
+// (i.e. multiple_of= on anything other than a number, min_props= or max_props= on anything other than a map), and
+// values allowed via one_of= that are not of the type of a scalar.
+func (v *ValidationAnnotation) checkAppliesTo(node yamlmeta.Node, typeOfValue Type) error {
+	valueType := typeOfValue
+	nullType, isNullable := typeOfValue.(*NullType)
+	if isNullable {
+		valueType = nullType.GetValueType()
+	}
+	if _, isAny := valueType.(*AnyType); isAny {
+		return nil
+	}
+
+	if scalarType, isScalar := valueType.(*ScalarType); isScalar {
+		values, _ := v.validation.HasSimpleOneOf()
+		for i, value := range values {
+			if (value == nil && isNullable) || scalarAllows(scalarType, value) {
+				continue
+			}
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{v.pos},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("type mismatch in values of %s=", validations.KwargOneOf),
+				expected:     scalarType.String(),
+				found:        fmt.Sprintf("%s %s (at index %d)", yamlmeta.TypeName(value), asJSON(value), i),
+				hints:        []string{"a value of another type would never be allowed: the value is only ever of its own type (e.g. that of its default)."},
+			})
+		}
+	}
+
+	if _, found := v.validation.HasSimpleMultipleOf(); found {
+		if scalarType, isScalar := valueType.(*ScalarType); !isScalar || (scalarType.ValueType != IntType && scalarType.ValueType != FloatType) {
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{v.pos},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("%s= not supported on values of type %s", validations.KwargMultipleOf, typeOfValue.String()),
+				expected:     "integer or float",
+				found:        typeOfValue.String(),
+				hints:        []string{"only numbers can be required to be a multiple (of some number)."},
+			})
+		}
+	}
+
+	_, hasMinProps := v.validation.HasSimpleMinProperties()
+	_, hasMaxProps := v.validation.HasSimpleMaxProperties()
+	if !hasMinProps && !hasMaxProps {
+		return nil
+	}
+	kwarg := validations.KwargMinProperties
+	if !hasMinProps {
+		kwarg = validations.KwargMaxProperties
+	}
+	if _, isMap := valueType.(*MapType); !isMap {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{v.pos},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("%s= not supported on values of type %s", kwarg, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps have a number of keys; to bound the length of strings or arrays, use min_len= and max_len=."},
+		})
+	}
+	// on maps, min_len= and max_len= already bound the number of keys (and are exported as the same keywords).
+	_, hasMinLen := v.validation.HasSimpleMinLength()
+	_, hasMaxLen := v.validation.HasSimpleMaxLength()
+	if (hasMinProps && hasMinLen) || (hasMaxProps && hasMaxLen) {
+		lenKwarg := validations.KwargMinLength
+		if !(hasMinProps && hasMinLen) {
+			kwarg, lenKwarg = validations.KwargMaxProperties, validations.KwargMaxLength
+		}
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{v.pos},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("both %s= and %s= given", kwarg, lenKwarg),
+			expected:     fmt.Sprintf("one of %s= or %s=", kwarg, lenKwarg),
+			found:        "both",
+			hints:        []string{fmt.Sprintf("on a map, %s= bounds the number of keys just as %s= does.", lenKwarg, kwarg)},
+		})
+	}
+	return nil
+}
+
+// scalarAllows indicates whether `value` is of the type of `scalarType` (an integer being a float, too).
+func scalarAllows(scalarType *ScalarType, value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return scalarType.ValueType == StringType
+	case int64:
+		return scalarType.ValueType == IntType || scalarType.ValueType == FloatType
+	case float64:
+		return scalarType.ValueType == FloatType
+	case bool:
+		return scalarType.ValueType == BoolType
+	default:
+		return false
+	}
+}
+
 func (t *TypeAnnotation) IsAny() bool {
 	return t.any
 }
 
+// TypeName returns the name of the type given (if any), e.g. "integer".
+func (t *TypeAnnotation) TypeName() string {
+	return t.typeName
+}
+
 // Val returns default value specified in annotation.
 func (d *DefaultAnnotation) Val() interface{} {
 	return d.val
@@ -525,7 +1944,7 @@ func (d *DefaultAnnotation) Val() interface{} {
 func collectTypeAnnotations(node yamlmeta.Node) ([]Annotation, error) {
 	var anns []Annotation
 
-	for _, annotation := range []template.AnnotationName{AnnotationType, AnnotationNullable} {
+	for _, annotation := range []template.AnnotationName{AnnotationType, AnnotationTuple, AnnotationNullable} {
 		ann, err := processOptionalAnnotation(node, annotation, nil)
 		if err != nil {
 			return nil, err
@@ -553,10 +1972,13 @@ func collectValueAnnotations(node yamlmeta.Node, effectiveType Type) ([]Annotati
 }
 
 // collectDocumentationAnnotations provides annotations that are used for documentation purposes
+// documentationAnnotations lists the annotations documenting a node (rather than typing or constraining it).
+var documentationAnnotations = []template.AnnotationName{AnnotationDescription, AnnotationTitle, AnnotationComment, AnnotationExamples, AnnotationDeprecated, AnnotationReadOnly, AnnotationWriteOnly, AnnotationExtension}
+
 func collectDocumentationAnnotations(node yamlmeta.Node) ([]Annotation, error) {
 	var anns []Annotation
 
-	for _, annotation := range []template.AnnotationName{AnnotationDescription, AnnotationTitle, AnnotationExamples, AnnotationDeprecated} {
+	for _, annotation := range documentationAnnotations {
 		ann, err := processOptionalAnnotation(node, annotation, nil)
 		if err != nil {
 			return nil, err
@@ -565,6 +1987,17 @@ func collectDocumentationAnnotations(node yamlmeta.Node) ([]Annotation, error) {
 			anns = append(anns, ann)
 		}
 	}
+	nodeAnnotations := template.NewAnnotations(node)
+	if nodeAnnotations.Has(AnnotationReadOnly) && nodeAnnotations.Has(AnnotationWriteOnly) {
+		return nil, schemaAssertionError{
+			annPositions: []*filepos.Position{nodeAnnotations[AnnotationReadOnly].Position, nodeAnnotations[AnnotationWriteOnly].Position},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v and @%v are mutually exclusive", AnnotationReadOnly, AnnotationWriteOnly),
+			expected:     fmt.Sprintf("one of @%v or @%v", AnnotationReadOnly, AnnotationWriteOnly),
+			found:        fmt.Sprintf("both @%v and @%v", AnnotationReadOnly, AnnotationWriteOnly),
+			hints:        []string{"a value cannot be both populated by the system (read-only), and never returned by it (write-only)."},
+		}
+	}
 	return anns, nil
 }
 
@@ -640,6 +2073,138 @@ func processOptionalAnnotation(node yamlmeta.Node, optionalAnnotation template.A
 				return nil, err
 			}
 			return titleAnn, nil
+		case AnnotationComment:
+			commentAnn, err := NewCommentAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return commentAnn, nil
+		case AnnotationAllowExtraProperties:
+			extraPropsAnn, err := NewAllowExtraPropertiesAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return extraPropsAnn, nil
+		case AnnotationReadOnly:
+			readOnlyAnn, err := NewReadOnlyAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return readOnlyAnn, nil
+		case AnnotationWriteOnly:
+			writeOnlyAnn, err := NewWriteOnlyAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return writeOnlyAnn, nil
+		case AnnotationFormat:
+			formatAnn, err := NewFormatAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return formatAnn, nil
+		case AnnotationUnit:
+			unitAnn, err := NewUnitAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return unitAnn, nil
+		case AnnotationNoAdditionalPropsKey:
+			noAdditionalPropsKeyAnn, err := NewNoAdditionalPropsKeyAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return noAdditionalPropsKeyAnn, nil
+		case AnnotationUnwrap:
+			unwrapAnn, err := NewUnwrapAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return unwrapAnn, nil
+		case AnnotationTuple:
+			tupleAnn, err := NewTupleAnnotation(ann, node)
+			if err != nil {
+				return nil, err
+			}
+			return tupleAnn, nil
+		case AnnotationScalarOrArray:
+			scalarOrArrayAnn, err := NewScalarOrArrayAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return scalarOrArrayAnn, nil
+		case AnnotationNullableItems:
+			nullableItemsAnn, err := NewNullableItemsAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return nullableItemsAnn, nil
+		case AnnotationDiscriminator:
+			discriminatorAnn, err := NewDiscriminatorAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return discriminatorAnn, nil
+		case AnnotationContentEncoding:
+			encodingAnn, err := NewContentEncodingAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return encodingAnn, nil
+		case AnnotationContentMediaType:
+			mediaTypeAnn, err := NewContentMediaTypeAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return mediaTypeAnn, nil
+		case AnnotationKeyPattern:
+			keyPatternAnn, err := NewKeyPatternAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return keyPatternAnn, nil
+		case AnnotationSchemaName:
+			schemaNameAnn, err := NewSchemaNameAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return schemaNameAnn, nil
+		case AnnotationExternalRef:
+			externalRefAnn, err := NewExternalRefAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return externalRefAnn, nil
+		case AnnotationDependentRequired:
+			depAnn, err := NewDependentRequiredAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return depAnn, nil
+		case AnnotationWhen:
+			whenAnn, err := NewWhenAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return whenAnn, nil
+		case AnnotationPatternProperty:
+			patternPropertyAnn, err := NewPatternPropertyAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return patternPropertyAnn, nil
+		case AnnotationExtension:
+			extensionAnn, err := NewExtensionAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return extensionAnn, nil
+		case AnnotationEnumDescriptions:
+			enumDescriptionsAnn, err := NewEnumDescriptionsAnnotation(ann, node.GetPosition())
+			if err != nil {
+				return nil, err
+			}
+			return enumDescriptionsAnn, nil
 		}
 	}
 
@@ -672,6 +2237,14 @@ func getTypeFromAnnotations(anns []Annotation) (Type, error) {
 					return nil, err
 				}
 			}
+		case *TupleAnnotation:
+			if typeFromAnn == nil {
+				var err error
+				typeFromAnn, err = typedAnn.NewTypeFromAnn()
+				if err != nil {
+					return nil, err
+				}
+			}
 		case *NullableAnnotation:
 			if typeFromAnn == nil {
 				var err error
@@ -679,6 +2252,8 @@ func getTypeFromAnnotations(anns []Annotation) (Type, error) {
 				if err != nil {
 					return nil, err
 				}
+			} else if tupleType, isTuple := typeFromAnn.(*ArrayType); isTuple {
+				typeFromAnn = &NullType{ValueType: tupleType, Position: typedAnn.node.GetPosition()}
 			} else {
 				typeFromAnn.SetDefaultValue(nil)
 			}
@@ -695,17 +2270,689 @@ func setDocumentationFromAnns(docAnns []Annotation, typeOfValue Type) error {
 		switch ann := a.(type) {
 		case *TitleAnnotation:
 			typeOfValue.SetTitle(ann.title)
+		case *CommentAnnotation:
+			typeOfValue.SetComment(ann.comment)
 		case *DescriptionAnnotation:
 			typeOfValue.SetDescription(ann.description)
 		case *DeprecatedAnnotation:
 			typeOfValue.SetDeprecated(true, ann.notice)
+			// the dates of the deprecation are given alongside the vendor extensions given via @schema/extension.
+			typeOfValue.SetExtensions(append(ann.extensions(), typeOfValue.GetExtensions()...))
+		case *ReadOnlyAnnotation:
+			typeOfValue.SetAccess(true, false)
+		case *WriteOnlyAnnotation:
+			typeOfValue.SetAccess(false, true)
 		case *ExampleAnnotation:
 			err := checkExamplesValue(ann, typeOfValue)
 			if err != nil {
 				return err
 			}
 			typeOfValue.SetExamples(ann.examples)
+		case *ExtensionAnnotation:
+			typeOfValue.SetExtensions(append(typeOfValue.GetExtensions(), ann.extensions...))
+		}
+	}
+	return nil
+}
+
+// setExtraPropertiesFromAnn records on `typeOfValue` (which must be a map) that it permits extra keys, if `node` is
+// annotated with @schema/allow-extra-properties.
+func setExtraPropertiesFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationAllowExtraProperties, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationAllowExtraProperties, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps can be allowed to contain keys beyond those declared."},
+		})
+	}
+	mapType.allowExtraProperties = true
+	mapType.extraPropertiesType = ann.(*AllowExtraPropertiesAnnotation).valueType
+	return nil
+}
+
+// setPatternPropertyFromAnn records on `typeOfValue` (which must be a map) that it permits keys matching a pattern, if
+// `node` is annotated with @schema/pattern-property.
+func setPatternPropertyFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationPatternProperty, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationPatternProperty, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps can be allowed to contain keys matching a pattern."},
+		})
+	}
+	mapType.patternProperty = ann.(*PatternPropertyAnnotation).property
+	return nil
+}
+
+// setEnumDescriptionsFromAnn records on `typeOfValue` (which must be a scalar) the documentation of the values it is
+// allowed (via one_of=, in @schema/validation), if `node` is annotated with @schema/enum-descriptions.
+func setEnumDescriptionsFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationEnumDescriptions, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		scalarType, ok = nullType.GetValueType().(*ScalarType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationEnumDescriptions, typeOfValue.String()),
+			expected:     "string, integer, float or boolean",
+			found:        typeOfValue.String(),
+			hints:        []string{"only scalars can have their allowed values documented."},
+		})
+	}
+
+	validationAnn, err := processValidationAnnotation(node)
+	if err != nil {
+		return err
+	}
+	var allowed []interface{}
+	if validationAnn != nil {
+		allowed, _ = validationAnn.GetValidation().HasSimpleOneOf()
+	}
+	for _, described := range ann.(*EnumDescriptionsAnnotation).descriptions {
+		if !containsValue(allowed, described.value) {
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{ann.GetPosition()},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("value described in @%v is not allowed", AnnotationEnumDescriptions),
+				expected:     fmt.Sprintf("one of the values allowed via %s= (in @%v)", validations.KwargOneOf, AnnotationValidation),
+				found:        fmt.Sprintf("%v", described.value),
+				hints:        []string{fmt.Sprintf("only the values of an (unconditional) %s= can be described.", validations.KwargOneOf)},
+			})
+		}
+	}
+	scalarType.enumDescriptions = ann.(*EnumDescriptionsAnnotation).descriptions
+	return nil
+}
+
+// setNoAdditionalPropsKeyFromAnn records on `typeOfValue` (which must be a map, not permitting extra keys) that it is
+// exported without "additionalProperties", if `node` is annotated with @schema/no-additional-properties-key.
+func setNoAdditionalPropsKeyFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationNoAdditionalPropsKey, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationNoAdditionalPropsKey, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps have additional properties."},
+		})
+	}
+	if extraPropsAnn := template.NewAnnotations(node)[AnnotationAllowExtraProperties]; mapType.allowExtraProperties {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition(), extraPropsAnn.Position},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v and @%v are mutually exclusive", AnnotationNoAdditionalPropsKey, AnnotationAllowExtraProperties),
+			expected:     fmt.Sprintf("either @%v or @%v", AnnotationNoAdditionalPropsKey, AnnotationAllowExtraProperties),
+			found:        fmt.Sprintf("both @%v and @%v", AnnotationNoAdditionalPropsKey, AnnotationAllowExtraProperties),
+			hints:        []string{"without \"additionalProperties\", a map is exported as permitting extra keys (of any type) anyway."},
+		})
+	}
+	mapType.noAdditionalPropsKey = true
+	return nil
+}
+
+// setUnwrapFromAnn records on `typeOfValue` (which must be a map of a single key) that it is exported as the value of
+// that key, if `node` is annotated with @schema/unwrap.
+func setUnwrapFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationUnwrap, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationUnwrap, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps (of a single key) can be unwrapped."},
+		})
+	}
+	if len(mapType.Items) != 1 {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on a map of %d keys", AnnotationUnwrap, len(mapType.Items)),
+			expected:     "a map of a single key",
+			found:        fmt.Sprintf("%d keys", len(mapType.Items)),
+			hints:        []string{"a map is unwrapped into the value of its key: either leave the other keys out, or do not unwrap the map."},
+		})
+	}
+	mapType.unwrapped = true
+	return nil
+}
+
+// setFormatFromAnn records on `typeOfValue` (which must be a string, or an integer given one of integerFormats) its
+// format, if `node` is annotated with @schema/format.
+func setFormatFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationFormat, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		scalarType, ok = nullType.GetValueType().(*ScalarType)
+	}
+	format := ann.(*FormatAnnotation)
+	if isIntegerFormat(format.format) {
+		if !ok || (scalarType.ValueType != IntType && scalarType.typeName != "integer") {
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{ann.GetPosition()},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("@%v %q not supported on values of type %s", AnnotationFormat, format.format, typeOfValue.String()),
+				expected:     "integer",
+				found:        typeOfValue.String(),
+				hints:        []string{fmt.Sprintf("only integers can be given a width: either %s.", strings.Join(integerFormats, " or "))},
+			})
+		}
+	} else if !ok || scalarType.ValueType != StringType {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationFormat, typeOfValue.String()),
+			expected:     "string",
+			found:        typeOfValue.String(),
+			hints:        []string{fmt.Sprintf("only strings can be given a format (integers, only their width: either %s).", strings.Join(integerFormats, " or "))},
+		})
+	}
+	scalarType.format = format
+	return nil
+}
+
+// setContentFromAnns records on `typeOfValue` (which must be a string) the encoding and media type of its content, if
+// `node` is annotated with @schema/content-encoding and/or @schema/content-media-type.
+func setContentFromAnns(node yamlmeta.Node, typeOfValue Type) error {
+	for _, annName := range []template.AnnotationName{AnnotationContentEncoding, AnnotationContentMediaType} {
+		ann, err := processOptionalAnnotation(node, annName, nil)
+		if err != nil {
+			return NewSchemaError("Invalid schema", err)
+		}
+		if ann == nil {
+			continue
+		}
+		scalarType, ok := typeOfValue.(*ScalarType)
+		if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+			scalarType, ok = nullType.GetValueType().(*ScalarType)
+		}
+		if !ok || scalarType.ValueType != StringType {
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{ann.GetPosition()},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("@%v not supported on values of type %s", annName, typeOfValue.String()),
+				expected:     "string",
+				found:        typeOfValue.String(),
+				hints:        []string{"only strings can be given the encoding or media type of their content."},
+			})
+		}
+		switch typedAnn := ann.(type) {
+		case *ContentEncodingAnnotation:
+			scalarType.contentEncoding = typedAnn.encoding
+		case *ContentMediaTypeAnnotation:
+			scalarType.contentMediaType = typedAnn.mediaType
+		}
+	}
+	return nil
+}
+
+// setScalarOrArrayFromAnn marks `typeOfValue` (which must be an array) as also given by a single item, if `node` is
+// annotated with @schema/scalar-or-array.
+func setScalarOrArrayFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationScalarOrArray, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	arrayType, ok := typeOfValue.(*ArrayType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		arrayType, ok = nullType.GetValueType().(*ArrayType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationScalarOrArray, typeOfValue.String()),
+			expected:     "array",
+			found:        typeOfValue.String(),
+			hints:        []string{"only arrays can also be given as one of their items."},
+		})
+	}
+	if arrayType.tupleItems != nil {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on a tuple", AnnotationScalarOrArray),
+			expected:     "array",
+			found:        fmt.Sprintf("tuple (by @%v)", AnnotationTuple),
+			hints:        []string{"a tuple is given as all of its items, never as a single one."},
+		})
+	}
+	arrayType.scalarOrArray = true
+	return nil
+}
+
+// setNullableItemsFromAnn makes the items of `typeOfValue` (which must be an array) nullable, if `node` is annotated
+// with @schema/nullable-items: like an item annotated with @schema/nullable, each then defaults to null.
+func setNullableItemsFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationNullableItems, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	arrayType, ok := typeOfValue.(*ArrayType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		arrayType, ok = nullType.GetValueType().(*ArrayType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationNullableItems, typeOfValue.String()),
+			expected:     "array",
+			found:        typeOfValue.String(),
+			hints:        []string{"only the items of arrays can be made nullable; to make a value nullable, use @schema/nullable."},
+		})
+	}
+	if arrayType.tupleItems != nil {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on a tuple", AnnotationNullableItems),
+			expected:     "array",
+			found:        fmt.Sprintf("tuple (by @%v)", AnnotationTuple),
+			hints:        []string{"to make an item of a tuple nullable, annotate that item with @schema/nullable."},
+		})
+	}
+	itemType := arrayType.ItemsType.(*ArrayItemType)
+	switch itemType.ValueType.(type) {
+	case *NullType, *AnyType:
+		// (already permits null)
+	default:
+		itemType.ValueType = &NullType{ValueType: itemType.ValueType, Position: itemType.Position}
+	}
+	itemType.ValueType.SetDefaultValue(nil)
+	itemType.defaultValue = nil
+	return nil
+}
+
+// numberTypeNames are the types a number can be given via @schema/type (in place of the one inferred from its value).
+var numberTypeNames = []string{"integer", "number"}
+
+// setTypeNameFromAnn records on `typeOfValue` (which must be a number) the type it is exported as, if `node` is
+// annotated with @schema/type naming one (e.g. an integer whose default is written `0.0`).
+func setTypeNameFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationType, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	typeAnn, ok := ann.(*TypeAnnotation)
+	if !ok || typeAnn.TypeName() == "" {
+		return nil
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		scalarType, ok = nullType.GetValueType().(*ScalarType)
+	}
+	isNumber := ok && (scalarType.ValueType == IntType || scalarType.ValueType == FloatType)
+	isNumberType := false
+	for _, name := range numberTypeNames {
+		isNumberType = isNumberType || name == typeAnn.TypeName()
+	}
+	if !isNumber || !isNumberType {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{typeAnn.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v %q not supported on values of type %s", AnnotationType, typeAnn.TypeName(), typeOfValue.String()),
+			expected:     strings.Join(numberTypeNames, " or "),
+			found:        typeAnn.TypeName(),
+			hints:        []string{fmt.Sprintf("only numbers can be given a type: either %s.", strings.Join(numberTypeNames, " or "))},
+		})
+	}
+	if defaultValue, isFloat := scalarType.defaultValue.(float64); isFloat && typeAnn.TypeName() == "integer" && defaultValue != math.Trunc(defaultValue) {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{typeAnn.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v %q not supported on a fractional number", AnnotationType, typeAnn.TypeName()),
+			expected:     "a whole number",
+			found:        fmt.Sprintf("%v", defaultValue),
+			hints:        []string{fmt.Sprintf("to allow fractional values, give @%v \"number\" (or none).", AnnotationType)},
+		})
+	}
+	scalarType.typeName = typeAnn.TypeName()
+	return nil
+}
+
+// unitExtension is the vendor extension giving the unit of a number (see @schema/unit).
+const unitExtension = extensionPrefix + "unit"
+
+// setUnitFromAnn gives `typeOfValue` (which must be a number) the vendor extension naming its unit, if `node` is
+// annotated with @schema/unit.
+func setUnitFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationUnit, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	scalarType, ok := typeOfValue.(*ScalarType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		scalarType, ok = nullType.GetValueType().(*ScalarType)
+	}
+	if !ok || (scalarType.ValueType != IntType && scalarType.ValueType != FloatType) {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationUnit, typeOfValue.String()),
+			expected:     "integer or float",
+			found:        typeOfValue.String(),
+			hints:        []string{"only numbers can be given a unit."},
+		})
+	}
+	typeOfValue.SetExtensions(append(typeOfValue.GetExtensions(), Extension{unitExtension, ann.(*UnitAnnotation).unit}))
+	return nil
+}
+
+// unitOf gives the unit of `typedValue` (see @schema/unit); empty, if it has none.
+func unitOf(typedValue Type) string {
+	for _, extension := range typedValue.GetExtensions() {
+		if extension.name == unitExtension {
+			return fmt.Sprintf("%v", extension.value)
+		}
+	}
+	return ""
+}
+
+// setKeyPatternFromAnn records on `typeOfValue` (which must be a map) the pattern its keys must match, if `node` is
+// annotated with @schema/key-pattern.
+func setKeyPatternFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationKeyPattern, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationKeyPattern, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only the keys of maps can be constrained by a pattern."},
+		})
+	}
+	mapType.keyPattern = ann.(*KeyPatternAnnotation).pattern
+	return nil
+}
+
+// setExternalRefFromAnn records on `typeOfValue` (which must be a map) the URI of the schema it is exported as, if
+// `node` is annotated with @schema/external-ref. That schema documents the map, so `node` cannot also be documented.
+func setExternalRefFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationExternalRef, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationExternalRef, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps can refer to an external schema."},
+		})
+	}
+	nodeAnnotations := template.NewAnnotations(node)
+	for _, docAnn := range documentationAnnotations {
+		if nodeAnnotations.Has(docAnn) {
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{ann.GetPosition(), nodeAnnotations[docAnn].Position},
+				position:     node.GetPosition(),
+				description:  fmt.Sprintf("@%v and @%v are mutually exclusive", AnnotationExternalRef, docAnn),
+				expected:     fmt.Sprintf("either @%v or documentation", AnnotationExternalRef),
+				found:        fmt.Sprintf("both @%v and @%v", AnnotationExternalRef, docAnn),
+				hints:        []string{"a map referring to an external schema is documented by that schema."},
+			})
+		}
+	}
+	mapType.externalRef = ann.(*ExternalRefAnnotation).uri
+	return nil
+}
+
+// setSchemaNameFromAnn records on `typeOfValue` (which must be a map) the name (and URI) under which it is exported, if
+// `node` is annotated with @schema/schema-name.
+func setSchemaNameFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationSchemaName, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationSchemaName, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps can be given a schema name."},
+		})
+	}
+	mapType.schemaName = ann.(*SchemaNameAnnotation).name
+	mapType.schemaID = ann.(*SchemaNameAnnotation).id
+	return nil
+}
+
+// setDependentRequiredFromAnn records on `typeOfValue` (which must be a map) which of its keys require others, if `node`
+// is annotated with @schema/dependent-required. Each key named must be one of the map's.
+func setDependentRequiredFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationDependentRequired, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationDependentRequired, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only the keys of maps can depend on one another."},
+		})
+	}
+
+	dependencies := ann.(*DependentRequiredAnnotation).dependencies
+	for _, dependency := range dependencies {
+		err := checkKeysDeclared(node, mapType, ann, AnnotationDependentRequired, append([]string{dependency.key}, dependency.requires...))
+		if err != nil {
+			return err
+		}
+	}
+	mapType.dependentRequired = dependencies
+	return nil
+}
+
+// setDiscriminatorFromAnn records on `typeOfValue` (which must be a map) the key whose value tells apart its shapes, if
+// `node` is annotated with @schema/discriminator. That key must be one of the map's, and its value a string.
+func setDiscriminatorFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationDiscriminator, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationDiscriminator, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps can be told apart by the value of one of their keys."},
+		})
+	}
+
+	key := ann.(*DiscriminatorAnnotation).key
+	if err := checkKeysDeclared(node, mapType, ann, AnnotationDiscriminator, []string{key}); err != nil {
+		return err
+	}
+	itemType := mapType.findItem(key)
+	if scalarType, isScalar := itemType.GetValueType().(*ScalarType); !isScalar || scalarType.ValueType != StringType {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     itemType.GetDefinitionPosition(),
+			description:  fmt.Sprintf("non-string key in @%v", AnnotationDiscriminator),
+			expected:     "string",
+			found:        itemType.GetValueType().String(),
+			hints:        []string{"the value of a discriminator names the shape of the map, so must be a string."},
+		})
+	}
+	mapType.discriminator = key
+	return nil
+}
+
+// setConditionFromAnn records on `typeOfValue` (which must be a map) the keys it requires under a condition, if `node`
+// is annotated with @schema/when. Each key named must be one of the map's.
+func setConditionFromAnn(node yamlmeta.Node, typeOfValue Type) error {
+	ann, err := processOptionalAnnotation(node, AnnotationWhen, nil)
+	if err != nil {
+		return NewSchemaError("Invalid schema", err)
+	}
+	if ann == nil {
+		return nil
+	}
+	mapType, ok := typeOfValue.(*MapType)
+	if nullType, isNullable := typeOfValue.(*NullType); isNullable {
+		mapType, ok = nullType.GetValueType().(*MapType)
+	}
+	if !ok {
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("@%v not supported on values of type %s", AnnotationWhen, typeOfValue.String()),
+			expected:     "map",
+			found:        typeOfValue.String(),
+			hints:        []string{"only maps can require keys depending on the value of another."},
+		})
+	}
+
+	condition := ann.(*WhenAnnotation).condition
+	err = checkKeysDeclared(node, mapType, ann, AnnotationWhen, append([]string{condition.key}, condition.requires...))
+	if err != nil {
+		return err
+	}
+	mapType.condition = condition
+	return nil
+}
+
+// checkKeysDeclared reports the first of `keys` (named by `ann`) that is not one of the keys of `mapType`.
+func checkKeysDeclared(node yamlmeta.Node, mapType *MapType, ann Annotation, annName template.AnnotationName, keys []string) error {
+	for _, key := range keys {
+		if mapType.findItem(key) != nil {
+			continue
 		}
+		var declared []string
+		for _, item := range mapType.Items {
+			declared = append(declared, fmt.Sprintf("%v", item.Key))
+		}
+		return NewSchemaError("Invalid schema", schemaAssertionError{
+			annPositions: []*filepos.Position{ann.GetPosition()},
+			position:     node.GetPosition(),
+			description:  fmt.Sprintf("unknown key in @%v", annName),
+			expected:     fmt.Sprintf("one of the keys of the map: %s", strings.Join(declared, ", ")),
+			found:        key,
+		})
 	}
 	return nil
 }
@@ -116,8 +116,15 @@ func (a *ArrayType) AssignTypeTo(node yamlmeta.Node) TypeCheck {
 		return chk
 	}
 	SetType(node, a)
-	for _, arrayItem := range arrayNode.Items {
-		childCheck := a.ItemsType.AssignTypeTo(arrayItem)
+	for i, arrayItem := range arrayNode.Items {
+		itemsType := a.ItemsType
+		if a.tupleItems != nil {
+			if i >= len(a.tupleItems) {
+				break // items beyond those of the tuple are reported when checked.
+			}
+			itemsType = a.tupleItems[i]
+		}
+		childCheck := itemsType.AssignTypeTo(arrayItem)
 		chk.Violations = append(chk.Violations, childCheck.Violations...)
 	}
 	return chk
@@ -5,6 +5,7 @@ package schema
 
 import (
 	"fmt"
+	"math"
 
 	"carvel.dev/ytt/pkg/yamlmeta"
 )
@@ -157,11 +158,22 @@ func (t *MapItemType) CheckType(node yamlmeta.Node) TypeCheck {
 // CheckType checks the type of `node` against this ArrayType
 //
 // If `node` is not a yamlmeta.Array, `chk` contains a violation describing the mismatch
+// If this ArrayType is a tuple and `node` has some other number of items, `chk` contains a violation describing this
 func (a *ArrayType) CheckType(node yamlmeta.Node) TypeCheck {
 	chk := TypeCheck{}
-	_, ok := node.(*yamlmeta.Array)
+	arrayNode, ok := node.(*yamlmeta.Array)
 	if !ok {
 		chk.Violations = append(chk.Violations, NewMismatchedTypeAssertionError(node, a))
+		return chk
+	}
+	if a.tupleItems != nil && len(arrayNode.Items) != len(a.tupleItems) {
+		chk.Violations = append(chk.Violations, schemaAssertionError{
+			position:    node.GetPosition(),
+			description: "wrong number of items in tuple",
+			expected:    fmt.Sprintf("%d array items (by %s)", len(a.tupleItems), a.Position.AsCompactString()),
+			found:       fmt.Sprintf("%d array items", len(arrayNode.Items)),
+			hints:       []string{"to replace the items of a tuple (rather than append to them), annotate with @overlay/replace."},
+		})
 	}
 	return chk
 }
@@ -191,20 +203,28 @@ func (a *ArrayItemType) CheckType(node yamlmeta.Node) TypeCheck {
 //
 // If the value is not a recognized scalar type, `chk` contains a corresponding violation
 // If the value is not of the type specified in this ScalarType, `chk` contains a violation describing the mismatch
+// (a number given a type via @schema/type is of that type: "integer" allows only whole floats, "number" any float).
 func (s *ScalarType) CheckType(node yamlmeta.Node) TypeCheck {
 	chk := TypeCheck{}
 	if len(node.GetValues()) < 1 {
 		panic(fmt.Sprintf("Expected a node that could hold a scalar value, but was %#v", node))
 	}
 	value := node.GetValues()[0]
-	switch value.(type) {
+	switch typedValue := value.(type) {
 	case string:
 		if s.ValueType != StringType {
 			chk.Violations = append(chk.Violations, NewMismatchedTypeAssertionError(node, s))
 		}
 	case float64:
-		if s.ValueType != FloatType {
+		switch {
+		case s.ValueType != FloatType && s.typeName != "number":
 			chk.Violations = append(chk.Violations, NewMismatchedTypeAssertionError(node, s))
+		case s.typeName == "integer" && typedValue != math.Trunc(typedValue):
+			chk.Violations = append(chk.Violations, schemaAssertionError{
+				position: node.GetPosition(),
+				expected: fmt.Sprintf("integer (by %s)", s.GetDefinitionPosition().AsCompactString()),
+				found:    "float",
+			})
 		}
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		if s.ValueType != IntType && s.ValueType != FloatType {
@@ -26,6 +26,7 @@ of values.
 # Other Schema Formats
 
 Like other Carvel tools, ytt aims to interoperate well with other tooling. In
-this vein, ytt can export schema defined within ytt as an OpenAPI v3 document.
+this vein, ytt can export schema defined within ytt as an OpenAPI v3 or a JSON Schema
+(draft 2020-12) document.
 */
 package schema
@@ -0,0 +1,156 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"carvel.dev/ytt/pkg/filepos"
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// WithKeysOf produces a DocumentType identical to this one, except extended so that it accepts the shape of `doc`
+// (e.g. data values, once overlaid): each key of a map in `doc` that is not declared by the corresponding map in this
+// schema is declared (its type inferred from its value in `doc`, which becomes its default).
+//
+// Keys already declared keep their type (and default); values of the wrong type are left for the type check to report.
+// Maps that allow extra properties already accept any key, and are not extended. This DocumentType is left as it is:
+// only the types along the way to a key declared are copied (the others being shared).
+func (t *DocumentType) WithKeysOf(doc *yamlmeta.Document) (*DocumentType, error) {
+	valueType, err := withKeysOf(t.GetValueType(), doc.Value)
+	if err != nil {
+		return nil, err
+	}
+	extended := *t
+	extended.ValueType = valueType
+	return &extended, nil
+}
+
+// withKeysOf gives `typ` extended to accept the keys of `value` (see WithKeysOf()); `typ` itself, if none are lacking.
+func withKeysOf(typ Type, value interface{}) (Type, error) {
+	switch typedType := typ.(type) {
+	case *NullType:
+		valueType, err := withKeysOf(typedType.GetValueType(), value)
+		if err != nil || valueType == typedType.GetValueType() {
+			return typ, err
+		}
+		extended := *typedType
+		extended.ValueType = valueType
+		return &extended, nil
+
+	case *MapType:
+		mapVal, ok := value.(*yamlmeta.Map)
+		if !ok {
+			return typ, nil
+		}
+		items := append([]*MapItemType{}, typedType.Items...)
+		extendedItems := false
+		for _, item := range mapVal.Items {
+			if i := indexOfItem(items, item.Key); i >= 0 {
+				valueType, err := withKeysOf(items[i].GetValueType(), item.Value)
+				if err != nil {
+					return nil, err
+				}
+				if valueType != items[i].GetValueType() {
+					extendedItem := *items[i]
+					extendedItem.ValueType = valueType
+					items[i], extendedItems = &extendedItem, true
+				}
+				continue
+			}
+			if typedType.allowExtraProperties || typedType.patternProperty.matches(item.Key) {
+				continue
+			}
+			itemType, err := mapItemTypeOf(item)
+			if err != nil {
+				return nil, err
+			}
+			items, extendedItems = append(items, itemType), true
+		}
+		if !extendedItems {
+			return typ, nil
+		}
+		extended := *typedType
+		extended.Items = items
+		return &extended, nil
+
+	case *ArrayType:
+		arrayVal, ok := value.(*yamlmeta.Array)
+		if !ok {
+			return typ, nil
+		}
+		itemType := typedType.GetValueType().(*ArrayItemType)
+		valueType := itemType.GetValueType()
+		for _, item := range arrayVal.Items {
+			var err error
+			valueType, err = withKeysOf(valueType, item.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if valueType == itemType.GetValueType() {
+			return typ, nil
+		}
+		extendedItem := *itemType
+		extendedItem.ValueType = valueType
+		extended := *typedType
+		extended.ItemsType = &extendedItem
+		return &extended, nil
+	}
+	return typ, nil
+}
+
+func indexOfItem(items []*MapItemType, key interface{}) int {
+	for i, item := range items {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mapItemTypeOf infers the type of a key from its value (see typeOfValue()).
+func mapItemTypeOf(item *yamlmeta.MapItem) (*MapItemType, error) {
+	valueType, err := typeOfValue(item.Value, item.Position)
+	if err != nil {
+		return nil, err
+	}
+	return &MapItemType{Key: item.Key, ValueType: valueType, defaultValue: valueType.GetDefaultValue(), Position: item.Position}, nil
+}
+
+// typeOfValue infers the type of `value` as for schema (see InferTypeFromValue()), except that any value is
+// permitted: a null is of any type, as are the items of an empty array; the items of other arrays are of the type of
+// the first one.
+func typeOfValue(value interface{}, position *filepos.Position) (Type, error) {
+	switch typedValue := value.(type) {
+	case *yamlmeta.Map:
+		mapType := &MapType{Position: typedValue.Position}
+		for _, item := range typedValue.Items {
+			itemType, err := mapItemTypeOf(item)
+			if err != nil {
+				return nil, err
+			}
+			mapType.Items = append(mapType.Items, itemType)
+		}
+		return mapType, nil
+
+	case *yamlmeta.Array:
+		var itemsType Type = &AnyType{Position: typedValue.Position}
+		itemPosition := typedValue.Position
+		if len(typedValue.Items) > 0 {
+			itemPosition = typedValue.Items[0].Position
+			var err error
+			itemsType, err = typeOfValue(typedValue.Items[0].Value, itemPosition)
+			if err != nil {
+				return nil, err
+			}
+		}
+		itemType := &ArrayItemType{ValueType: itemsType, defaultValue: itemsType.GetDefaultValue(), Position: itemPosition}
+		return &ArrayType{ItemsType: itemType, defaultValue: &yamlmeta.Array{}, Position: typedValue.Position}, nil
+
+	case nil:
+		return &AnyType{Position: position}, nil
+
+	default:
+		return InferTypeFromValue(value, position)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"carvel.dev/ytt/pkg/filepos"
+)
+
+// knownFormats lists the formats of strings that can be given via @schema/format: those defined by JSON Schema and
+// (for strings) by OpenAPI v3.0.
+//
+// Formats are exported as named, whichever the draft: those added since draft-07 or draft-04 (e.g. "uuid") have no
+// other name there, and validators of those drafts ignore formats they do not know.
+var knownFormats = []string{
+	"date-time", "date", "time", "duration",
+	"email", "idn-email", "hostname", "idn-hostname", "ipv4", "ipv6",
+	"uri", "uri-reference", "iri", "iri-reference", "uri-template", "uuid",
+	"json-pointer", "relative-json-pointer", "regex",
+	"byte", "binary", "password",
+}
+
+// integerFormats lists the formats of integers that can be given via @schema/format: the widths defined by OpenAPI
+// v3.0 (e.g. for clients generated from the schema to hold such values in integers of that width).
+var integerFormats = []string{"int32", "int64"}
+
+// integerFormatBounds holds the least and greatest integers of each of integerFormats (see FormatBounds).
+var integerFormatBounds = map[string][2]int64{
+	"int32": {math.MinInt32, math.MaxInt32},
+	"int64": {math.MinInt64, math.MaxInt64},
+}
+
+// CheckFormats reports the first string in `docType` given a format (via @schema/format) that is not one of
+// knownFormats, unless `allowCustom` is set.
+func CheckFormats(docType *DocumentType, allowCustom bool) error {
+	if allowCustom {
+		return nil
+	}
+	return checkFormatsIn(docType)
+}
+
+func checkFormatsIn(typ Type) error {
+	switch typedValue := typ.(type) {
+	case *DocumentType, *MapItemType, *ArrayItemType, *NullType:
+		return checkFormatsIn(typedValue.GetValueType())
+	case *MapType:
+		for _, item := range typedValue.Items {
+			if err := checkFormatsIn(item); err != nil {
+				return err
+			}
+		}
+		if typedValue.extraPropertiesType != nil {
+			return checkFormatsIn(typedValue.extraPropertiesType)
+		}
+	case *ArrayType:
+		for _, itemType := range typedValue.itemTypes() {
+			if err := checkFormatsIn(itemType); err != nil {
+				return err
+			}
+		}
+	case *ScalarType:
+		if typedValue.format != nil && !isKnownFormat(typedValue.format.format) && !isIntegerFormat(typedValue.format.format) {
+			return NewSchemaError("Invalid schema", schemaAssertionError{
+				annPositions: []*filepos.Position{typedValue.format.pos},
+				position:     typedValue.GetDefinitionPosition(),
+				description:  fmt.Sprintf("unknown format in @%v", AnnotationFormat),
+				expected:     fmt.Sprintf("one of: %s", strings.Join(knownFormats, ", ")),
+				found:        typedValue.format.format,
+				hints:        []string{"to give a format of your own, allow custom formats (i.e. --allow-custom-formats)."},
+			})
+		}
+	}
+	return nil
+}
+
+func isKnownFormat(format string) bool {
+	for _, known := range knownFormats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isIntegerFormat(format string) bool {
+	for _, known := range integerFormats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+// emailPattern loosely matches an email address (i.e. "local-part@domain", the domain having at least one dot).
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// inferFormat guesses the format of a string from `defaultValue`: "date-time" for an RFC 3339 timestamp, "email" for
+// an email address, "uri" for an absolute URL. Returns "" when the value has none of those shapes (or is not a string).
+func inferFormat(defaultValue interface{}) string {
+	str, ok := defaultValue.(string)
+	if !ok || str == "" {
+		return ""
+	}
+	if _, err := time.Parse(time.RFC3339, str); err == nil {
+		return "date-time"
+	}
+	if emailPattern.MatchString(str) {
+		return "email"
+	}
+	if u, err := url.Parse(str); err == nil && u.Scheme != "" && u.Host != "" {
+		return "uri"
+	}
+	return ""
+}
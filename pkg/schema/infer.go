@@ -0,0 +1,75 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"carvel.dev/ytt/pkg/filepos"
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// InferDocumentType infers a best-effort type of `doc` (e.g. data values given without a schema) from the shape of its
+// value: scalars are typed by their kind, maps and arrays recursively, each defaulting to its value in `doc` (arrays,
+// as in schema, to an empty one). As
+// `doc` is only an instance of the values it describes, every value within it is nullable (so that, when exported,
+// none but those of nulls is required: see inferNullableType()).
+//
+// A null, or the items of an empty array, are of any type; as are the items of an array that are not all alike (i.e.
+// whose types cannot be merged, see MergeTypes()).
+func InferDocumentType(doc *yamlmeta.Document) *DocumentType {
+	valueType := inferType(doc.Value, doc.Position)
+	return &DocumentType{Source: doc, Position: doc.Position, ValueType: valueType, defaultValue: valueType.GetDefaultValue()}
+}
+
+// inferNullableType infers the type of `value`, declared at `position`, as nullable; unless of any type (i.e. a null),
+// which already permits null (though, having no default, it is required when exported).
+func inferNullableType(value interface{}, position *filepos.Position) Type {
+	valueType := inferType(value, position)
+	if _, isAny := valueType.(*AnyType); isAny {
+		return valueType
+	}
+	return &NullType{ValueType: valueType, Position: position}
+}
+
+// inferType infers the type of `value`, declared at `position` (the values within being nullable).
+func inferType(value interface{}, position *filepos.Position) Type {
+	switch typedValue := value.(type) {
+	case *yamlmeta.Map:
+		mapType := &MapType{Position: typedValue.Position}
+		for _, item := range typedValue.Items {
+			itemType := inferNullableType(item.Value, item.Position)
+			mapType.Items = append(mapType.Items, &MapItemType{Key: item.Key, ValueType: itemType, defaultValue: itemType.GetDefaultValue(), Position: item.Position})
+		}
+		return mapType
+
+	case *yamlmeta.Array:
+		var itemsType Type
+		itemPosition := typedValue.Position
+		for i, item := range typedValue.Items {
+			itemType := inferNullableType(item.Value, item.Position)
+			if i == 0 {
+				itemsType, itemPosition = itemType, item.Position
+				continue
+			}
+			merged, err := MergeTypes(itemsType, itemType)
+			if err != nil {
+				itemsType = nil
+				break
+			}
+			itemsType = merged
+		}
+		if itemsType == nil {
+			itemsType = &AnyType{Position: itemPosition}
+		}
+		itemType := &ArrayItemType{ValueType: itemsType, defaultValue: itemsType.GetDefaultValue(), Position: itemPosition}
+		return &ArrayType{ItemsType: itemType, defaultValue: &yamlmeta.Array{}, Position: typedValue.Position}
+
+	default:
+		scalarType, err := InferTypeFromValue(value, position)
+		if err != nil || scalarType == nil {
+			// a null (or a value of no known kind) could be of any type.
+			return &AnyType{Position: position}
+		}
+		return scalarType
+	}
+}
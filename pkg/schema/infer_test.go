@@ -0,0 +1,135 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"testing"
+
+	"carvel.dev/ytt/pkg/schema"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferDocumentType(t *testing.T) {
+	jsonSchemaOf := func(t *testing.T, valuesYAML string) string {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(valuesYAML), yamlmeta.DocSetOpts{AssociatedName: "values.yml"})
+		require.NoError(t, err)
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(schema.InferDocumentType(docSet.Items[0]), schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		bs, err := jsonSchemaDoc.AsDocument().AsYAMLBytes()
+		require.NoError(t, err)
+		return string(bs)
+	}
+
+	t.Run("types nested maps, every value nullable", func(t *testing.T) {
+		require.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type:
+    - string
+    - "null"
+    default: app
+  db:
+    type:
+    - object
+    - "null"
+    additionalProperties: false
+    properties:
+      port:
+        type:
+        - integer
+        - "null"
+        default: 5432
+      tls:
+        type:
+        - boolean
+        - "null"
+        default: true
+`, jsonSchemaOf(t, `
+name: app
+db:
+  port: 5432
+  tls: true
+`))
+	})
+	t.Run("types arrays of scalars by their items", func(t *testing.T) {
+		require.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  hosts:
+    type:
+    - array
+    - "null"
+    items:
+      type:
+      - string
+      - "null"
+      default: a.com
+    default: []
+`, jsonSchemaOf(t, `
+hosts:
+- a.com
+- b.com
+`))
+	})
+	t.Run("types the items of arrays as the merge of each, if they can be merged", func(t *testing.T) {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(`
+servers:
+- host: a.com
+- port: 443
+`), yamlmeta.DocSetOpts{AssociatedName: "values.yml"})
+		require.NoError(t, err)
+		docType := schema.InferDocumentType(docSet.Items[0])
+
+		counter := &scalarCounter{}
+		require.NoError(t, docType.Walk(counter))
+		require.Equal(t, []string{"servers[].host", "servers[].port"}, counter.paths)
+	})
+	t.Run("types the items of arrays of mixed types, and nulls, as of any type", func(t *testing.T) {
+		require.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  mixed:
+    type:
+    - array
+    - "null"
+    items:
+      type:
+      - "null"
+      - string
+      - integer
+      - number
+      - object
+      - array
+      - boolean
+      default: null
+    default: []
+  password:
+    type:
+    - "null"
+    - string
+    - integer
+    - number
+    - object
+    - array
+    - boolean
+    default: null
+required:
+- password
+`, jsonSchemaOf(t, `
+mixed:
+- a.com
+- 443
+password: null
+`))
+	})
+}
@@ -0,0 +1,1364 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"carvel.dev/ytt/pkg/orderedmap"
+	"carvel.dev/ytt/pkg/validations"
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// keys used when generating a JSON Schema Document
+const (
+	schemaProp   = "$schema"
+	idProp       = "$id"
+	vocabProp    = "$vocabulary"
+	id04Prop     = "id"
+	anyOfProp    = "anyOf"
+	allOfProp    = "allOf"
+	patternProp  = "pattern"
+	requiredProp = "required"
+	refProp      = "$ref"
+	defsProp     = "$defs"
+	defs07Prop   = "definitions"
+	oneOfProp    = "oneOf"
+	notProp      = "not"
+	examplesProp = "examples"
+	constProp    = "const"
+	commentProp  = "$comment"
+
+	propertyNamesProp     = "propertyNames"
+	patternPropertiesProp = "patternProperties"
+	anchorProp            = "$anchor"
+
+	dependentRequiredProp = "dependentRequired"
+	dependenciesProp      = "dependencies" // draft-07's (and draft-04's) equivalent of "dependentRequired"
+	ifProp                = "if"
+	thenProp              = "then"
+
+	contentEncodingProp  = "contentEncoding"
+	contentMediaTypeProp = "contentMediaType"
+
+	containsProp    = "contains"
+	minContainsProp = "minContains"
+	maxContainsProp = "maxContains"
+
+	prefixItemsProp     = "prefixItems"
+	additionalItemsProp = "additionalItems" // draft-07's (and draft-04's) equivalent of "items" following "prefixItems"
+)
+
+// Supported versions ("drafts") of the JSON Schema specification
+const (
+	JSONSchemaDraft202012 = "2020-12"
+	JSONSchemaDraft07     = "draft-07"
+	JSONSchemaDraft04     = "draft-04"
+)
+
+// JSONSchemaDrafts lists all supported JSON Schema drafts, the first being the default.
+var JSONSchemaDrafts = []string{JSONSchemaDraft202012, JSONSchemaDraft07, JSONSchemaDraft04}
+
+// Interpretations of null, telling apart a nullable key given as null from one that is absent (see JSONSchemaOpts.Nulls)
+const (
+	JSONSchemaNullsLenient = "lenient" // a nullable key may be null or absent
+	JSONSchemaNullsStrict  = "strict"  // a nullable key must be present, though it may be null
+)
+
+// JSONSchemaNulls lists all supported interpretations of null.
+var JSONSchemaNulls = []string{JSONSchemaNullsLenient, JSONSchemaNullsStrict}
+
+var jsonSchemaDraftURIs = map[string]string{
+	JSONSchemaDraft202012: "https://json-schema.org/draft/2020-12/schema",
+	JSONSchemaDraft07:     "http://json-schema.org/draft-07/schema#",
+	JSONSchemaDraft04:     "http://json-schema.org/draft-04/schema#",
+}
+
+// jsonSchemaKeywordOrder arranges keywords when JSONSchemaOpts.PreserveOrder is set: what the value is about, what
+// type it is, how it is constrained, and only then what it contains.
+var jsonSchemaKeywordOrder = map[string]int{
+	refProp:               0,
+	idProp:                1,
+	id04Prop:              2,
+	anchorProp:            3,
+	titleProp:             4,
+	descriptionProp:       5,
+	commentProp:           6,
+	deprecatedProp:        7,
+	readOnlyProp:          8,
+	writeOnlyProp:         9,
+	examplesProp:          10,
+	typeProp:              11,
+	formatProp:            12,
+	contentEncodingProp:   13,
+	contentMediaTypeProp:  14,
+	enumProp:              15,
+	constProp:             16,
+	minProp:               17,
+	exclusiveMinProp:      18,
+	maxProp:               19,
+	exclusiveMaxProp:      20,
+	multipleOfProp:        21,
+	minLenProp:            22,
+	maxLenProp:            23,
+	patternProp:           24,
+	minItemsProp:          25,
+	maxItemsProp:          26,
+	uniqueItemsProp:       27,
+	containsProp:          28,
+	minContainsProp:       29,
+	maxContainsProp:       30,
+	minPropertiesProp:     31,
+	maxPropertiesProp:     32,
+	requiredProp:          33,
+	dependentRequiredProp: 34,
+	dependenciesProp:      35,
+	ifProp:                36,
+	thenProp:              37,
+	allOfProp:             38,
+	anyOfProp:             39,
+	notProp:               40,
+	defaultProp:           41,
+	additionalPropsProp:   42,
+	propertyNamesProp:     43,
+	propertiesProp:        44,
+	patternPropertiesProp: 45,
+	prefixItemsProp:       46,
+	itemsProp:             47,
+	additionalItemsProp:   48,
+	defsProp:              49,
+	defs07Prop:            50,
+}
+
+// JSONSchemaOpts configures the JSON Schema document generated from a DocumentType
+type JSONSchemaOpts struct {
+	// ID is the URI identifying the schema (i.e. `$id`); when empty, no `$id` is emitted.
+	ID string
+	// Vocabulary declares the vocabularies the schema relies on (i.e. `$vocabulary`): by URI, whether each is required
+	// (true) or optional (false) to process it. Only 2020-12 declares vocabularies; for other drafts, it is omitted.
+	Vocabulary map[string]bool
+	// Description describes the schema as a whole, unless the document is itself described (via @schema/desc); when
+	// empty, a generic description is given.
+	Description string
+	// Draft is the version of the JSON Schema specification to target; when empty, the first of JSONSchemaDrafts.
+	Draft string
+	// FloatFormat adds `format: float` to floating point numbers (as in OpenAPI), beyond them being of type "number".
+	FloatFormat bool
+	// NoRefs inlines every map, even those repeated throughout the schema (rather than referring to a single definition).
+	NoRefs bool
+	// TitlesFromKeys titles each property after its key (humanized), unless given a title via @schema/title.
+	TitlesFromKeys bool
+	// SortAnyTypes lists the types permitted for values of any type in alphabetical order (rather than "null" first).
+	SortAnyTypes bool
+	// PreserveOrder arranges keywords in reading order (documentation, type, constraints, then contents; see
+	// jsonSchemaKeywordOrder) rather than in the order shared with OpenAPI documents.
+	PreserveOrder bool
+	// NoConst gives a single allowed value (via `one_of`) as an "enum" of one, rather than as a "const".
+	NoConst bool
+	// SynthesizeDescriptions describes each undocumented property by its type and default (e.g. `string (default: "")`).
+	SynthesizeDescriptions bool
+	// InferFormats guesses the format of strings not given one via @schema/format, from their default (see
+	// inferFormat()).
+	InferFormats bool
+	// NoPasswordFormat omits the `format: password` otherwise given to write-only strings (see writeOnlyString()), so
+	// that forms mask them.
+	NoPasswordFormat bool
+	// NoDefaults omits every "default" (e.g. when the schema is only used to validate values, rather than to scaffold
+	// them).
+	NoDefaults bool
+	// RequiredAll requires every key of every map to be present (in a value), regardless of its default or whether it
+	// is nullable (rather than only those that requiredKeysOf() infers must be).
+	RequiredAll bool
+	// EnumRefs declares enums repeated throughout the schema (i.e. of the same values, in any order) once, among the
+	// definitions, and refers to them there (unless NoRefs is set). Only from draft 2019-09 on: before then, keywords
+	// alongside a reference are ignored.
+	EnumRefs bool
+	// UnitDescriptions appends the unit of each number given one via @schema/unit to its description (e.g. "Timeout
+	// (seconds)"), describing it by the unit alone if undocumented; the unit is given as "x-unit" either way.
+	UnitDescriptions bool
+	// DefaultsAsExamples gives each "default" as an example instead (the first of its "examples"), so that the schema
+	// only validates values: some validators fill in defaults, changing the values they validate. (Unlike NoDefaults,
+	// the defaults remain documented.)
+	DefaultsAsExamples bool
+	// ExamplesFromDefaults gives the default of each value not given examples via @schema/examples as its example
+	// (unless that default is null or an empty array, or the value is a map, whose default is given by its properties).
+	ExamplesFromDefaults bool
+	// DeprecatedFromValidations marks as deprecated each property with a validation whose message (of any rule)
+	// starts with "DEPRECATED:" (see deprecationPrefix), as though also annotated with @schema/deprecated.
+	DeprecatedFromValidations bool
+	// MergeValidationMessages appends to the description of each property what its validation requires of it (the
+	// message of each rule, e.g. "Must be a value >= 1 and a value <= 100."), describing it so if undocumented.
+	MergeValidationMessages bool
+	// MapDefaults gives each map a "default": the map of the defaults of its keys (those of nested maps included), as
+	// ytt would fill in. (Otherwise, only the items of an array default to such a map.)
+	MapDefaults bool
+	// FlattenSingleOneOf inlines each "oneOf", "anyOf" or "allOf" of a single subschema into the schema containing it
+	// (unless their keywords overlap, or, before draft 2019-09, the subschema is a reference alongside other keywords).
+	FlattenSingleOneOf bool
+	// Embedded omits the keywords identifying the document as a JSON Schema ("$schema", "$id" and the generic
+	// description), so that it can be embedded as a subschema of another. (References to repeated maps are relative
+	// to the document, though: to embed a schema with such maps, also set NoRefs.)
+	Embedded bool
+	// DropUnsupported omits from the export the keywords that the targeted draft does not define (e.g. "deprecated", in
+	// draft-07), rather than failing to export a schema using a feature the draft cannot express; features that it
+	// expresses otherwise are rewritten either way (see checkDraftSupport()).
+	DropUnsupported bool
+	// NullableStrings allows null for every string, as though each were annotated with @schema/nullable (e.g. to adopt a
+	// schema for configuration in which strings may have been left empty as null); other types are left as they are.
+	NullableStrings bool
+	// Nulls, when given (one of JSONSchemaNulls), expresses each nullable value as a choice ("anyOf") between its type
+	// and null, which validators understand alike (unlike a list of types), and tells whether a nullable key may be
+	// absent ("lenient") or must be present, if only as null ("strict": the key is required). Otherwise, nullable keys
+	// may be absent, and the "null" type is added to those of the value where the draft permits.
+	Nulls string
+	// FormatBounds bounds each integer given a width via @schema/format (e.g. "int32") by the least and greatest
+	// integers of that width (as "minimum" and "maximum"), unless bounded via `min` or `max`.
+	FormatBounds bool
+	// EnumLengths bounds the length of strings allowed via `one_of` by that of their shortest and longest values (as
+	// "minLength" and "maxLength", redundant with the "enum" but checked by validators that only check lengths), unless
+	// bounded via `min_len` or `max_len`.
+	EnumLengths bool
+	// KeywordLess, when given, orders the keywords of each schema (in place of DefaultKeywordLess or, if PreserveOrder
+	// is set, jsonSchemaKeywordOrder); keywords it considers equal keep the order in which they were generated.
+	KeywordLess func(keyword, otherKeyword string) bool
+	// KeyRenamer, when given, rewrites each (string) key of a map wherever it is named: as a property, and in the keys
+	// required (e.g. to give snake_case keys in camelCase, as some tools expect). Keywords are left as they are.
+	KeyRenamer func(key string) string
+}
+
+// JSONSchemaDocument holds the document type used for creating a JSON Schema document
+type JSONSchemaDocument struct {
+	*OpenAPIDocument
+	opts JSONSchemaOpts
+	defs *schemaDefs
+
+	defsPrefix string // distinguishes the definitions of this document from those of others in the same schema
+}
+
+// NewJSONSchemaDocument creates an instance of a JSONSchemaDocument based on the given DocumentType
+//
+// Returns an error if `opts` targets an unsupported draft, if the same schema name (see @schema/schema-name) is given
+// to maps that differ, if arrays are required to contain a number of some item (via contains=) in a draft preceding
+// 2019-09, or (unless `opts` is DropUnsupported) if the schema uses a feature the targeted draft cannot express.
+func NewJSONSchemaDocument(docType *DocumentType, opts JSONSchemaOpts) (*JSONSchemaDocument, error) {
+	if opts.Draft == "" {
+		opts.Draft = JSONSchemaDrafts[0]
+	}
+	if _, found := jsonSchemaDraftURIs[opts.Draft]; !found {
+		return nil, fmt.Errorf("Unknown JSON Schema draft '%s' (supported drafts: %s)", opts.Draft, strings.Join(JSONSchemaDrafts, ", "))
+	}
+	if opts.Nulls != "" && opts.Nulls != JSONSchemaNullsLenient && opts.Nulls != JSONSchemaNullsStrict {
+		return nil, fmt.Errorf("Unknown interpretation of nulls '%s' (supported: %s)", opts.Nulls, strings.Join(JSONSchemaNulls, ", "))
+	}
+	doc := &JSONSchemaDocument{OpenAPIDocument: NewOpenAPIDocument(docType), opts: opts}
+	if err := doc.checkSchemaNames(); err != nil {
+		return nil, err
+	}
+	if err := doc.checkContainsSupport(); err != nil {
+		return nil, err
+	}
+	if !opts.DropUnsupported {
+		if err := doc.checkDraftSupport(); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// AsDocument generates a new AST of this JSON Schema document, describing the type information contained in
+// `docType` at the root of the document.
+//
+// Maps that are repeated (identically) are declared once, in the definitions section, and referred to wherever they
+// occur (unless `NoRefs` is set).
+func (j *JSONSchemaDocument) AsDocument() *yamlmeta.Document {
+	jsonSchemaProperties := j.asSchema()
+	if defs := j.defs.asMap(); defs != nil {
+		jsonSchemaProperties.Items = append(jsonSchemaProperties.Items, &yamlmeta.MapItem{Key: j.defsKey(), Value: defs})
+	}
+	j.rewriteGenerated(jsonSchemaProperties)
+	return &yamlmeta.Document{Value: j.withMetaKeywords(jsonSchemaProperties, "Schema for data values, generated by ytt")}
+}
+
+// JSONSchemaTransform tweaks a generated JSON Schema document in place (e.g. to inject vendor extensions).
+type JSONSchemaTransform func(doc *yamlmeta.Document) error
+
+// AsDocumentWithTransforms generates a new AST of this JSON Schema document (see AsDocument()), then applies each of
+// `transforms` to it, in order.
+//
+// Returns the error of the first transform to fail (the others are not applied).
+func (j *JSONSchemaDocument) AsDocumentWithTransforms(transforms ...JSONSchemaTransform) (*yamlmeta.Document, error) {
+	doc := j.AsDocument()
+	for i, transform := range transforms {
+		if err := transform(doc); err != nil {
+			return nil, fmt.Errorf("Transforming JSON Schema (by transform %d of %d): %s", i+1, len(transforms), err)
+		}
+	}
+	return doc, nil
+}
+
+// asSchema describes `docType`, collecting the definitions of repeated maps (unless `NoRefs` is set) along the way.
+func (j *JSONSchemaDocument) asSchema() *yamlmeta.Map {
+	j.defs = nil
+	if !j.opts.NoRefs {
+		j.defs = j.findRepeatedMapTypes()
+	}
+	return j.calculateProperties(j.docType)
+}
+
+// withMetaKeywords prefixes `schema` with the keywords identifying it as a JSON Schema (and, when it has no
+// description of its own, with the configured Description or else `description`), unless Embedded is set.
+func (j *JSONSchemaDocument) withMetaKeywords(schema *yamlmeta.Map, description string) *yamlmeta.Map {
+	if j.opts.Embedded {
+		return schema
+	}
+	metaItems := []*yamlmeta.MapItem{
+		{Key: schemaProp, Value: jsonSchemaDraftURIs[j.opts.Draft]},
+	}
+	if j.opts.ID != "" {
+		metaItems = append(metaItems, &yamlmeta.MapItem{Key: j.idKey(), Value: j.opts.ID})
+	}
+	if len(j.opts.Vocabulary) > 0 && j.opts.Draft == JSONSchemaDraft202012 {
+		metaItems = append(metaItems, &yamlmeta.MapItem{Key: vocabProp, Value: j.vocabulary()})
+	}
+	if !hasKey(schema, descriptionProp) {
+		if j.opts.Description != "" {
+			description = j.opts.Description
+		}
+		metaItems = append(metaItems, &yamlmeta.MapItem{Key: descriptionProp, Value: description})
+	}
+	schema.Items = append(metaItems, schema.Items...)
+	return schema
+}
+
+// vocabulary lists the vocabularies of Vocabulary, by URI (in sorted order, as Vocabulary is not ordered).
+func (j *JSONSchemaDocument) vocabulary() *yamlmeta.Map {
+	var uris []string
+	for uri := range j.opts.Vocabulary {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	vocabulary := &yamlmeta.Map{}
+	for _, uri := range uris {
+		vocabulary.Items = append(vocabulary.Items, &yamlmeta.MapItem{Key: uri, Value: j.opts.Vocabulary[uri]})
+	}
+	return vocabulary
+}
+
+func (j *JSONSchemaDocument) calculateProperties(schemaVal interface{}) *yamlmeta.Map {
+	switch typedValue := schemaVal.(type) {
+	case *DocumentType:
+		keywords := append(j.convertValidations(typedValue), j.formatBoundKeywords(typedValue)...)
+		result := j.withKeywords(j.calculateProperties(typedValue.GetValueType()), keywords)
+		j.orderKeywords(result.Items)
+		return result
+
+	case *MapType:
+		if typedValue.externalRef != "" {
+			return &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: refProp, Value: typedValue.externalRef}}}
+		}
+		if typedValue.unwrapped && len(typedValue.Items) == 1 {
+			// the wrapper (see @schema/unwrap) is left out: its only key is described in its place.
+			return j.calculateProperties(typedValue.Items[0])
+		}
+		var items openAPIKeys
+		items = append(items, j.collectDocumentation(typedValue)...)
+		items = append(items, j.convertValidations(typedValue)...)
+		if j.opts.MapDefaults {
+			items = append(items, j.defaultKeyword(typedValue.GetDefaultValue())...)
+		}
+		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: "object"})
+		if typedValue.schemaName != "" && !j.opts.NoRefs {
+			items = append(items, j.anchorKeywords(typedValue.schemaName, typedValue.schemaID)...)
+		}
+		if !typedValue.noAdditionalPropsKey {
+			items = append(items, &yamlmeta.MapItem{Key: additionalPropsProp, Value: j.additionalPropertiesOf(typedValue)})
+		}
+		if typedValue.keyPattern != "" {
+			keySchema := &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: patternProp, Value: typedValue.keyPattern}}}
+			items = append(items, &yamlmeta.MapItem{Key: propertyNamesProp, Value: keySchema})
+		}
+
+		var properties []*yamlmeta.MapItem
+		for _, i := range typedValue.Items {
+			mi := yamlmeta.MapItem{Key: j.renamedKey(i.Key), Value: j.calculateProperties(i)}
+			properties = append(properties, &mi)
+		}
+		items = append(items, &yamlmeta.MapItem{Key: propertiesProp, Value: &yamlmeta.Map{Items: properties}})
+		if property := typedValue.patternProperty; property != nil {
+			patternProperties := &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+				{Key: property.pattern, Value: j.calculateProperties(property.valueType)},
+			}}
+			items = append(items, &yamlmeta.MapItem{Key: patternPropertiesProp, Value: patternProperties})
+		}
+		if required := j.requiredKeysOf(typedValue); len(required) > 0 {
+			var requiredNames []interface{}
+			for _, key := range required {
+				requiredNames = append(requiredNames, j.renamedKey(key))
+			}
+			// (renaming keys need not preserve their order)
+			sortKeys(requiredNames)
+			items = append(items, &yamlmeta.MapItem{Key: requiredProp, Value: requiredNames})
+		}
+		if len(typedValue.dependentRequired) > 0 {
+			items = append(items, j.dependentRequiredKeyword(typedValue.dependentRequired))
+		}
+		if typedValue.condition != nil {
+			items = append(items, j.conditionKeywords(typedValue.condition)...)
+		}
+
+		j.orderKeywords(items)
+		return j.referenceIfRepeated(typedValue, &yamlmeta.Map{Items: items})
+
+	case *MapItemType:
+		properties := j.calculateProperties(typedValue.GetValueType())
+		keywords := append(j.convertValidations(typedValue), j.formatBoundKeywords(typedValue)...)
+		// the external schema of a map documents it (see @schema/external-ref).
+		documented := !isExternalRef(typedValue.GetValueType())
+		if j.opts.TitlesFromKeys && documented && typedValue.GetValueType().GetTitle() == "" {
+			keywords = append(keywords, &yamlmeta.MapItem{Key: titleProp, Value: humanizeKey(typedValue.Key)})
+		}
+		if j.opts.DeprecatedFromValidations && !hasKey(properties, deprecatedProp) && isDeprecatedByValidation(typedValue) {
+			keywords = append(keywords, &yamlmeta.MapItem{Key: deprecatedProp, Value: true})
+		}
+		if j.opts.SynthesizeDescriptions && documented && !hasKey(properties, descriptionProp) {
+			keywords = append(keywords, &yamlmeta.MapItem{Key: descriptionProp, Value: j.synthesizeDescription(typedValue.GetValueType())})
+		}
+		result := j.withKeywords(properties, keywords)
+		if j.opts.MergeValidationMessages && documented {
+			mergeValidationMessages(result, typedValue.GetValidation())
+		}
+		j.orderKeywords(result.Items)
+		return result
+
+	case *ArrayType:
+		var items openAPIKeys
+		items = append(items, j.collectDocumentation(typedValue)...)
+		items = append(items, j.defaultKeyword(typedValue.GetDefaultValue())...)
+
+		var arrayKeywords openAPIKeys
+		arrayKeywords = append(arrayKeywords, j.convertValidations(typedValue)...)
+		arrayKeywords = append(arrayKeywords, &yamlmeta.MapItem{Key: typeProp, Value: "array"})
+
+		if typedValue.tupleItems != nil {
+			arrayKeywords = append(arrayKeywords, j.tupleKeywords(typedValue, arrayKeywords)...)
+			items = append(items, arrayKeywords...)
+			j.orderKeywords(items)
+			return &yamlmeta.Map{Items: items}
+		}
+
+		// the default of each item (e.g. of a map, the defaults of its keys) helps tools scaffold new items.
+		valueType := typedValue.GetValueType().(*ArrayItemType)
+		properties := j.withKeywords(j.calculateProperties(valueType.GetValueType()), j.formatBoundKeywords(valueType))
+		if !hasKey(properties, defaultProp) {
+			properties = j.withKeywords(properties, j.defaultKeyword(valueType.GetValueType().GetDefaultValue()))
+			j.orderKeywords(properties.Items)
+		}
+		if condition := discriminatedCondition(valueType.GetValueType()); condition != nil {
+			properties = j.withKeywords(properties, j.conditionKeywords(condition))
+			j.orderKeywords(properties.Items)
+		}
+		arrayKeywords = append(arrayKeywords, &yamlmeta.MapItem{Key: itemsProp, Value: properties})
+
+		if typedValue.scalarOrArray {
+			// either a single item, or the array of them (see @schema/scalar-or-array).
+			j.orderKeywords(arrayKeywords)
+			items = append(items, &yamlmeta.MapItem{Key: anyOfProp, Value: []interface{}{properties, &yamlmeta.Map{Items: arrayKeywords}}})
+		} else {
+			items = append(items, arrayKeywords...)
+		}
+
+		j.orderKeywords(items)
+		return &yamlmeta.Map{Items: items}
+
+	case *ScalarType:
+		if j.opts.NullableStrings && j.isString(typedValue) {
+			return j.calculateProperties(&NullType{ValueType: typedValue, Position: typedValue.Position})
+		}
+		return j.scalarProperties(typedValue)
+
+	case *NullType:
+		var items openAPIKeys
+		items = append(items, j.collectDocumentation(typedValue)...)
+		items = append(items, j.convertValidations(typedValue)...)
+
+		if typedValue.GetValueType() == nil {
+			// declared as null, with no other type: null is the only value allowed.
+			items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: "null"})
+			items = append(items, j.defaultKeyword(nil)...)
+			j.orderKeywords(items)
+			return &yamlmeta.Map{Items: items}
+		}
+
+		// JSON Schema has no "nullable"; instead, "null" is added to the allowed types (or, where there is no
+		// type to extend, allowed as an alternative).
+		var properties *yamlmeta.Map
+		scalarType, isScalar := typedValue.GetValueType().(*ScalarType)
+		if isScalar {
+			properties = j.scalarProperties(scalarType)
+			if !hasKey(properties, defaultProp) {
+				properties.Items = append(properties.Items, j.defaultKeyword(nil)...)
+			}
+			if !j.opts.NoPasswordFormat && writeOnlyString(typedValue) && !hasKey(properties, formatProp) {
+				properties.Items = append(properties.Items, &yamlmeta.MapItem{Key: formatProp, Value: passwordFormat})
+			}
+		} else {
+			properties = j.calculateProperties(typedValue.GetValueType())
+		}
+		if j.opts.Nulls != "" || j.predatesDraft201909() || hasKey(properties, refProp) || hasKey(properties, anyOfProp) {
+			items = append(items, j.nullableAsAnyOf(properties)...)
+		} else {
+			for _, prop := range properties.Items {
+				if prop.Key == typeProp {
+					prop.Value = []interface{}{prop.Value, "null"}
+				}
+			}
+			items = append(items, properties.Items...)
+		}
+
+		j.orderKeywords(items)
+		return &yamlmeta.Map{Items: items}
+
+	case *AnyType:
+		var items openAPIKeys
+		items = append(items, j.collectDocumentation(typedValue)...)
+		items = append(items, j.convertValidations(typedValue)...)
+		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: j.anyTypes()})
+		items = append(items, j.defaultKeyword(typedValue.GetDefaultValue())...)
+
+		j.orderKeywords(items)
+		return &yamlmeta.Map{Items: items}
+
+	default:
+		panic(fmt.Sprintf("Unrecognized type %T", schemaVal))
+	}
+}
+
+// scalarProperties describes a scalar (without allowing null, even if NullableStrings is set: see NullType).
+func (j *JSONSchemaDocument) scalarProperties(typedValue *ScalarType) *yamlmeta.Map {
+	var items openAPIKeys
+	items = append(items, j.collectDocumentation(typedValue)...)
+	items = append(items, j.convertValidations(typedValue)...)
+	// a null default would suggest that null is allowed; only a NullType (wrapping this one) allows it.
+	if defaultValue := typedValue.GetDefaultValue(); defaultValue != nil {
+		items = append(items, j.defaultKeyword(defaultValue)...)
+	}
+
+	typeString := j.openAPITypeFor(typedValue)
+	items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: typeString})
+
+	if j.opts.FloatFormat && typedValue.String() == "float" && typeString == "number" {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "float"})
+	}
+	if typedValue.format != nil {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: typedValue.format.format})
+	} else if !j.opts.NoPasswordFormat && writeOnlyString(typedValue) {
+		items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: passwordFormat})
+	} else if j.opts.InferFormats {
+		if format := inferFormat(typedValue.GetDefaultValue()); format != "" {
+			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: format})
+		}
+	}
+	if typedValue.contentEncoding != "" {
+		items = append(items, &yamlmeta.MapItem{Key: contentEncodingProp, Value: typedValue.contentEncoding})
+	}
+	if typedValue.contentMediaType != "" {
+		items = append(items, &yamlmeta.MapItem{Key: contentMediaTypeProp, Value: typedValue.contentMediaType})
+	}
+
+	j.orderKeywords(items)
+	return &yamlmeta.Map{Items: items}
+}
+
+// collectDocumentation lists the JSON Schema annotations of `typedValue` (see also
+// OpenAPIDocument.collectDocumentation()).
+//
+// All examples are given (as "examples"), without their descriptions; JSON Schema has nowhere to put them. Comments
+// (for maintainers) are given as "$comment", which OpenAPI v3.0 lacks.
+func (j *JSONSchemaDocument) collectDocumentation(typedValue Type) []*yamlmeta.MapItem {
+	var items []*yamlmeta.MapItem
+	if typedValue.GetTitle() != "" {
+		items = append(items, &yamlmeta.MapItem{Key: titleProp, Value: typedValue.GetTitle()})
+	}
+	description := describe(typedValue)
+	if unit := unitOf(typedValue); j.opts.UnitDescriptions && unit != "" {
+		description = strings.TrimSpace(fmt.Sprintf("%s (%s)", description, unit))
+	}
+	if description != "" {
+		items = append(items, &yamlmeta.MapItem{Key: descriptionProp, Value: description})
+	}
+	if typedValue.GetComment() != "" {
+		items = append(items, &yamlmeta.MapItem{Key: commentProp, Value: typedValue.GetComment()})
+	}
+	if isDeprecated, _ := typedValue.IsDeprecated(); isDeprecated {
+		items = append(items, &yamlmeta.MapItem{Key: deprecatedProp, Value: isDeprecated})
+	}
+	items = append(items, accessKeywords(typedValue)...)
+	if examples := typedValue.GetExamples(); len(examples) != 0 {
+		var values []interface{}
+		for _, ex := range examples {
+			values = append(values, ex.example)
+		}
+		items = append(items, &yamlmeta.MapItem{Key: examplesProp, Value: values})
+	} else if j.opts.ExamplesFromDefaults {
+		items = append(items, j.exampleFromDefault(typedValue)...)
+	}
+	items = append(items, extensionKeywords(typedValue)...)
+	return items
+}
+
+// exampleFromDefault gives the default of `typedValue` as its only example (see ExamplesFromDefaults).
+func (j *JSONSchemaDocument) exampleFromDefault(typedValue Type) []*yamlmeta.MapItem {
+	if _, isMap := typedValue.(*MapType); isMap {
+		return nil
+	}
+	defaultValue := typedValue.GetDefaultValue()
+	if defaultValue == nil {
+		return nil
+	}
+	// an empty array is no example (its items are given examples of their own).
+	if array, isArray := defaultValue.(*yamlmeta.Array); isArray && len(array.Items) == 0 {
+		return nil
+	}
+	return []*yamlmeta.MapItem{{Key: examplesProp, Value: []interface{}{defaultValue}}}
+}
+
+// synthesizeDescription describes a value of type `valueType` for lack of documentation: by its type and (except for
+// maps, whose defaults are given by their properties) its default.
+func (j *JSONSchemaDocument) synthesizeDescription(valueType Type) string {
+	if _, isMap := valueType.(*MapType); isMap {
+		return j.describeType(valueType)
+	}
+	if nullType, isNullable := valueType.(*NullType); isNullable {
+		if _, isMap := nullType.GetValueType().(*MapType); isMap {
+			return j.describeType(valueType)
+		}
+	}
+	return fmt.Sprintf("%s (default: %s)", j.describeType(valueType), asJSON(valueType.GetDefaultValue()))
+}
+
+// tupleKeywords gives the schemas of the items of `tuple`, by position, and closes it to any other item: draft 2020-12
+// lists them as "prefixItems" (any other item being disallowed by "items"), earlier drafts as an "items" of a list
+// (with "additionalItems"). As a tuple's items are all given, it is also given a "minItems" (unless validated so).
+func (j *JSONSchemaDocument) tupleKeywords(tuple *ArrayType, arrayKeywords []*yamlmeta.MapItem) []*yamlmeta.MapItem {
+	var schemas []interface{}
+	for _, itemType := range tuple.tupleItems {
+		schemas = append(schemas, j.calculateProperties(itemType.GetValueType()))
+	}
+
+	var keywords []*yamlmeta.MapItem
+	if !hasKey(&yamlmeta.Map{Items: arrayKeywords}, minItemsProp) {
+		keywords = append(keywords, &yamlmeta.MapItem{Key: minItemsProp, Value: len(schemas)})
+	}
+	if j.opts.Draft == JSONSchemaDraft202012 {
+		return append(keywords, &yamlmeta.MapItem{Key: prefixItemsProp, Value: schemas}, &yamlmeta.MapItem{Key: itemsProp, Value: false})
+	}
+	return append(keywords, &yamlmeta.MapItem{Key: itemsProp, Value: schemas}, &yamlmeta.MapItem{Key: additionalItemsProp, Value: false})
+}
+
+// describeType names `valueType` in JSON Schema terms (e.g. "array of string").
+func (j *JSONSchemaDocument) describeType(valueType Type) string {
+	switch typedValue := valueType.(type) {
+	case *MapType:
+		return "object"
+	case *ArrayType:
+		return "array of " + j.describeType(typedValue.GetValueType().GetValueType())
+	case *ScalarType:
+		return j.openAPITypeFor(typedValue)
+	case *NullType:
+		if typedValue.GetValueType() == nil {
+			return "null"
+		}
+		return j.describeType(typedValue.GetValueType()) + " or null"
+	case *AnyType:
+		return "any type"
+	default:
+		panic(fmt.Sprintf("Unrecognized type %T", valueType))
+	}
+}
+
+// asJSON encodes `value` (a scalar or AST) as JSON.
+func asJSON(value interface{}) string {
+	doc := &yamlmeta.Document{Value: value}
+	bs, err := json.Marshal(orderedmap.Conversion{Object: doc.AsInterface()}.AsUnorderedStringMaps())
+	if err != nil {
+		panic(fmt.Sprintf("Marshaling default value: %s", err))
+	}
+	return string(bs)
+}
+
+// defaultKeyword gives `value` as the default (unless `NoDefaults` is set).
+func (j *JSONSchemaDocument) defaultKeyword(value interface{}) []*yamlmeta.MapItem {
+	if j.opts.NoDefaults {
+		return nil
+	}
+	return []*yamlmeta.MapItem{{Key: defaultProp, Value: value}}
+}
+
+// orderKeywords sorts `items` (the keywords of a schema) as configured.
+func (j *JSONSchemaDocument) orderKeywords(items []*yamlmeta.MapItem) {
+	if j.opts.KeywordLess != nil {
+		sort.SliceStable(items, func(i, k int) bool {
+			return j.opts.KeywordLess(items[i].Key.(string), items[k].Key.(string))
+		})
+		return
+	}
+	if !j.opts.PreserveOrder {
+		sort.Stable(openAPIKeys(items))
+		return
+	}
+	sort.SliceStable(items, func(i, k int) bool {
+		return keywordRank(jsonSchemaKeywordOrder, items[i].Key.(string)) < keywordRank(jsonSchemaKeywordOrder, items[k].Key.(string))
+	})
+}
+
+// anyTypes lists every JSON Schema type, as permitted for values of any type.
+func (j *JSONSchemaDocument) anyTypes() []interface{} {
+	if j.opts.SortAnyTypes {
+		return []interface{}{"array", "boolean", "integer", "null", "number", "object", "string"}
+	}
+	return []interface{}{"null", "string", "integer", "number", "object", "array", "boolean"}
+}
+
+// additionalPropertiesOf describes which keys (beyond those declared) `mapType` permits (see also
+// OpenAPIDocument.additionalPropertiesOf()).
+func (j *JSONSchemaDocument) additionalPropertiesOf(mapType *MapType) interface{} {
+	if !mapType.allowExtraProperties {
+		return false
+	}
+	if mapType.extraPropertiesType == nil {
+		return true
+	}
+	return j.calculateProperties(mapType.extraPropertiesType)
+}
+
+// requiredKeysOf lists (in sorted order) the keys of `mapType` that must be present in a value: those that are
+// validated to be not null, those that default to null without being nullable, and (when Nulls is "strict") those
+// that are nullable; or, when RequiredAll is set, all of them.
+func (j *JSONSchemaDocument) requiredKeysOf(mapType *MapType) []interface{} {
+	var required []interface{}
+	for _, item := range mapType.Items {
+		if j.opts.RequiredAll {
+			required = append(required, item.Key)
+			continue
+		}
+		if validation := item.GetValidation(); validation != nil && validation.HasSimpleNotNull() {
+			required = append(required, item.Key)
+			continue
+		}
+		_, isNullable := item.GetValueType().(*NullType)
+		if (!isNullable && item.defaultValue == nil) || (isNullable && j.opts.Nulls == JSONSchemaNullsStrict) {
+			required = append(required, item.Key)
+		}
+	}
+	sortKeys(required)
+	return required
+}
+
+// sortKeys sorts `keys` by their string form; stably, so that keys of that same form (e.g. 1 and "1") keep the order in
+// which they are declared.
+func sortKeys(keys []interface{}) {
+	sort.SliceStable(keys, func(i, k int) bool {
+		return fmt.Sprintf("%v", keys[i]) < fmt.Sprintf("%v", keys[k])
+	})
+}
+
+// renamedKey gives `key` as renamed by KeyRenamer, if set (and `key` is a string).
+func (j *JSONSchemaDocument) renamedKey(key interface{}) interface{} {
+	if name, isString := key.(string); isString && j.opts.KeyRenamer != nil {
+		return j.opts.KeyRenamer(name)
+	}
+	return key
+}
+
+// dependentRequiredKeyword lists the keys required by each key of `dependencies` (when given): for draft-07 and
+// draft-04, as "dependencies"; otherwise, as "dependentRequired".
+func (j *JSONSchemaDocument) dependentRequiredKeyword(dependencies []keyDependency) *yamlmeta.MapItem {
+	var items []*yamlmeta.MapItem
+	for _, dependency := range dependencies {
+		var requires []interface{}
+		for _, key := range dependency.requires {
+			requires = append(requires, j.renamedKey(key))
+		}
+		items = append(items, &yamlmeta.MapItem{Key: j.renamedKey(dependency.key), Value: requires})
+	}
+	key := dependentRequiredProp
+	if j.predatesDraft201909() {
+		key = dependenciesProp
+	}
+	return &yamlmeta.MapItem{Key: key, Value: &yamlmeta.Map{Items: items}}
+}
+
+// conditionKeywords requires the keys of `condition` when its key is given, with its value: "if" that key is (present,
+// and) the constant value, "then" the keys are required.
+func (j *JSONSchemaDocument) conditionKeywords(condition *keyCondition) []*yamlmeta.MapItem {
+	equals := &yamlmeta.MapItem{Key: constProp, Value: condition.equals}
+	if j.noConst() {
+		equals = &yamlmeta.MapItem{Key: enumProp, Value: []interface{}{condition.equals}}
+	}
+	var requires []interface{}
+	for _, key := range condition.requires {
+		requires = append(requires, j.renamedKey(key))
+	}
+	return []*yamlmeta.MapItem{
+		{Key: ifProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: propertiesProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+				{Key: j.renamedKey(condition.key), Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{equals}}},
+			}}},
+			{Key: requiredProp, Value: []interface{}{j.renamedKey(condition.key)}},
+		}}},
+		{Key: thenProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: requiredProp, Value: requires},
+		}}},
+	}
+}
+
+// discriminatedCondition is the condition on the items of an array, when they are maps told apart by a discriminator
+// (see @schema/discriminator): an item tagged (by the value of that key) as the shape declared requires all of its
+// keys. Items that are not such maps, or that already have a condition (see @schema/when), have none.
+func discriminatedCondition(itemType Type) *keyCondition {
+	mapType, ok := itemType.(*MapType)
+	if !ok || mapType.discriminator == "" || mapType.condition != nil {
+		return nil
+	}
+	condition := &keyCondition{key: mapType.discriminator, equals: mapType.findItem(mapType.discriminator).defaultValue}
+	for _, item := range mapType.Items {
+		if item.Key != mapType.discriminator {
+			condition.requires = append(condition.requires, fmt.Sprintf("%v", item.Key))
+		}
+	}
+	if len(condition.requires) == 0 {
+		return nil
+	}
+	return condition
+}
+
+// convertValidations converts the starlark validation map to a list of JSON Schema keywords
+//
+// Every (unconditional) constraint of the validation is emitted, alongside the others: a node has a single validation,
+// made of all the keyword arguments of its @schema/validation (each given at most once).
+//
+// Length constraints are mapped according to the value being constrained (looking through nullability): the number of
+// items of an array, of properties of a map, or of characters of a string; other scalars have no length.
+// Likewise, bounds and multiples only apply to numbers and patterns to strings. (JSON Schema expresses exclusive bounds as the bound
+// itself, rather than a boolean modifier as in OpenAPI v3.0, except in draft-04.) A single allowed value is given as a
+// "const" (unless `NoConst` is set, or in draft-04); documented allowed values (see @schema/enum-descriptions) are each
+// given as a subschema of a "oneOf". Items an array must contain (via contains=) are given as a "contains" of that
+// value, counted via "minContains" and "maxContains".
+func (j *JSONSchemaDocument) convertValidations(schemaVal Type) []*yamlmeta.MapItem {
+	validation := schemaVal.GetValidation()
+	if validation == nil {
+		return nil
+	}
+
+	valueType := schemaVal.GetValueType()
+	nullType, isNullable := valueType.(*NullType)
+	if isNullable {
+		valueType = nullType.GetValueType()
+	}
+	isNullable = isNullable || (j.opts.NullableStrings && j.isString(valueType))
+
+	var items []*yamlmeta.MapItem
+
+	if value, found := validation.HasSimpleMinLength(); found {
+		if key := j.lengthKeywordFor(valueType, minItemsProp, minPropertiesProp, minLenProp); key != "" {
+			items = append(items, &yamlmeta.MapItem{Key: key, Value: value})
+		}
+	}
+	if value, found := validation.HasSimpleMaxLength(); found {
+		if key := j.lengthKeywordFor(valueType, maxItemsProp, maxPropertiesProp, maxLenProp); key != "" {
+			items = append(items, &yamlmeta.MapItem{Key: key, Value: value})
+		}
+	}
+	if _, isMap := valueType.(*MapType); isMap {
+		if value, found := validation.HasSimpleMinProperties(); found {
+			items = append(items, &yamlmeta.MapItem{Key: minPropertiesProp, Value: value})
+		}
+		if value, found := validation.HasSimpleMaxProperties(); found {
+			items = append(items, &yamlmeta.MapItem{Key: maxPropertiesProp, Value: value})
+		}
+	}
+	if value, found := validation.HasSimpleMin(); found && j.isNumeric(valueType) {
+		items = append(items, j.boundKeywords(minProp, exclusiveMinProp, value, validation.HasExclusiveBounds())...)
+	}
+	if value, found := validation.HasSimpleMax(); found && j.isNumeric(valueType) {
+		items = append(items, j.boundKeywords(maxProp, exclusiveMaxProp, value, validation.HasExclusiveBounds())...)
+	}
+	if value, found := validation.HasSimpleMultipleOf(); found && j.isNumeric(valueType) {
+		items = append(items, &yamlmeta.MapItem{Key: multipleOfProp, Value: value})
+	}
+	if j.isString(valueType) {
+		patterns, _ := validation.HasSimplePatterns()
+		notPatterns, _ := validation.HasSimpleNotPatterns()
+		items = append(items, j.patternsAsKeywords(patterns, notPatterns)...)
+	}
+	if _, isArray := valueType.(*ArrayType); isArray && validation.HasSimpleUnique() {
+		items = append(items, &yamlmeta.MapItem{Key: uniqueItemsProp, Value: true})
+	}
+	if _, isArray := valueType.(*ArrayType); isArray {
+		items = append(items, j.containsKeywords(validation)...)
+	}
+	if value, found := validation.HasSimpleOneOf(); found {
+		// "enum" applies to the value as a whole, so a nullable value must list null for it to remain allowed.
+		if isNullable && !containsNull(value) {
+			value = append(value, nil)
+		}
+		if scalarType, isScalar := valueType.(*ScalarType); isScalar && len(scalarType.enumDescriptions) > 0 {
+			items = append(items, &yamlmeta.MapItem{Key: oneOfProp, Value: j.documentedValues(value, scalarType.enumDescriptions)})
+		} else if len(value) == 1 && !j.noConst() {
+			items = append(items, &yamlmeta.MapItem{Key: constProp, Value: value[0]})
+		} else if ref := j.enumReferenceIfRepeated(value); ref != nil {
+			items = append(items, ref)
+		} else {
+			items = append(items, &yamlmeta.MapItem{Key: enumProp, Value: value})
+		}
+		if j.opts.EnumLengths && j.isString(valueType) {
+			items = append(items, j.enumLengthKeywords(value, validation)...)
+		}
+	}
+	return items
+}
+
+// containsKeywords requires (via "contains") items equal to the value given via contains=: at least one, unless
+// bounded otherwise via min_contains= ("minContains") and/or max_contains= ("maxContains").
+func (j *JSONSchemaDocument) containsKeywords(validation *validations.NodeValidation) []*yamlmeta.MapItem {
+	value, found := validation.HasSimpleContains()
+	if !found {
+		return nil
+	}
+	equals := &yamlmeta.MapItem{Key: constProp, Value: value}
+	if j.noConst() {
+		equals = &yamlmeta.MapItem{Key: enumProp, Value: []interface{}{value}}
+	}
+	items := []*yamlmeta.MapItem{{Key: containsProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{equals}}}}
+	if minContains, found := validation.HasSimpleMinContains(); found {
+		items = append(items, &yamlmeta.MapItem{Key: minContainsProp, Value: minContains})
+	}
+	if maxContains, found := validation.HasSimpleMaxContains(); found {
+		items = append(items, &yamlmeta.MapItem{Key: maxContainsProp, Value: maxContains})
+	}
+	return items
+}
+
+// formatBoundKeywords bounds the integer held by `typedValue` (e.g. a MapItemType), if given a width via
+// @schema/format, by the least and greatest integers of that width (see FormatBounds); except where the validation of
+// `typedValue` bounds it already (via `min` or `max`, whether exclusive or not).
+func (j *JSONSchemaDocument) formatBoundKeywords(typedValue Type) []*yamlmeta.MapItem {
+	if !j.opts.FormatBounds {
+		return nil
+	}
+	valueType := typedValue.GetValueType()
+	if nullType, isNullable := valueType.(*NullType); isNullable {
+		valueType = nullType.GetValueType()
+	}
+	scalarType, isScalar := valueType.(*ScalarType)
+	if !isScalar || scalarType.format == nil || j.openAPITypeFor(scalarType) != "integer" {
+		return nil
+	}
+	bounds, found := integerFormatBounds[scalarType.format.format]
+	if !found {
+		return nil
+	}
+	var hasMin, hasMax bool
+	if validation := typedValue.GetValidation(); validation != nil {
+		_, hasMin = validation.HasSimpleMin()
+		_, hasMax = validation.HasSimpleMax()
+	}
+	var items []*yamlmeta.MapItem
+	if !hasMin {
+		items = append(items, &yamlmeta.MapItem{Key: minProp, Value: bounds[0]})
+	}
+	if !hasMax {
+		items = append(items, &yamlmeta.MapItem{Key: maxProp, Value: bounds[1]})
+	}
+	return items
+}
+
+// enumLengthKeywords bounds the length of strings by that of the shortest and longest of `values` (null, when allowed,
+// has no length), except where `validation` bounds it already.
+func (j *JSONSchemaDocument) enumLengthKeywords(values []interface{}, validation *validations.NodeValidation) []*yamlmeta.MapItem {
+	var shortest, longest int64 = -1, -1
+	for _, value := range values {
+		str, isString := value.(string)
+		if !isString {
+			continue
+		}
+		length := int64(utf8.RuneCountInString(str))
+		if shortest < 0 || length < shortest {
+			shortest = length
+		}
+		if length > longest {
+			longest = length
+		}
+	}
+	if longest < 0 {
+		return nil
+	}
+	var items []*yamlmeta.MapItem
+	if _, found := validation.HasSimpleMinLength(); !found {
+		items = append(items, &yamlmeta.MapItem{Key: minLenProp, Value: shortest})
+	}
+	if _, found := validation.HasSimpleMaxLength(); !found {
+		items = append(items, &yamlmeta.MapItem{Key: maxLenProp, Value: longest})
+	}
+	return items
+}
+
+// documentedValues allows one of `values`, each given as a subschema of its own (a "const", unless `NoConst` is set or
+// in draft-04) titled and described as in `descriptions` (see @schema/enum-descriptions).
+func (j *JSONSchemaDocument) documentedValues(values []interface{}, descriptions []enumDescription) []interface{} {
+	var subschemas []interface{}
+	for _, value := range values {
+		subschema := &yamlmeta.Map{}
+		if j.noConst() {
+			subschema.Items = append(subschema.Items, &yamlmeta.MapItem{Key: enumProp, Value: []interface{}{value}})
+		} else {
+			subschema.Items = append(subschema.Items, &yamlmeta.MapItem{Key: constProp, Value: value})
+		}
+		for _, described := range descriptions {
+			if !containsValue([]interface{}{described.value}, value) {
+				continue
+			}
+			if described.title != "" {
+				subschema.Items = append(subschema.Items, &yamlmeta.MapItem{Key: titleProp, Value: described.title})
+			}
+			subschema.Items = append(subschema.Items, &yamlmeta.MapItem{Key: descriptionProp, Value: described.description})
+		}
+		j.orderKeywords(subschema.Items)
+		subschemas = append(subschemas, subschema)
+	}
+	return subschemas
+}
+
+// boundKeywords bounds a number by `value`: inclusively, via `key`; exclusively, via `exclusiveKey` (in draft-04, a
+// boolean modifier of `key`).
+func (j *JSONSchemaDocument) boundKeywords(key, exclusiveKey string, value interface{}, exclusive bool) []*yamlmeta.MapItem {
+	if !exclusive {
+		return []*yamlmeta.MapItem{{Key: key, Value: value}}
+	}
+	if j.opts.Draft == JSONSchemaDraft04 {
+		return []*yamlmeta.MapItem{{Key: key, Value: value}, {Key: exclusiveKey, Value: true}}
+	}
+	return []*yamlmeta.MapItem{{Key: exclusiveKey, Value: value}}
+}
+
+// patternsAsKeywords requires a string to match all of `patterns` and none of `notPatterns` (each, via "not"): a single
+// constraint is given directly, several as subschemas of an "allOf" (as only one "pattern" can appear in a schema).
+func (j *JSONSchemaDocument) patternsAsKeywords(patterns, notPatterns []string) []*yamlmeta.MapItem {
+	var constraints []*yamlmeta.MapItem
+	for _, pattern := range patterns {
+		constraints = append(constraints, &yamlmeta.MapItem{Key: patternProp, Value: pattern})
+	}
+	for _, pattern := range notPatterns {
+		constraints = append(constraints, &yamlmeta.MapItem{Key: notProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: patternProp, Value: pattern},
+		}}})
+	}
+	if len(constraints) < 2 {
+		return constraints
+	}
+	var subschemas []interface{}
+	for _, constraint := range constraints {
+		subschemas = append(subschemas, &yamlmeta.Map{Items: []*yamlmeta.MapItem{constraint}})
+	}
+	return []*yamlmeta.MapItem{{Key: allOfProp, Value: subschemas}}
+}
+
+// lengthKeywordFor selects which of the given keywords constrains the length of a value of type `valueType`, if any.
+func (j *JSONSchemaDocument) lengthKeywordFor(valueType Type, arrayKey, mapKey, stringKey string) string {
+	switch typedValue := valueType.(type) {
+	case *ArrayType:
+		return arrayKey
+	case *MapType:
+		return mapKey
+	case *ScalarType:
+		if j.isString(typedValue) {
+			return stringKey
+		}
+	}
+	return ""
+}
+
+// isString indicates whether `valueType` is a string.
+func (j *JSONSchemaDocument) isString(valueType Type) bool {
+	scalarType, ok := valueType.(*ScalarType)
+	return ok && j.openAPITypeFor(scalarType) == "string"
+}
+
+// isNumeric indicates whether `valueType` is an integer or a number.
+func (j *JSONSchemaDocument) isNumeric(valueType Type) bool {
+	scalarType, ok := valueType.(*ScalarType)
+	if !ok {
+		return false
+	}
+	typeString := j.openAPITypeFor(scalarType)
+	return typeString == "integer" || typeString == "number"
+}
+
+// nullableAsAnyOf expresses that the value described by `properties` may also be null as a choice between that
+// schema and the "null" type.
+//
+// (while draft-07 and draft-04 permit a list of types, validators disagree on how sibling keywords apply to such a
+// list; a choice of subschemas is understood uniformly.)
+func (j *JSONSchemaDocument) nullableAsAnyOf(properties *yamlmeta.Map) []*yamlmeta.MapItem {
+	var items []*yamlmeta.MapItem
+	valueSchema := &yamlmeta.Map{}
+	for _, prop := range properties.Items {
+		if prop.Key == defaultProp {
+			items = append(items, prop)
+		} else {
+			valueSchema.Items = append(valueSchema.Items, prop)
+		}
+	}
+	nullSchema := &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: typeProp, Value: "null"}}}
+	return append(items, &yamlmeta.MapItem{Key: anyOfProp, Value: []interface{}{valueSchema, nullSchema}})
+}
+
+// rewriteGenerated rewrites `value` (a generated schema) as configured, once it is complete: inlining single
+// combinations (see FlattenSingleOneOf), then giving defaults as examples (see DefaultsAsExamples).
+func (j *JSONSchemaDocument) rewriteGenerated(value interface{}) {
+	j.flattenSingleCombinations(value)
+	if j.opts.DefaultsAsExamples {
+		j.defaultsAsExamples(value)
+	}
+	if j.opts.DropUnsupported {
+		j.dropUnsupportedKeywords(value)
+	}
+}
+
+// defaultsAsExamples replaces, throughout `value` (a generated schema), each "default" by an example of that value:
+// the only one, or the first of those the schema is given (unless among them already).
+func (j *JSONSchemaDocument) defaultsAsExamples(value interface{}) {
+	switch typedValue := value.(type) {
+	case *yamlmeta.Map:
+		var defaultValue *yamlmeta.MapItem
+		var items []*yamlmeta.MapItem
+		for _, item := range typedValue.Items {
+			switch key, _ := item.Key.(string); {
+			case key == defaultProp:
+				defaultValue = item
+				continue
+			case isValueKeyword(key):
+			case key == propertiesProp || key == patternPropertiesProp || key == defsProp || key == defs07Prop:
+				// (subschemas by name)
+				for _, named := range asSchemaMap(item.Value).Items {
+					j.defaultsAsExamples(named.Value)
+				}
+			default:
+				j.defaultsAsExamples(item.Value)
+			}
+			items = append(items, item)
+		}
+		if defaultValue == nil {
+			return
+		}
+		typedValue.Items = items
+		for _, item := range typedValue.Items {
+			if item.Key == examplesProp {
+				if examples := listOf(item.Value); !containsValue(examples, defaultValue.Value) {
+					item.Value = append([]interface{}{defaultValue.Value}, examples...)
+				}
+				return
+			}
+		}
+		typedValue.Items = append(typedValue.Items, &yamlmeta.MapItem{Key: examplesProp, Value: []interface{}{defaultValue.Value}})
+		j.orderKeywords(typedValue.Items)
+	case []interface{}:
+		for _, item := range typedValue {
+			j.defaultsAsExamples(item)
+		}
+	}
+}
+
+// combinationKeywords combine subschemas (which, when there is only one, can be inlined: see FlattenSingleOneOf).
+var combinationKeywords = map[string]bool{oneOfProp: true, anyOfProp: true, allOfProp: true}
+
+// flattenSingleCombinations inlines, throughout `value` (a generated schema), each combination of a single subschema
+// into the schema containing it, if FlattenSingleOneOf is set. (Values, e.g. defaults, are left as they are.)
+func (j *JSONSchemaDocument) flattenSingleCombinations(value interface{}) {
+	if !j.opts.FlattenSingleOneOf {
+		return
+	}
+	switch typedValue := value.(type) {
+	case *yamlmeta.Map:
+		for _, item := range typedValue.Items {
+			switch key, _ := item.Key.(string); {
+			case isValueKeyword(key):
+			case key == propertiesProp || key == patternPropertiesProp || key == defsProp || key == defs07Prop:
+				// (subschemas by name)
+				for _, named := range asSchemaMap(item.Value).Items {
+					j.flattenSingleCombinations(named.Value)
+				}
+			default:
+				j.flattenSingleCombinations(item.Value)
+			}
+		}
+		for i := 0; i < len(typedValue.Items); i++ {
+			if subschema, ok := j.singleSubschemaOf(typedValue, typedValue.Items[i]); ok {
+				inlined := append(append([]*yamlmeta.MapItem{}, typedValue.Items[:i]...), subschema.Items...)
+				typedValue.Items = append(inlined, typedValue.Items[i+1:]...)
+				j.orderKeywords(typedValue.Items)
+				i = -1 // the inlined subschema may itself be a combination
+			}
+		}
+	case []interface{}:
+		for _, item := range typedValue {
+			j.flattenSingleCombinations(item)
+		}
+	}
+}
+
+// singleSubschemaOf returns the only subschema combined by `keyword` (an item of `schema`), if it can be inlined.
+func (j *JSONSchemaDocument) singleSubschemaOf(schema *yamlmeta.Map, keyword *yamlmeta.MapItem) (*yamlmeta.Map, bool) {
+	if key, _ := keyword.Key.(string); !combinationKeywords[key] {
+		return nil, false
+	}
+	subschemas := listOf(keyword.Value)
+	if len(subschemas) != 1 {
+		return nil, false
+	}
+	subschema, ok := subschemas[0].(*yamlmeta.Map)
+	if !ok {
+		return nil, false
+	}
+	if j.predatesDraft201909() && hasKey(subschema, refProp) && len(schema.Items) > 1 {
+		// keywords alongside a "$ref" would be ignored (see withKeywords()).
+		return nil, false
+	}
+	for _, item := range subschema.Items {
+		if item.Key != keyword.Key && hasKey(schema, item.Key.(string)) {
+			return nil, false
+		}
+	}
+	return subschema, true
+}
+
+// withKeywords adds `keywords` to `schema`.
+//
+// Before draft 2019-09, keywords alongside a "$ref" are ignored; there, a reference is wrapped so that the keywords
+// apply.
+func (j *JSONSchemaDocument) withKeywords(schema *yamlmeta.Map, keywords []*yamlmeta.MapItem) *yamlmeta.Map {
+	if len(keywords) == 0 {
+		return schema
+	}
+	if j.predatesDraft201909() && hasKey(schema, refProp) {
+		schema = &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: allOfProp, Value: []interface{}{schema}}}}
+	}
+	schema.Items = append(schema.Items, keywords...)
+	return schema
+}
+
+// defsKey is the keyword under which reusable subschemas are declared in the targeted draft.
+func (j *JSONSchemaDocument) defsKey() string {
+	if j.predatesDraft201909() {
+		return defs07Prop
+	}
+	return defsProp
+}
+
+// idKey is the keyword identifying a schema by URI in the targeted draft.
+func (j *JSONSchemaDocument) idKey() string {
+	if j.opts.Draft == JSONSchemaDraft04 {
+		return id04Prop
+	}
+	return idProp
+}
+
+// noConst indicates whether a single allowed value is given as an "enum" of one: if NoConst is set, or in draft-04
+// (which has no "const").
+func (j *JSONSchemaDocument) noConst() bool {
+	return j.opts.NoConst || j.opts.Draft == JSONSchemaDraft04
+}
+
+// predatesDraft201909 indicates whether the targeted draft is one of those preceding draft 2019-09, which introduced
+// "$defs", "$anchor" and "dependentRequired" and let keywords apply alongside a "$ref".
+func (j *JSONSchemaDocument) predatesDraft201909() bool {
+	return j.opts.Draft == JSONSchemaDraft07 || j.opts.Draft == JSONSchemaDraft04
+}
+
+// deprecationPrefix starts the message of a validation rule noting that the value is deprecated (see
+// DeprecatedFromValidations).
+const deprecationPrefix = "DEPRECATED:"
+
+// mergeValidationMessages appends to the description of `schema` (giving it one, if it has none) a sentence listing
+// the messages of the rules of `validation`, if any: what a valid value must be.
+func mergeValidationMessages(schema *yamlmeta.Map, validation *validations.NodeValidation) {
+	if validation == nil || len(validation.Messages()) == 0 {
+		return
+	}
+	requirement := "Must be " + strings.Join(validation.Messages(), " and ") + "."
+	for _, item := range schema.Items {
+		if description, isString := item.Value.(string); isString && item.Key == descriptionProp {
+			if !strings.HasSuffix(description, ".") {
+				description += "."
+			}
+			item.Value = description + " " + requirement
+			return
+		}
+	}
+	schema.Items = append(schema.Items, &yamlmeta.MapItem{Key: descriptionProp, Value: requirement})
+}
+
+// isDeprecatedByValidation indicates whether a rule of the validation of `typedValue` notes that it is deprecated.
+func isDeprecatedByValidation(typedValue Type) bool {
+	validation := typedValue.GetValidation()
+	if validation == nil {
+		return false
+	}
+	for _, message := range validation.Messages() {
+		if strings.HasPrefix(message, deprecationPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// humanizeKey spells out `key` as words (split at underscores, dashes, dots and changes of case), each capitalized:
+// e.g. "db_conn" and "dbConn" become "Db Conn".
+func humanizeKey(key interface{}) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			word[0] = unicode.ToUpper(word[0])
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	for _, r := range fmt.Sprintf("%v", key) {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return strings.Join(words, " ")
+}
+
+func containsNull(values []interface{}) bool {
+	for _, value := range values {
+		if value == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(m *yamlmeta.Map, key string) bool {
+	for _, item := range m.Items {
+		if item.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// JSONSchemaBundle holds the document types of the data values of several libraries, used for creating a single JSON
+// Schema document that describes them all (e.g. to publish one schema for an app composed of those libraries).
+type JSONSchemaBundle struct {
+	names []string // library names, sorted
+	docs  map[string]*JSONSchemaDocument
+}
+
+// NewJSONSchemaBundle creates an instance of a JSONSchemaBundle based on the given DocumentTypes, by library name.
+//
+// Returns an error if `docTypes` is empty, if a library name is empty, or if `opts` targets an unsupported draft.
+func NewJSONSchemaBundle(docTypes map[string]*DocumentType, opts JSONSchemaOpts) (*JSONSchemaBundle, error) {
+	if len(docTypes) == 0 {
+		return nil, fmt.Errorf("Expected at least one library to describe")
+	}
+	bundle := &JSONSchemaBundle{docs: map[string]*JSONSchemaDocument{}}
+	for name := range docTypes {
+		bundle.names = append(bundle.names, name)
+	}
+	// (libraries are described in order of their names, so that which error is reported does not vary between runs)
+	sort.Strings(bundle.names)
+	for _, name := range bundle.names {
+		if name == "" {
+			return nil, fmt.Errorf("Expected each library to be named")
+		}
+		doc, err := NewJSONSchemaDocument(docTypes[name], opts)
+		if err != nil {
+			return nil, err
+		}
+		doc.defsPrefix = name
+		bundle.docs[name] = doc
+	}
+	return bundle, nil
+}
+
+// AsDocument generates a new AST of this JSON Schema document: a root that requires ("allOf") the schema of each
+// library, which is defined under the name of that library.
+//
+// Definitions of repeated maps are declared alongside, named after the library in which they occur (except for maps
+// named via @schema/schema-name, which are declared once).
+func (j *JSONSchemaBundle) AsDocument() *yamlmeta.Document {
+	first := j.docs[j.names[0]]
+
+	var refs []interface{}
+	var defs []*yamlmeta.MapItem
+	defined := map[interface{}]bool{}
+	for _, name := range j.names {
+		doc := j.docs[name]
+		refs = append(refs, &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: refProp, Value: fmt.Sprintf("#/%s/%s", first.defsKey(), escapeJSONPointer(name))},
+		}})
+		defined[name] = true
+		defs = append(defs, &yamlmeta.MapItem{Key: name, Value: doc.asSchema()})
+	}
+	for _, name := range j.names {
+		if repeated := j.docs[name].defs; repeated != nil {
+			for _, def := range repeated.items {
+				if !defined[def.Key] {
+					defined[def.Key] = true
+					defs = append(defs, def)
+				}
+			}
+		}
+	}
+
+	result := &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+		{Key: allOfProp, Value: refs},
+		{Key: first.defsKey(), Value: &yamlmeta.Map{Items: defs}},
+	}}
+	first.rewriteGenerated(result)
+	return &yamlmeta.Document{Value: first.withMetaKeywords(result, "Schema for data values of libraries, generated by ytt")}
+}
+
+// escapeJSONPointer escapes `token` for use in a JSON Pointer (e.g. a library named "a/b" is referred to as "a~1b").
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
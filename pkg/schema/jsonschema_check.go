@@ -0,0 +1,290 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// ValidateAgainstJSONSchema checks `doc` against `schemaDoc`, a JSON Schema (e.g. as exported, then parsed back from a
+// file), reporting each value that does not conform by its path, as Validate() does (each keyword being checked alike:
+// see checkType() and the checks following it). Values against a ytt schema are better checked via Validate(), which
+// needs no export.
+//
+// The keywords checked are those ytt exports: types, properties (required, additional, pattern-matched, dependent,
+// conditional and their names), items, lengths, bounds, multiples, patterns, allowed values, and combinations of schemas
+// ("allOf", "anyOf", "oneOf" and "not"). References are followed within the schema only (i.e. "#/..."); formats are not
+// checked.
+func ValidateAgainstJSONSchema(schemaDoc, doc *yamlmeta.Document) []error {
+	root, ok := schemaDoc.Value.(*yamlmeta.Map)
+	if !ok {
+		return []error{fmt.Errorf("Expected JSON Schema to be a map, but was %s", jsonTypeOf(schemaDoc.Value))}
+	}
+	checker := jsonSchemaChecker{root: root}
+	return checker.check("values", root, doc.Value)
+}
+
+type jsonSchemaChecker struct {
+	root *yamlmeta.Map
+}
+
+func (c jsonSchemaChecker) check(path string, schemaVal interface{}, value interface{}) []error {
+	if isTrue(schemaVal) {
+		return nil
+	}
+	if isFalse(schemaVal) {
+		return []error{fmt.Errorf("%s: not allowed", path)}
+	}
+	schema := resolveRef(c.root, asSchemaMap(schemaVal))
+
+	if types := listOfTypes(keywordOrNil(schema, typeProp)); len(types) > 0 {
+		if errs := checkType(path, types, value); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	var errs []error
+	errs = append(errs, c.checkCombinations(path, schema, value)...)
+	errs = append(errs, c.checkAllowedValues(path, schema, value)...)
+	switch typedValue := value.(type) {
+	case *yamlmeta.Map:
+		errs = append(errs, c.checkObject(path, schema, typedValue)...)
+	case *yamlmeta.Array:
+		errs = append(errs, c.checkArray(path, schema, typedValue)...)
+	case string:
+		errs = append(errs, c.checkString(path, schema, typedValue)...)
+	default:
+		if _, isNumber := asFloat(value); isNumber {
+			errs = append(errs, c.checkNumber(path, schema, value)...)
+		}
+	}
+	return errs
+}
+
+// checkCombinations checks `value` against the schemas combined by "allOf" (all of them), "anyOf" (at least one),
+// "oneOf" (exactly one) and "not" (not that one). When none match, the errors against the closest (i.e. with the fewest errors) are reported.
+func (c jsonSchemaChecker) checkCombinations(path string, schema *yamlmeta.Map, value interface{}) []error {
+	var errs []error
+	for _, subschema := range listOf(keywordOrNil(schema, allOfProp)) {
+		errs = append(errs, c.check(path, subschema, value)...)
+	}
+	if alternatives := listOf(keywordOrNil(schema, anyOfProp)); len(alternatives) > 0 {
+		if matched, closest := c.matchesOf(path, alternatives, value); matched == 0 {
+			errs = append(errs, closest...)
+		}
+	}
+	if alternatives := listOf(keywordOrNil(schema, oneOfProp)); len(alternatives) > 0 {
+		matched, closest := c.matchesOf(path, alternatives, value)
+		switch {
+		case matched == 0:
+			errs = append(errs, closest...)
+		case matched > 1:
+			errs = append(errs, fmt.Errorf("%s: expected exactly one of the alternatives to match, got %d", path, matched))
+		}
+	}
+	if negated, found := keywordOf(schema, notProp); found && len(c.check(path, negated, value)) == 0 {
+		errs = append(errs, fmt.Errorf("%s: expected a value not matching the schema under \"%s\"", path, notProp))
+	}
+	return errs
+}
+
+func (c jsonSchemaChecker) matchesOf(path string, alternatives []interface{}, value interface{}) (int, []error) {
+	matched := 0
+	var closest []error
+	for i, alternative := range alternatives {
+		errs := c.check(path, alternative, value)
+		if len(errs) == 0 {
+			matched++
+			continue
+		}
+		if i == 0 || len(errs) < len(closest) {
+			closest = errs
+		}
+	}
+	return matched, closest
+}
+
+func (c jsonSchemaChecker) checkAllowedValues(path string, schema *yamlmeta.Map, value interface{}) []error {
+	if constVal, found := keywordOf(schema, constProp); found && !containsValue([]interface{}{asComparable(constVal)}, value) {
+		return []error{fmt.Errorf("%s: expected %v, got %v", path, asComparable(constVal), asComparable(value))}
+	}
+	if enum, found := keywordOf(schema, enumProp); found {
+		var allowed []interface{}
+		for _, item := range listOf(enum) {
+			allowed = append(allowed, asComparable(item))
+		}
+		return checkAllowed(path, allowed, value)
+	}
+	return nil
+}
+
+// patternPropertyOf returns the schema of the first of the "patternProperties" of `schema` whose pattern `key` matches.
+func (c jsonSchemaChecker) patternPropertyOf(schema *yamlmeta.Map, key string) (interface{}, bool) {
+	for _, property := range asSchemaMap(keywordOrNil(schema, patternPropertiesProp)).Items {
+		if matched, err := regexp.MatchString(fmt.Sprintf("%v", property.Key), key); err == nil && matched {
+			return property.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (c jsonSchemaChecker) checkObject(path string, schema *yamlmeta.Map, mapVal *yamlmeta.Map) []error {
+	var errs []error
+	properties := propertiesOf(schema)
+	given := map[string]interface{}{}
+	for _, item := range mapVal.Items {
+		key := fmt.Sprintf("%v", item.Key)
+		given[key] = item.Value
+		itemPath := fmt.Sprintf("%s.%s", path, key)
+		if propertyNames, found := keywordOf(schema, propertyNamesProp); found {
+			if pattern, ok := keywordOrNil(asSchemaMap(propertyNames), patternProp).(string); ok {
+				if keyErrs := checkKeyPattern(itemPath, key, pattern); len(keyErrs) > 0 {
+					errs = append(errs, keyErrs...)
+					continue
+				}
+			}
+		}
+		if propertySchema, found := keywordOf(properties, key); found {
+			errs = append(errs, c.check(itemPath, propertySchema, item.Value)...)
+			continue
+		}
+		if patternSchema, found := c.patternPropertyOf(schema, key); found {
+			errs = append(errs, c.check(itemPath, patternSchema, item.Value)...)
+			continue
+		}
+		additional, found := keywordOf(schema, additionalPropsProp)
+		switch {
+		case !found, isTrue(additional):
+		case isFalse(additional):
+			errs = append(errs, fmt.Errorf("%s: unexpected key", itemPath))
+		default:
+			errs = append(errs, c.check(itemPath, additional, item.Value)...)
+		}
+	}
+
+	for _, key := range listOf(keywordOrNil(schema, requiredProp)) {
+		if _, found := given[fmt.Sprintf("%v", key)]; !found {
+			errs = append(errs, missingKeyError(path, key))
+		}
+	}
+	for _, keyword := range []string{dependentRequiredProp, dependenciesProp} {
+		dependencies := asSchemaMap(keywordOrNil(schema, keyword))
+		for _, dependency := range dependencies.Items {
+			if _, found := given[fmt.Sprintf("%v", dependency.Key)]; !found {
+				continue
+			}
+			for _, key := range listOf(dependency.Value) {
+				if _, found := given[fmt.Sprintf("%v", key)]; !found {
+					errs = append(errs, missingDependencyError(path, key, dependency.Key))
+				}
+			}
+		}
+	}
+	if ifSchema, found := keywordOf(schema, ifProp); found && len(c.check(path, ifSchema, mapVal)) == 0 {
+		if thenSchema, found := keywordOf(schema, thenProp); found {
+			errs = append(errs, c.check(path, thenSchema, mapVal)...)
+		}
+	}
+
+	errs = append(errs, checkKeyCount(path, int64(len(mapVal.Items)), keywordOrNil(schema, minPropertiesProp), keywordOrNil(schema, maxPropertiesProp))...)
+	return errs
+}
+
+func (c jsonSchemaChecker) checkArray(path string, schema *yamlmeta.Map, arrayVal *yamlmeta.Array) []error {
+	var errs []error
+	items, hasItems := keywordOf(schema, itemsProp)
+	// items by position: "prefixItems" (then, "items" for the others) or, before draft 2019-09, an "items" of a list
+	// (then, "additionalItems").
+	var positional []interface{}
+	if prefixItems, found := keywordOf(schema, prefixItemsProp); found {
+		positional = listOf(prefixItems)
+	} else if list := listOf(items); list != nil {
+		positional = list
+		items, hasItems = keywordOf(schema, additionalItemsProp)
+	}
+	contains, hasContains := keywordOf(schema, containsProp)
+	var seen []interface{}
+	matching := 0
+	for i, item := range arrayVal.Items {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(positional) {
+			errs = append(errs, c.check(itemPath, positional[i], item.Value)...)
+		} else if hasItems {
+			errs = append(errs, c.check(itemPath, items, item.Value)...)
+		}
+		if isTrue(keywordOrNil(schema, uniqueItemsProp)) {
+			if containsValue(seen, item.Value) {
+				errs = append(errs, fmt.Errorf("%s: expected unique items, got a duplicate", itemPath))
+			}
+			seen = append(seen, asComparable(item.Value))
+		}
+		if hasContains && len(c.check(itemPath, contains, item.Value)) == 0 {
+			matching++
+		}
+	}
+	errs = append(errs, checkLength(path, int64(len(arrayVal.Items)), keywordOrNil(schema, minItemsProp), keywordOrNil(schema, maxItemsProp))...)
+	if hasContains {
+		minContains, isNumber := asFloat(keywordOrNil(schema, minContainsProp))
+		if !isNumber {
+			minContains = 1
+		}
+		if float64(matching) < minContains {
+			errs = append(errs, fmt.Errorf("%s: expected >= %v items matching \"contains\", got %d", path, minContains, matching))
+		}
+		if maxContains, isNumber := asFloat(keywordOrNil(schema, maxContainsProp)); isNumber && float64(matching) > maxContains {
+			errs = append(errs, fmt.Errorf("%s: expected <= %v items matching \"contains\", got %d", path, maxContains, matching))
+		}
+	}
+	return errs
+}
+
+func (c jsonSchemaChecker) checkString(path string, schema *yamlmeta.Map, str string) []error {
+	errs := checkLength(path, int64(len([]rune(str))), keywordOrNil(schema, minLenProp), keywordOrNil(schema, maxLenProp))
+	if pattern, ok := keywordOrNil(schema, patternProp).(string); ok {
+		errs = append(errs, checkPattern(path, str, pattern, false)...)
+	}
+	return errs
+}
+
+func (c jsonSchemaChecker) checkNumber(path string, schema *yamlmeta.Map, value interface{}) []error {
+	var errs []error
+	min, hasMin := keywordOf(schema, minProp)
+	max, hasMax := keywordOf(schema, maxProp)
+	exclusiveMin, _ := keywordOf(schema, exclusiveMinProp)
+	exclusiveMax, _ := keywordOf(schema, exclusiveMaxProp)
+	// in draft-04, exclusive bounds are boolean modifiers of "minimum" and "maximum".
+	if hasMin {
+		errs = append(errs, checkBound(path, value, min, false, isTrue(exclusiveMin))...)
+	}
+	if _, isModifier := exclusiveMin.(bool); exclusiveMin != nil && !isModifier {
+		errs = append(errs, checkBound(path, value, exclusiveMin, false, true)...)
+	}
+	if hasMax {
+		errs = append(errs, checkBound(path, value, max, true, isTrue(exclusiveMax))...)
+	}
+	if _, isModifier := exclusiveMax.(bool); exclusiveMax != nil && !isModifier {
+		errs = append(errs, checkBound(path, value, exclusiveMax, true, true)...)
+	}
+	if multipleOf, found := keywordOf(schema, multipleOfProp); found {
+		errs = append(errs, checkMultipleOf(path, value, multipleOf)...)
+	}
+	return errs
+}
+
+// listOfTypes gives the type(s) named by a "type" keyword (a single type, or a list of them).
+func listOfTypes(value interface{}) []string {
+	if typ, ok := value.(string); ok {
+		return []string{typ}
+	}
+	var types []string
+	for _, item := range listOf(value) {
+		if typ, ok := item.(string); ok {
+			types = append(types, typ)
+		}
+	}
+	return types
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"carvel.dev/ytt/pkg/orderedmap"
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// schemaDefs tracks which maps of a schema are identical so that they can be declared once and referred to (in the
+// definitions of a JSON Schema, or among the schemas of the components of an OpenAPI document).
+type schemaDefs struct {
+	// collecting is true while measuring the schema; false while generating it.
+	collecting   bool
+	fingerprints map[*MapType]string
+	occurrences  map[string]int
+	mapTypes     []*MapType // fingerprinted, in the order they occur (as iterating over fingerprints is not ordered)
+
+	names    map[string]string
+	numbered []string // names given to (unnamed) maps, in order
+	prefix   string
+	items    []*yamlmeta.MapItem
+
+	enumOccurrences map[string]int    // of each enum, by its members (see enumKeyOf())
+	enumNames       map[string]string // of the definition of each repeated enum, by its members
+}
+
+// newSchemaDefs starts collecting the fingerprints of the maps of a schema (numbering definitions after `prefix`).
+func newSchemaDefs(prefix string) *schemaDefs {
+	return &schemaDefs{
+		collecting:   true,
+		fingerprints: map[*MapType]string{},
+		occurrences:  map[string]int{},
+		names:        map[string]string{},
+		prefix:       prefix,
+
+		enumOccurrences: map[string]int{},
+		enumNames:       map[string]string{},
+	}
+}
+
+// findRepeatedMapTypes generates the schema with every map inlined, recording the fingerprint of each map.
+func (j *JSONSchemaDocument) findRepeatedMapTypes() *schemaDefs {
+	j.defs = newSchemaDefs(j.defsPrefix)
+	j.calculateProperties(j.docType)
+	j.defs.collecting = false
+	return j.defs
+}
+
+// referenceIfRepeated returns a reference to the definition of `mapType` if an identical map occurs elsewhere in the
+// schema (or if it is named); otherwise, returns `schema` (i.e. the definition of `mapType`) itself.
+func (j *JSONSchemaDocument) referenceIfRepeated(mapType *MapType, schema *yamlmeta.Map) *yamlmeta.Map {
+	if j.defs == nil || mapType == j.docType.GetValueType() {
+		return schema
+	}
+	return j.defs.reference(mapType, schema, fmt.Sprintf("#/%s/", j.defsKey()))
+}
+
+// reference returns a reference (i.e. `pointerBase` followed by the name of the definition) to the definition of
+// `mapType` if an identical map occurs elsewhere in the schema (or if it is named); otherwise, returns `schema` (i.e.
+// the definition of `mapType`) itself.
+//
+// Maps are identical when their (fully inlined) schemas are, regardless of the order of their keys. Maps named via
+// @schema/schema-name are defined under that name (which is stable as the schema changes), rather than a numbered one.
+func (d *schemaDefs) reference(mapType *MapType, schema *yamlmeta.Map, pointerBase string) *yamlmeta.Map {
+	if len(mapType.Items) == 0 && mapType.schemaName == "" {
+		return schema
+	}
+	if d.collecting {
+		fingerprint := fingerprintOf(schema)
+		if _, found := d.fingerprints[mapType]; !found {
+			d.mapTypes = append(d.mapTypes, mapType)
+		}
+		d.fingerprints[mapType] = fingerprint
+		d.occurrences[fingerprint]++
+		return schema
+	}
+
+	fingerprint := d.fingerprints[mapType]
+	if d.occurrences[fingerprint] < 2 && mapType.schemaName == "" {
+		return schema
+	}
+	name, found := d.names[fingerprint]
+	if !found {
+		name = mapType.schemaName
+		if name == "" {
+			name = fmt.Sprintf("%sType%d", d.prefix, len(d.numbered)+1)
+			d.numbered = append(d.numbered, name)
+		}
+		d.names[fingerprint] = name
+		d.items = append(d.items, &yamlmeta.MapItem{Key: name, Value: schema})
+	}
+	return &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: refProp, Value: pointerBase + name}}}
+}
+
+// enumReferenceIfRepeated returns a reference to the definition of the enum of `values` if that same enum (i.e. of the
+// same members, in any order) occurs elsewhere in the schema and EnumRefs is set; otherwise, returns nil (i.e. the enum
+// is to be given inline).
+//
+// Before draft 2019-09, keywords alongside a "$ref" are ignored; there, enums are always given inline.
+func (j *JSONSchemaDocument) enumReferenceIfRepeated(values []interface{}) *yamlmeta.MapItem {
+	if j.defs == nil || !j.opts.EnumRefs || j.predatesDraft201909() {
+		return nil
+	}
+	return j.defs.enumReference(values, fmt.Sprintf("#/%s/", j.defsKey()))
+}
+
+// enumReference returns a reference (i.e. `pointerBase` followed by the name of the definition) to the definition of
+// the enum of `values` if it occurs more than once in the schema (defined as first given); otherwise, returns nil.
+func (d *schemaDefs) enumReference(values []interface{}, pointerBase string) *yamlmeta.MapItem {
+	key := enumKeyOf(values)
+	if d.collecting {
+		d.enumOccurrences[key]++
+		return nil
+	}
+	if d.enumOccurrences[key] < 2 {
+		return nil
+	}
+	name, found := d.enumNames[key]
+	if !found {
+		name = fmt.Sprintf("%sEnum%d", d.prefix, len(d.enumNames)+1)
+		d.enumNames[key] = name
+		d.items = append(d.items, &yamlmeta.MapItem{Key: name, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: enumProp, Value: values},
+		}}})
+	}
+	return &yamlmeta.MapItem{Key: refProp, Value: pointerBase + name}
+}
+
+// enumKeyOf identifies the enum of `values` by its members, regardless of their order: their (sorted) JSON encodings.
+func enumKeyOf(values []interface{}) string {
+	var members []string
+	for _, value := range values {
+		bs, err := json.Marshal(orderedmap.Conversion{Object: value}.AsUnorderedStringMaps())
+		if err != nil {
+			panic(fmt.Sprintf("Marshaling enum member to identify enum: %s", err))
+		}
+		members = append(members, string(bs))
+	}
+	sort.Strings(members)
+	return strings.Join(members, ",")
+}
+
+// checkSchemaNames ensures each schema name (see @schema/schema-name) is given to identical maps only; otherwise, those
+// maps could not all be defined under that name.
+func (j *JSONSchemaDocument) checkSchemaNames() error {
+	if j.opts.NoRefs {
+		return nil
+	}
+	defs := j.findRepeatedMapTypes()
+	j.defs = nil
+	return defs.checkSchemaNames()
+}
+
+// checkSchemaNames ensures each schema name is given to identical maps only (see JSONSchemaDocument.checkSchemaNames()).
+// Maps are checked in the order they occur, so that the first name given to maps that differ is the one reported.
+func (d *schemaDefs) checkSchemaNames() error {
+	fingerprints := map[string]string{}
+	for _, mapType := range d.mapTypes {
+		fingerprint := d.fingerprints[mapType]
+		if mapType.schemaName == "" {
+			continue
+		}
+		if other, found := fingerprints[mapType.schemaName]; found && other != fingerprint {
+			return fmt.Errorf("Schema name '%s' is given to maps that differ (a name can only identify one kind of map)", mapType.schemaName)
+		}
+		fingerprints[mapType.schemaName] = fingerprint
+	}
+	return nil
+}
+
+// anchorKeywords identify the schema of a map by `name`, independently of where it is declared: for draft-07 and
+// draft-04, a plain-name fragment "$id" (or "id"); otherwise, an "$anchor". When given, `id` identifies the schema as
+// a resource of its own (via "$id"), so that it can be dereferenced independently of the document; before draft
+// 2019-09, that "$id" takes the place of the plain-name fragment (as a schema has only one).
+func (j *JSONSchemaDocument) anchorKeywords(name, id string) []*yamlmeta.MapItem {
+	if j.predatesDraft201909() {
+		if id == "" {
+			id = "#" + name
+		}
+		return []*yamlmeta.MapItem{{Key: j.idKey(), Value: id}}
+	}
+	var items []*yamlmeta.MapItem
+	if id != "" {
+		items = append(items, &yamlmeta.MapItem{Key: j.idKey(), Value: id})
+	}
+	return append(items, &yamlmeta.MapItem{Key: anchorProp, Value: name})
+}
+
+// isExternalRef indicates whether `valueType` (looking through nullability) is a map exported as a reference to an
+// external schema (see @schema/external-ref).
+func isExternalRef(valueType Type) bool {
+	if nullType, isNullable := valueType.(*NullType); isNullable {
+		valueType = nullType.GetValueType()
+	}
+	mapType, isMap := valueType.(*MapType)
+	return isMap && mapType.externalRef != ""
+}
+
+// asMap produces the definitions section, if there is anything to define.
+func (d *schemaDefs) asMap() *yamlmeta.Map {
+	if d == nil || len(d.items) == 0 {
+		return nil
+	}
+	return &yamlmeta.Map{Items: d.items}
+}
+
+// fingerprintOf hashes the canonical (i.e. key-sorted) JSON encoding of `schema`.
+func fingerprintOf(schema *yamlmeta.Map) string {
+	doc := &yamlmeta.Document{Value: schema}
+	bs, err := json.Marshal(orderedmap.Conversion{Object: doc.AsInterface()}.AsUnorderedStringMaps())
+	if err != nil {
+		panic(fmt.Sprintf("Marshaling schema to fingerprint: %s", err))
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(bs))
+}
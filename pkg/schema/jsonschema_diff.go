@@ -0,0 +1,362 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// ChangeKind classifies a difference between two JSON Schemas.
+type ChangeKind string
+
+// Kinds of differences reported by SchemaDiff()
+const (
+	PropertyAdded        ChangeKind = "property added"
+	PropertyRemoved      ChangeKind = "property removed"
+	RequiredAdded        ChangeKind = "now required"
+	RequiredRemoved      ChangeKind = "no longer required"
+	TypeChanged          ChangeKind = "type changed"
+	ConstraintTightened  ChangeKind = "constraint tightened"
+	ConstraintLoosened   ChangeKind = "constraint loosened"
+	AllowedValuesChanged ChangeKind = "allowed values changed"
+)
+
+// Change is a difference between two JSON Schemas, at the value located by Path (e.g. "values.db.port").
+//
+// A change is breaking when data values that conform to the old schema might not conform to the new one.
+type Change struct {
+	Path     string
+	Kind     ChangeKind
+	Keyword  string // the keyword that changed (for constraints and allowed values)
+	Old      interface{}
+	New      interface{}
+	Breaking bool
+}
+
+// String describes this change for humans (e.g. "breaking: values.db.host: now required").
+func (c Change) String() string {
+	compat := "non-breaking"
+	if c.Breaking {
+		compat = "breaking"
+	}
+	desc := string(c.Kind)
+	switch c.Kind {
+	case TypeChanged:
+		desc = fmt.Sprintf("%s from %v to %v", c.Kind, asText(c.Old), asText(c.New))
+	case ConstraintTightened, ConstraintLoosened, AllowedValuesChanged:
+		desc = fmt.Sprintf("%s (%s: %v -> %v)", c.Kind, c.Keyword, asText(c.Old), asText(c.New))
+	}
+	return fmt.Sprintf("%s: %s: %s", compat, c.Path, desc)
+}
+
+// SchemaDiff compares two JSON Schema documents (as generated by JSONSchemaDocument), reporting how the values they
+// describe differ: properties added or removed, keys becoming (or ceasing to be) required, types changed, and
+// constraints tightened or loosened. Changes are listed as the schemas are walked: a value's own changes, then those of
+// its properties (in the order of the old schema, then those added), then those of its items.
+//
+// References to definitions (i.e. "$ref" into "$defs" or "definitions") are followed. Properties, items and nullable
+// alternatives (i.e. an "anyOf" with the "null" type) are compared; other composed schemas are not.
+func SchemaDiff(oldDoc, newDoc *yamlmeta.Document) []Change {
+	d := schemaDiffer{oldRoot: asSchemaMap(oldDoc.Value), newRoot: asSchemaMap(newDoc.Value)}
+	d.compare("values", d.oldRoot, d.newRoot)
+	return d.changes
+}
+
+type schemaDiffer struct {
+	oldRoot, newRoot *yamlmeta.Map
+	changes          []Change
+}
+
+func (d *schemaDiffer) add(change Change) {
+	d.changes = append(d.changes, change)
+}
+
+func (d *schemaDiffer) compare(path string, oldSchema, newSchema *yamlmeta.Map) {
+	oldSchema, oldNullable := nonNullAlternative(resolveRef(d.oldRoot, oldSchema))
+	newSchema, newNullable := nonNullAlternative(resolveRef(d.newRoot, newSchema))
+
+	oldTypes, newTypes := typesOf(oldSchema, oldNullable), typesOf(newSchema, newNullable)
+	if !reflect.DeepEqual(oldTypes, newTypes) && len(oldTypes) > 0 {
+		d.add(Change{Path: path, Kind: TypeChanged, Old: oldTypes, New: newTypes, Breaking: !isSubset(oldTypes, newTypes)})
+	}
+
+	d.compareConstraints(path, oldSchema, newSchema)
+	d.compareProperties(path, oldSchema, newSchema)
+
+	oldItems, oldHasItems := keywordOf(oldSchema, itemsProp)
+	newItems, newHasItems := keywordOf(newSchema, itemsProp)
+	if oldHasItems && newHasItems {
+		d.compare(path+"[]", asSchemaMap(oldItems), asSchemaMap(newItems))
+	}
+}
+
+func (d *schemaDiffer) compareProperties(path string, oldSchema, newSchema *yamlmeta.Map) {
+	oldRequired, newRequired := requiredSetOf(oldSchema), requiredSetOf(newSchema)
+	oldProps, newProps := propertiesOf(oldSchema), propertiesOf(newSchema)
+	additionalAllowed := !isFalse(keywordOrNil(newSchema, additionalPropsProp))
+
+	for _, item := range oldProps.Items {
+		propPath := fmt.Sprintf("%s.%v", path, item.Key)
+		newProp, found := keywordOf(newProps, fmt.Sprintf("%v", item.Key))
+		if !found {
+			// values giving the property are rejected, unless the map now allows any keys.
+			d.add(Change{Path: propPath, Kind: PropertyRemoved, Breaking: !additionalAllowed})
+			continue
+		}
+		d.compare(propPath, asSchemaMap(item.Value), asSchemaMap(newProp))
+		switch key := fmt.Sprintf("%v", item.Key); {
+		case newRequired[key] && !oldRequired[key]:
+			d.add(Change{Path: propPath, Kind: RequiredAdded, Breaking: true})
+		case oldRequired[key] && !newRequired[key]:
+			d.add(Change{Path: propPath, Kind: RequiredRemoved})
+		}
+	}
+	for _, item := range newProps.Items {
+		key := fmt.Sprintf("%v", item.Key)
+		if _, found := keywordOf(oldProps, key); !found {
+			// values lacking a newly required property are rejected.
+			d.add(Change{Path: fmt.Sprintf("%s.%s", path, key), Kind: PropertyAdded, Breaking: newRequired[key]})
+		}
+	}
+}
+
+// lowerBoundKeywords and upperBoundKeywords constrain values from below (resp. above): raising a lower bound (or
+// lowering an upper one) tightens the constraint.
+var lowerBoundKeywords = []string{minProp, exclusiveMinProp, minLenProp, minItemsProp, minContainsProp, minPropertiesProp}
+var upperBoundKeywords = []string{maxProp, exclusiveMaxProp, maxLenProp, maxItemsProp, maxContainsProp, maxPropertiesProp}
+
+func (d *schemaDiffer) compareConstraints(path string, oldSchema, newSchema *yamlmeta.Map) {
+	for _, keyword := range lowerBoundKeywords {
+		d.compareBound(path, keyword, oldSchema, newSchema, func(oldBound, newBound float64) bool { return newBound > oldBound })
+	}
+	for _, keyword := range upperBoundKeywords {
+		d.compareBound(path, keyword, oldSchema, newSchema, func(oldBound, newBound float64) bool { return newBound < oldBound })
+	}
+	// any other change of these constrains values in ways that cannot be compared: assume the worst.
+	for _, keyword := range []string{patternProp, notProp, multipleOfProp, formatProp, uniqueItemsProp, containsProp, propertyNamesProp} {
+		oldValue, oldFound := keywordOf(oldSchema, keyword)
+		newValue, newFound := keywordOf(newSchema, keyword)
+		switch {
+		case newFound && (!oldFound || !reflect.DeepEqual(asComparable(oldValue), asComparable(newValue))):
+			if keyword == uniqueItemsProp && isFalse(newValue) {
+				d.add(Change{Path: path, Kind: ConstraintLoosened, Keyword: keyword, Old: oldValue, New: newValue})
+				continue
+			}
+			d.add(Change{Path: path, Kind: ConstraintTightened, Keyword: keyword, Old: oldValue, New: newValue, Breaking: true})
+		case oldFound && !newFound:
+			d.add(Change{Path: path, Kind: ConstraintLoosened, Keyword: keyword, Old: oldValue})
+		}
+	}
+	d.compareAllowedValues(path, oldSchema, newSchema)
+}
+
+func (d *schemaDiffer) compareBound(path, keyword string, oldSchema, newSchema *yamlmeta.Map, tighter func(oldBound, newBound float64) bool) {
+	oldValue, oldFound := keywordOf(oldSchema, keyword)
+	newValue, newFound := keywordOf(newSchema, keyword)
+	oldBound, oldIsNum := asFloat(oldValue)
+	newBound, newIsNum := asFloat(newValue)
+	switch {
+	case !oldFound && !newFound:
+	case !oldFound && newIsNum:
+		d.add(Change{Path: path, Kind: ConstraintTightened, Keyword: keyword, New: newValue, Breaking: true})
+	case !newFound && oldIsNum:
+		d.add(Change{Path: path, Kind: ConstraintLoosened, Keyword: keyword, Old: oldValue})
+	case oldIsNum && newIsNum && oldBound != newBound:
+		if tighter(oldBound, newBound) {
+			d.add(Change{Path: path, Kind: ConstraintTightened, Keyword: keyword, Old: oldValue, New: newValue, Breaking: true})
+		} else {
+			d.add(Change{Path: path, Kind: ConstraintLoosened, Keyword: keyword, Old: oldValue, New: newValue})
+		}
+	}
+}
+
+// compareAllowedValues reports changes to "enum" (or "const"): removing an allowed value is breaking.
+func (d *schemaDiffer) compareAllowedValues(path string, oldSchema, newSchema *yamlmeta.Map) {
+	oldAllowed, oldFound := allowedValuesOf(oldSchema)
+	newAllowed, newFound := allowedValuesOf(newSchema)
+	if !oldFound && !newFound {
+		return
+	}
+	if oldFound && newFound && reflect.DeepEqual(oldAllowed, newAllowed) {
+		return
+	}
+	breaking := newFound
+	if oldFound && newFound {
+		breaking = false
+		for _, value := range oldAllowed {
+			if !containsValue(newAllowed, value) {
+				breaking = true
+			}
+		}
+	}
+	d.add(Change{Path: path, Kind: AllowedValuesChanged, Keyword: enumProp, Old: oldAllowed, New: newAllowed, Breaking: breaking})
+}
+
+// resolveRef follows `schema` (if it is a reference) to its definition in `root`.
+func resolveRef(root, schema *yamlmeta.Map) *yamlmeta.Map {
+	for i := 0; i < 10; i++ {
+		ref, found := keywordOf(schema, refProp)
+		if !found {
+			return schema
+		}
+		pointer, ok := ref.(string)
+		if !ok || !strings.HasPrefix(pointer, "#/") {
+			return schema
+		}
+		target := root
+		for _, segment := range strings.Split(strings.TrimPrefix(pointer, "#/"), "/") {
+			value, _ := keywordOf(target, segment)
+			target = asSchemaMap(value)
+		}
+		schema = target
+	}
+	return schema
+}
+
+// nonNullAlternative unwraps a nullable schema expressed as an "anyOf" of it and the "null" type.
+func nonNullAlternative(schema *yamlmeta.Map) (*yamlmeta.Map, bool) {
+	anyOf, found := keywordOf(schema, anyOfProp)
+	if !found {
+		return schema, false
+	}
+	alternatives := listOf(anyOf)
+	if len(alternatives) != 2 {
+		return schema, false
+	}
+	for i, alternative := range alternatives {
+		if t, _ := keywordOf(asSchemaMap(alternative), typeProp); t == "null" {
+			return asSchemaMap(alternatives[1-i]), true
+		}
+	}
+	return schema, false
+}
+
+// typesOf lists (sorted) the types permitted by `schema`: those of "type", along with "null" if `nullable`.
+func typesOf(schema *yamlmeta.Map, nullable bool) []string {
+	var types []string
+	value, _ := keywordOf(schema, typeProp)
+	if str, ok := value.(string); ok {
+		types = append(types, str)
+	}
+	for _, item := range listOf(value) {
+		types = append(types, fmt.Sprintf("%v", item))
+	}
+	if isTrue(keywordOrNil(schema, nullableProp)) || nullable {
+		types = append(types, "null")
+	}
+	sort.Strings(types)
+	return types
+}
+
+func isSubset(subset, set []string) bool {
+	for _, candidate := range subset {
+		found := false
+		for _, item := range set {
+			// an integer is also a number.
+			if candidate == item || (candidate == "integer" && item == "number") {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func propertiesOf(schema *yamlmeta.Map) *yamlmeta.Map {
+	value, _ := keywordOf(schema, propertiesProp)
+	return asSchemaMap(value)
+}
+
+func requiredSetOf(schema *yamlmeta.Map) map[string]bool {
+	required := map[string]bool{}
+	value, _ := keywordOf(schema, requiredProp)
+	for _, key := range listOf(value) {
+		required[fmt.Sprintf("%v", key)] = true
+	}
+	return required
+}
+
+func allowedValuesOf(schema *yamlmeta.Map) ([]interface{}, bool) {
+	if value, found := keywordOf(schema, constProp); found {
+		return []interface{}{asComparable(value)}, true
+	}
+	if value, found := keywordOf(schema, enumProp); found {
+		var values []interface{}
+		for _, item := range listOf(value) {
+			values = append(values, asComparable(item))
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+func keywordOf(schema *yamlmeta.Map, keyword string) (interface{}, bool) {
+	for _, item := range schema.Items {
+		if item.Key == keyword {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func keywordOrNil(schema *yamlmeta.Map, keyword string) interface{} {
+	value, _ := keywordOf(schema, keyword)
+	return value
+}
+
+// asSchemaMap treats `value` as a schema; anything other than a map (e.g. `true`) constrains nothing we compare.
+func asSchemaMap(value interface{}) *yamlmeta.Map {
+	if m, ok := value.(*yamlmeta.Map); ok {
+		return m
+	}
+	return &yamlmeta.Map{}
+}
+
+// listOf returns the items of `value`, whether a generated list or one parsed from YAML (or JSON).
+func listOf(value interface{}) []interface{} {
+	switch typedValue := value.(type) {
+	case []interface{}:
+		return typedValue
+	case *yamlmeta.Array:
+		var items []interface{}
+		for _, item := range typedValue.Items {
+			items = append(items, item.Value)
+		}
+		return items
+	}
+	return nil
+}
+
+// asComparable converts nodes into plain values, so that schemas generated and parsed compare alike.
+func asComparable(value interface{}) interface{} {
+	if node, ok := value.(yamlmeta.Node); ok {
+		return node.DeepCopyAsInterface()
+	}
+	return value
+}
+
+func isTrue(value interface{}) bool {
+	b, ok := value.(bool)
+	return ok && b
+}
+
+func isFalse(value interface{}) bool {
+	b, ok := value.(bool)
+	return ok && !b
+}
+
+// asText renders a value of a change for humans (e.g. a list of types as "[integer string]").
+func asText(value interface{}) interface{} {
+	if value == nil {
+		return "(none)"
+	}
+	return asComparable(value)
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// JSONSchemaDocuments holds the document types used for creating a single JSON Schema document that describes each
+// document of a stream (i.e. any one of those types).
+type JSONSchemaDocuments struct {
+	docs []*JSONSchemaDocument
+}
+
+// NewJSONSchemaDocuments creates an instance of a JSONSchemaDocuments based on the given DocumentTypes
+//
+// Returns an error if `docTypes` is empty, or if `opts` targets an unsupported draft.
+func NewJSONSchemaDocuments(docTypes []*DocumentType, opts JSONSchemaOpts) (*JSONSchemaDocuments, error) {
+	if len(docTypes) == 0 {
+		return nil, fmt.Errorf("Expected at least one document to describe")
+	}
+	var docs []*JSONSchemaDocument
+	for i, docType := range docTypes {
+		doc, err := NewJSONSchemaDocument(docType, opts)
+		if err != nil {
+			return nil, err
+		}
+		doc.defsPrefix = fmt.Sprintf("Document%d", i+1)
+		docs = append(docs, doc)
+	}
+	return &JSONSchemaDocuments{docs: docs}, nil
+}
+
+// AsDocument generates a new AST of this JSON Schema document: the schema shared by all documents, if they are alike;
+// otherwise, a choice ("oneOf") between the schema of each document.
+//
+// Definitions of repeated maps are declared at the root, named after the document in which they occur (except for maps
+// named via @schema/schema-name, which are declared once).
+func (j *JSONSchemaDocuments) AsDocument() *yamlmeta.Document {
+	docs := j.docs
+	if j.areAlike() {
+		docs = docs[:1]
+	}
+
+	var choices []interface{}
+	var defs []*yamlmeta.MapItem
+	var result *yamlmeta.Map
+	defined := map[interface{}]bool{}
+	for _, doc := range docs {
+		result = doc.asSchema()
+		choices = append(choices, result)
+		if doc.defs != nil {
+			for _, def := range doc.defs.items {
+				if !defined[def.Key] {
+					defined[def.Key] = true
+					defs = append(defs, def)
+				}
+			}
+		}
+	}
+	if len(choices) > 1 {
+		result = &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: oneOfProp, Value: choices}}}
+	}
+	if len(defs) > 0 {
+		result.Items = append(result.Items, &yamlmeta.MapItem{Key: j.docs[0].defsKey(), Value: &yamlmeta.Map{Items: defs}})
+	}
+	j.docs[0].rewriteGenerated(result)
+	return &yamlmeta.Document{Value: j.docs[0].withMetaKeywords(result, "Schema for documents, generated by ytt")}
+}
+
+// areAlike indicates whether all documents are described by the same schema (regardless of the order of their keys).
+//
+// Schemas are compared fully inlined, as references are named after the document in which they occur.
+func (j *JSONSchemaDocuments) areAlike() bool {
+	inlinedFingerprintOf := func(doc *JSONSchemaDocument) string {
+		inlined := &JSONSchemaDocument{OpenAPIDocument: doc.OpenAPIDocument, opts: doc.opts}
+		inlined.opts.NoRefs = true
+		return fingerprintOf(inlined.asSchema())
+	}
+	first := inlinedFingerprintOf(j.docs[0])
+	for _, doc := range j.docs[1:] {
+		if inlinedFingerprintOf(doc) != first {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"carvel.dev/ytt/pkg/orderedmap"
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// JSONTabIndent, given to AsJSON(), indents each level by a tab (rather than by spaces).
+const JSONTabIndent = -1
+
+// AsJSON serializes this JSON Schema document (see AsDocument()) as JSON, with keywords in the order they are
+// generated (see JSONSchemaOpts.KeywordLess).
+//
+// Each level is indented by `indent` spaces (or by a tab, given JSONTabIndent); an `indent` of 0 gives compact JSON.
+func (j *JSONSchemaDocument) AsJSON(indent int) ([]byte, error) {
+	return DocumentAsJSON(j.AsDocument(), indent)
+}
+
+// DocumentAsJSON serializes `doc` (a JSON Schema, e.g. one of AsSplitDocuments() or that of AsSingleFileDocument()) as
+// JSON, as AsJSON() does: keeping the order of its keywords, and indenting each level by `indent`.
+func DocumentAsJSON(doc *yamlmeta.Document, indent int) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, doc.AsInterface()); err != nil {
+		return nil, fmt.Errorf("Marshaling JSON Schema: %s", err)
+	}
+	if indent == 0 {
+		return compact.Bytes(), nil
+	}
+
+	indentStr := "\t"
+	if indent > 0 {
+		indentStr = strings.Repeat(" ", indent)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", indentStr); err != nil {
+		return nil, fmt.Errorf("Indenting JSON Schema: %s", err)
+	}
+	return indented.Bytes(), nil
+}
+
+// writeJSON encodes `value` (as converted from an AST) as compact JSON, keeping the order of the keys of each map
+// (which encoding/json would otherwise sort).
+func writeJSON(buf *bytes.Buffer, value interface{}) error {
+	switch typedValue := value.(type) {
+	case *orderedmap.Map:
+		buf.WriteByte('{')
+		i := 0
+		err := typedValue.IterateErr(func(k, v interface{}) error {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			i++
+			if err := writeJSONScalar(buf, fmt.Sprintf("%v", k)); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			return writeJSON(buf, v)
+		})
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range typedValue {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+
+	default:
+		return writeJSONScalar(buf, value)
+	}
+}
+
+// writeJSONScalar encodes `value` as is (i.e. without escaping HTML characters, which may well appear in patterns).
+func writeJSONScalar(buf *bytes.Buffer, value interface{}) error {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
+
+// CanonicalBytes serializes this JSON Schema document as compact JSON in a canonical form: the keys of every map
+// sorted, as well as the items of each "enum", "required" and "type" list (but not those of values: defaults,
+// examples and constants).
+//
+// Generating the same schema always gives the same canonical bytes, regardless of how it is configured to be ordered
+// (e.g. via KeywordLess); they suit comparing schemas, or golden files that should not churn.
+func (j *JSONSchemaDocument) CanonicalBytes() ([]byte, error) {
+	value := orderedmap.Conversion{Object: j.AsDocument().AsInterface()}.AsUnorderedStringMaps()
+	canonical, err := canonicalOf(value, false)
+	if err != nil {
+		return nil, fmt.Errorf("Marshaling JSON Schema: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := writeJSONScalar(&buf, canonical); err != nil {
+		return nil, fmt.Errorf("Marshaling JSON Schema: %s", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unorderedKeywords are those whose lists of items have no meaningful order.
+var unorderedKeywords = map[string]bool{enumProp: true, requiredProp: true, typeProp: true}
+
+// valueKeywords are those given values (rather than schemas), whose content is kept as is.
+var valueKeywords = map[string]bool{defaultProp: true, examplesProp: true, constProp: true, enumProp: true}
+
+// isValueKeyword indicates whether `keyword` is given a value (see valueKeywords), as vendor extensions also are.
+func isValueKeyword(keyword string) bool {
+	return valueKeywords[keyword] || strings.HasPrefix(keyword, extensionPrefix)
+}
+
+// canonicalOf sorts the set-like lists within `value` (a schema, unless `isValue`); maps are sorted once encoded.
+func canonicalOf(value interface{}, isValue bool) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, item := range typedValue {
+			canonicalItem, err := canonicalOf(item, isValue || isValueKeyword(key))
+			if err != nil {
+				return nil, err
+			}
+			if list, isList := canonicalItem.([]interface{}); isList && !isValue && unorderedKeywords[key] {
+				if canonicalItem, err = sortedByJSON(list); err != nil {
+					return nil, err
+				}
+			}
+			result[key] = canonicalItem
+		}
+		return result, nil
+
+	case []interface{}:
+		var result []interface{}
+		for _, item := range typedValue {
+			canonicalItem, err := canonicalOf(item, isValue)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, canonicalItem)
+		}
+		return result, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// sortedByJSON sorts (a copy of) `items` by their (canonical) JSON encoding.
+func sortedByJSON(items []interface{}) ([]interface{}, error) {
+	type encodedItem struct {
+		item    interface{}
+		encoded string
+	}
+	var encodedItems []encodedItem
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := writeJSONScalar(&buf, item); err != nil {
+			return nil, err
+		}
+		encodedItems = append(encodedItems, encodedItem{item, buf.String()})
+	}
+	sort.SliceStable(encodedItems, func(i, k int) bool { return encodedItems[i].encoded < encodedItems[k].encoded })
+	var result []interface{}
+	for _, encoded := range encodedItems {
+		result = append(result, encoded.item)
+	}
+	return result, nil
+}
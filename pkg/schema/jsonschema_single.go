@@ -0,0 +1,183 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// JSONSchemaFetcher retrieves the external schema at `uri` (e.g. one referred to via @schema/external-ref).
+type JSONSchemaFetcher func(uri string) (*yamlmeta.Document, error)
+
+// AsSingleFileDocument generates the AST of this JSON Schema document as a single, self-contained, schema: each
+// external schema it refers to (see @schema/external-ref) is retrieved via `fetch` and declared among the definitions
+// (named after the last segment of its path), references to it referring to that definition instead.
+//
+// External schemas lose their `$schema` and `$id`, so that their own references resolve within the document; those
+// to yet other external schemas (resolved relative to the URI of the schema referring to them) are inlined alike.
+//
+// Returns an error if an external schema cannot be fetched, is not a schema, or is referred to by an anchor (i.e.
+// some fragment other than a JSON Pointer).
+func (j *JSONSchemaDocument) AsSingleFileDocument(fetch JSONSchemaFetcher) (*yamlmeta.Document, error) {
+	doc := j.AsDocument()
+	root := doc.Value.(*yamlmeta.Map)
+
+	inliner := &schemaInliner{fetch: fetch, defsKey: j.defsKey(), names: map[string]string{}, taken: map[string]bool{}}
+	defs, hasDefs := keywordOf(root, j.defsKey())
+	for _, def := range asSchemaMap(defs).Items {
+		inliner.taken[fmt.Sprintf("%v", def.Key)] = true
+	}
+	if err := inliner.referInternally(root, nil, ""); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(inliner.pending); i++ {
+		uri := inliner.pending[i]
+		schema, err := inliner.fetchSchema(uri)
+		if err != nil {
+			return nil, err
+		}
+		base, _ := url.Parse(uri) // (parsed as it was referred to)
+		if err := inliner.referInternally(schema, base, inliner.pointerTo(inliner.names[uri])); err != nil {
+			return nil, err
+		}
+		inliner.defs = append(inliner.defs, &yamlmeta.MapItem{Key: inliner.names[uri], Value: schema})
+	}
+	if len(inliner.defs) == 0 {
+		return doc, nil
+	}
+
+	if !hasDefs {
+		defs = &yamlmeta.Map{}
+		root.Items = append(root.Items, &yamlmeta.MapItem{Key: j.defsKey(), Value: defs})
+	}
+	defsMap := defs.(*yamlmeta.Map)
+	defsMap.Items = append(defsMap.Items, inliner.defs...)
+	return doc, nil
+}
+
+// schemaInliner declares the external schemas referred to within a schema among its definitions.
+type schemaInliner struct {
+	fetch   JSONSchemaFetcher
+	defsKey string
+
+	names   map[string]string // of the definition of each external schema, by URI (without fragment)
+	taken   map[string]bool   // names of definitions already declared
+	pending []string          // URIs of external schemas, in the order they are first referred to
+	defs    []*yamlmeta.MapItem
+}
+
+// referInternally rewrites every reference within `node` to refer within the document: those to an external schema,
+// to its definition; those within an external schema (whose definition is at `self`, and URI is `base`), to within
+// that definition. (References of the document itself, i.e. when `base` is nil, already are.)
+func (s *schemaInliner) referInternally(node interface{}, base *url.URL, self string) error {
+	switch typedNode := node.(type) {
+	case *yamlmeta.Map:
+		for _, item := range typedNode.Items {
+			if ref, isString := item.Value.(string); isString && item.Key == refProp {
+				internalRef, err := s.internalRef(ref, base, self)
+				if err != nil {
+					return err
+				}
+				item.Value = internalRef
+				continue
+			}
+			if err := s.referInternally(item.Value, base, self); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, value := range typedNode {
+			if err := s.referInternally(value, base, self); err != nil {
+				return err
+			}
+		}
+	case *yamlmeta.Array:
+		for _, item := range typedNode.Items {
+			if err := s.referInternally(item.Value, base, self); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *schemaInliner) internalRef(ref string, base *url.URL, self string) (string, error) {
+	if strings.HasPrefix(ref, "#") {
+		if base == nil {
+			return ref, nil
+		}
+		return s.withFragment(self, ref, ref)
+	}
+	uri, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("Expected reference '%s' to be a URI: %s", ref, err)
+	}
+	if base != nil {
+		uri = base.ResolveReference(uri)
+	}
+	fragment := uri.Fragment
+	uri.Fragment = ""
+
+	name, found := s.names[uri.String()]
+	if !found {
+		name = s.nameFor(uri)
+		s.names[uri.String()] = name
+		s.pending = append(s.pending, uri.String())
+	}
+	return s.withFragment(s.pointerTo(name), "#"+fragment, ref)
+}
+
+// withFragment appends to `pointer` (to a definition) the JSON Pointer of `fragment` (e.g. "#/properties/a"), which
+// is part of `ref`.
+func (s *schemaInliner) withFragment(pointer, fragment, ref string) (string, error) {
+	fragment = strings.TrimPrefix(fragment, "#")
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		return "", fmt.Errorf("Unable to refer internally to '%s': only references by JSON Pointer (rather than by anchor) can be", ref)
+	}
+	return pointer + fragment, nil
+}
+
+// pointerTo gives the reference to the definition named `name`.
+func (s *schemaInliner) pointerTo(name string) string {
+	return fmt.Sprintf("#/%s/%s", s.defsKey, escapeJSONPointer(name))
+}
+
+// nameFor names the definition of the external schema at `uri` after the last segment of its path (without
+// extension), e.g. "requests" for "https://example.com/schemas/requests.json"; suffixed by a number, if taken.
+func (s *schemaInliner) nameFor(uri *url.URL) string {
+	name := strings.TrimSuffix(path.Base(uri.Path), path.Ext(uri.Path))
+	if name == "" || name == "." || name == "/" {
+		name = "external"
+	}
+	unique := name
+	for i := 2; s.taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	s.taken[unique] = true
+	return unique
+}
+
+// fetchSchema retrieves the external schema at `uri`, without the keywords identifying it as a document of its own.
+func (s *schemaInliner) fetchSchema(uri string) (*yamlmeta.Map, error) {
+	doc, err := s.fetch(uri)
+	if err != nil {
+		return nil, fmt.Errorf("Fetching external schema '%s': %s", uri, err)
+	}
+	schema, isMap := doc.Value.(*yamlmeta.Map)
+	if !isMap {
+		return nil, fmt.Errorf("Expected external schema '%s' to be a map, but was %s", uri, yamlmeta.TypeName(doc.Value))
+	}
+	var items []*yamlmeta.MapItem
+	for _, item := range schema.Items {
+		if item.Key != schemaProp && item.Key != idProp && item.Key != id04Prop {
+			items = append(items, item)
+		}
+	}
+	return &yamlmeta.Map{Items: items}, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// JSONSchemaFile is one of the documents of a JSON Schema split into several files (see AsSplitDocuments).
+type JSONSchemaFile struct {
+	// Name is the name of the file, which the other files refer to (relative to the directory holding them all).
+	Name     string
+	Document *yamlmeta.Document
+}
+
+// AsSplitDocuments generates the AST of this JSON Schema document split into files: the root schema (named
+// `rootName`), followed by a file for each of its definitions (named by `fileNameOf`, after the name of that
+// definition). References to definitions refer to the files declaring them, relative to the file referring to them.
+//
+// With `NoRefs` set, there are no definitions: the root schema is the only file.
+func (j *JSONSchemaDocument) AsSplitDocuments(rootName string, fileNameOf func(defName string) string) []JSONSchemaFile {
+	root := j.asSchema()
+	pointerBase := fmt.Sprintf("#/%s/", j.defsKey())
+
+	var defFiles []JSONSchemaFile
+	var defs []*yamlmeta.MapItem
+	if j.defs != nil {
+		defs = j.defs.items
+	}
+	for _, def := range defs {
+		schema := def.Value.(*yamlmeta.Map)
+		j.rewriteGenerated(schema)
+		referToFiles(schema, pointerBase, fileNameOf)
+		if !j.opts.Embedded {
+			schema.Items = append([]*yamlmeta.MapItem{{Key: schemaProp, Value: jsonSchemaDraftURIs[j.opts.Draft]}}, schema.Items...)
+		}
+		defFiles = append(defFiles, JSONSchemaFile{Name: fileNameOf(def.Key.(string)), Document: &yamlmeta.Document{Value: schema}})
+	}
+
+	j.rewriteGenerated(root)
+	referToFiles(root, pointerBase, fileNameOf)
+	rootFile := JSONSchemaFile{Name: rootName, Document: &yamlmeta.Document{Value: j.withMetaKeywords(root, "Schema for data values, generated by ytt")}}
+	return append([]JSONSchemaFile{rootFile}, defFiles...)
+}
+
+// referToFiles rewrites every reference (within `node`) to a definition (i.e. starting with `pointerBase`) into the
+// name of the file declaring it.
+func referToFiles(node interface{}, pointerBase string, fileNameOf func(defName string) string) {
+	switch typedNode := node.(type) {
+	case *yamlmeta.Map:
+		for _, item := range typedNode.Items {
+			if ref, isString := item.Value.(string); isString && item.Key == refProp && strings.HasPrefix(ref, pointerBase) {
+				item.Value = fileNameOf(strings.TrimPrefix(ref, pointerBase))
+				continue
+			}
+			referToFiles(item.Value, pointerBase, fileNameOf)
+		}
+	case []interface{}:
+		for _, value := range typedNode {
+			referToFiles(value, pointerBase, fileNameOf)
+		}
+	case *yamlmeta.Array:
+		for _, item := range typedNode.Items {
+			referToFiles(item.Value, pointerBase, fileNameOf)
+		}
+	}
+}
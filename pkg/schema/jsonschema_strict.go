@@ -0,0 +1,280 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+
+	"carvel.dev/ytt/pkg/filepos"
+	"carvel.dev/ytt/pkg/template"
+	"carvel.dev/ytt/pkg/yamlmeta"
+)
+
+// formatsUndefinedByDraft lists, for each draft preceding the default one, the known formats (see knownFormats) of
+// JSON Schema that the draft does not define.
+var formatsUndefinedByDraft = map[string][]string{
+	JSONSchemaDraft07: {"duration", "uuid"},
+	JSONSchemaDraft04: {
+		"date", "time", "duration", "idn-email", "idn-hostname", "uri-reference", "iri", "iri-reference",
+		"uri-template", "uuid", "json-pointer", "relative-json-pointer", "regex",
+	},
+}
+
+// checkDraftSupport reports the first value in the schema given a feature that the targeted draft cannot express, by
+// the annotation giving it.
+//
+// Features that the targeted draft expresses otherwise are rewritten when exported, rather than reported: for
+// draft-07 and draft-04, definitions are declared under "definitions" (rather than "$defs"), dependent keys are
+// required via "dependencies" (rather than "dependentRequired"), and named maps are identified by "$id" (rather than
+// "$anchor"); in draft-04, a single allowed value is an "enum" of one, and exclusive bounds are boolean modifiers.
+//
+// Draft-04 also lacks keywords that later drafts added for annotations (see checkDraft04Support()); nor can defaults
+// be given as examples there (see ExamplesFromDefaults and DefaultsAsExamples).
+func (j *JSONSchemaDocument) checkDraftSupport() error {
+	if !j.predatesDraft201909() {
+		return nil
+	}
+	if j.opts.Draft == JSONSchemaDraft04 && (j.opts.ExamplesFromDefaults || j.opts.DefaultsAsExamples) {
+		return fmt.Errorf("Unable to give defaults as examples in JSON Schema %s, which has no '%s' keyword (to export the schema without them, set --json-schema-drop-unsupported)", j.opts.Draft, examplesProp)
+	}
+	return j.checkDraftSupportIn(j.docType)
+}
+
+func (j *JSONSchemaDocument) checkDraftSupportIn(typ Type) error {
+	if err := j.checkDraft04Support(typ); err != nil {
+		return err
+	}
+	switch typedValue := typ.(type) {
+	case *DocumentType, *NullType:
+		return j.checkDraftSupportIn(typedValue.GetValueType())
+	case *MapItemType, *ArrayItemType:
+		if err := j.checkDeprecationSupport(typedValue); err != nil {
+			return err
+		}
+		return j.checkDraftSupportIn(typedValue.GetValueType())
+	case *MapType:
+		for _, item := range typedValue.Items {
+			if err := j.checkDraftSupportIn(item); err != nil {
+				return err
+			}
+		}
+		if typedValue.extraPropertiesType != nil {
+			if err := j.checkDraftSupportIn(typedValue.extraPropertiesType); err != nil {
+				return err
+			}
+		}
+		if typedValue.patternProperty != nil {
+			return j.checkDraftSupportIn(typedValue.patternProperty.valueType)
+		}
+	case *ArrayType:
+		for _, itemType := range typedValue.itemTypes() {
+			if err := j.checkDraftSupportIn(itemType); err != nil {
+				return err
+			}
+		}
+	case *ScalarType:
+		if typedValue.format == nil {
+			return nil
+		}
+		for _, format := range formatsUndefinedByDraft[j.opts.Draft] {
+			if typedValue.format.format == format {
+				return NewSchemaError("Invalid schema", schemaAssertionError{
+					annPositions: []*filepos.Position{typedValue.format.pos},
+					position:     typedValue.GetDefinitionPosition(),
+					description:  fmt.Sprintf("format in @%v not defined by JSON Schema %s", AnnotationFormat, j.opts.Draft),
+					expected:     fmt.Sprintf("a format of %s", j.opts.Draft),
+					found:        format,
+					hints:        j.draftSupportHints("to give that format"),
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// checkDeprecationSupport reports `typedValue` (a map item or an array item) if its value is exported as deprecated:
+// drafts before 2019-09 have no "deprecated" keyword.
+func (j *JSONSchemaDocument) checkDeprecationSupport(typedValue Type) error {
+	annName := AnnotationDeprecated
+	if isDeprecated, _ := typedValue.GetValueType().IsDeprecated(); !isDeprecated {
+		if !j.opts.DeprecatedFromValidations || !isDeprecatedByValidation(typedValue) {
+			return nil
+		}
+		annName = AnnotationValidation
+	}
+	return NewSchemaError("Invalid schema", schemaAssertionError{
+		position:    typedValue.GetDefinitionPosition(),
+		description: fmt.Sprintf("deprecation (via @%v) not expressed by JSON Schema %s", annName, j.opts.Draft),
+		expected:    fmt.Sprintf("a keyword of %s", j.opts.Draft),
+		found:       deprecatedProp,
+		hints:       j.draftSupportHints("to mark values as deprecated"),
+	})
+}
+
+// checkDraft04Support reports `typedValue` if, when targeting draft-04, it is given (via an annotation) a keyword that
+// only later drafts define: "$comment", "examples", "readOnly", "writeOnly", "if"/"then" (via @schema/when, or
+// @schema/discriminator on the items of an array), "propertyNames" (via @schema/key-pattern), "contentEncoding" or
+// "contentMediaType". ("const" is always rewritten into an "enum" of one there.)
+func (j *JSONSchemaDocument) checkDraft04Support(typedValue Type) error {
+	if j.opts.Draft != JSONSchemaDraft04 {
+		return nil
+	}
+	var annName template.AnnotationName
+	var keyword string
+	readOnly, writeOnly := typedValue.GetAccess()
+	switch {
+	case typedValue.GetComment() != "":
+		annName, keyword = AnnotationComment, commentProp
+	case len(typedValue.GetExamples()) != 0:
+		annName, keyword = AnnotationExamples, examplesProp
+	case readOnly:
+		annName, keyword = AnnotationReadOnly, readOnlyProp
+	case writeOnly:
+		annName, keyword = AnnotationWriteOnly, writeOnlyProp
+	}
+	switch typedValue := typedValue.(type) {
+	case *MapType:
+		if typedValue.condition != nil {
+			annName, keyword = AnnotationWhen, ifProp
+		} else if typedValue.keyPattern != "" {
+			annName, keyword = AnnotationKeyPattern, propertyNamesProp
+		}
+	case *ArrayType:
+		if typedValue.tupleItems == nil && discriminatedCondition(typedValue.GetValueType().GetValueType()) != nil {
+			annName, keyword = AnnotationDiscriminator, ifProp
+		}
+	case *ScalarType:
+		if typedValue.contentEncoding != "" {
+			annName, keyword = AnnotationContentEncoding, contentEncodingProp
+		} else if typedValue.contentMediaType != "" {
+			annName, keyword = AnnotationContentMediaType, contentMediaTypeProp
+		}
+	}
+	if keyword == "" {
+		return nil
+	}
+	return NewSchemaError("Invalid schema", schemaAssertionError{
+		position:    typedValue.GetDefinitionPosition(),
+		description: fmt.Sprintf("%s (via @%v) not expressed by JSON Schema %s", keyword, annName, j.opts.Draft),
+		expected:    fmt.Sprintf("a keyword of %s", j.opts.Draft),
+		found:       keyword,
+		hints:       j.draftSupportHints(fmt.Sprintf("to give '%s'", keyword)),
+	})
+}
+
+func (j *JSONSchemaDocument) draftSupportHints(purpose string) []string {
+	return []string{
+		fmt.Sprintf("%s, target JSON Schema %s (i.e. --json-schema-draft=%s).", purpose, JSONSchemaDraft202012, JSONSchemaDraft202012),
+		fmt.Sprintf("to export the schema without it (as %s validators would ignore it), set --json-schema-drop-unsupported.", j.opts.Draft),
+	}
+}
+
+// unsupportedKeywordsByDraft lists, for each draft preceding 2019-09, the keywords generated that the draft does not
+// define (see checkDraftSupport()), to drop when DropUnsupported.
+var unsupportedKeywordsByDraft = map[string][]string{
+	JSONSchemaDraft07: {deprecatedProp},
+	JSONSchemaDraft04: {
+		deprecatedProp, commentProp, examplesProp, readOnlyProp, writeOnlyProp, ifProp, thenProp, propertyNamesProp,
+		contentEncodingProp, contentMediaTypeProp,
+	},
+}
+
+// dropUnsupportedKeywords removes, throughout `value` (a generated schema), the keywords that the targeted draft does
+// not define, as well as formats it does not define. (Values, e.g. defaults, are left as they are.)
+func (j *JSONSchemaDocument) dropUnsupportedKeywords(value interface{}) {
+	switch typedValue := value.(type) {
+	case *yamlmeta.Map:
+		var items []*yamlmeta.MapItem
+		for _, item := range typedValue.Items {
+			key, _ := item.Key.(string)
+			if j.isUnsupportedKeyword(key, item.Value) {
+				continue
+			}
+			switch {
+			case isValueKeyword(key):
+			case key == propertiesProp || key == patternPropertiesProp || key == defsProp || key == defs07Prop:
+				// (subschemas by name)
+				for _, named := range asSchemaMap(item.Value).Items {
+					j.dropUnsupportedKeywords(named.Value)
+				}
+			default:
+				j.dropUnsupportedKeywords(item.Value)
+			}
+			items = append(items, item)
+		}
+		typedValue.Items = items
+	case []interface{}:
+		for _, item := range typedValue {
+			j.dropUnsupportedKeywords(item)
+		}
+	}
+}
+
+// isUnsupportedKeyword indicates whether the keyword `key` (given `value`) is not defined by the targeted draft.
+func (j *JSONSchemaDocument) isUnsupportedKeyword(key string, value interface{}) bool {
+	for _, keyword := range unsupportedKeywordsByDraft[j.opts.Draft] {
+		if key == keyword {
+			return true
+		}
+	}
+	if key == formatProp {
+		for _, format := range formatsUndefinedByDraft[j.opts.Draft] {
+			if value == format {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkContainsSupport reports the first array required to contain some item (via contains=) when the targeted draft
+// precedes 2019-09, even when DropUnsupported: such drafts have no "minContains" nor "maxContains" (and
+// draft-04, no "contains" either).
+func (j *JSONSchemaDocument) checkContainsSupport() error {
+	if !j.predatesDraft201909() {
+		return nil
+	}
+	return j.checkContainsSupportIn(j.docType)
+}
+
+func (j *JSONSchemaDocument) checkContainsSupportIn(typ Type) error {
+	switch typedValue := typ.(type) {
+	case *DocumentType, *MapItemType, *ArrayItemType:
+		if validation := typedValue.GetValidation(); validation != nil {
+			if _, found := validation.HasSimpleContains(); found {
+				return NewSchemaError("Invalid schema", schemaAssertionError{
+					position:    typedValue.GetDefinitionPosition(),
+					description: fmt.Sprintf("contains (via @%v) not expressed by JSON Schema %s", AnnotationValidation, j.opts.Draft),
+					expected:    fmt.Sprintf("a keyword of %s", j.opts.Draft),
+					found:       containsProp,
+					hints:       j.draftSupportHints("to require arrays to contain some items")[:1],
+				})
+			}
+		}
+		return j.checkContainsSupportIn(typedValue.GetValueType())
+	case *NullType:
+		return j.checkContainsSupportIn(typedValue.GetValueType())
+	case *MapType:
+		for _, item := range typedValue.Items {
+			if err := j.checkContainsSupportIn(item); err != nil {
+				return err
+			}
+		}
+		if typedValue.extraPropertiesType != nil {
+			if err := j.checkContainsSupportIn(typedValue.extraPropertiesType); err != nil {
+				return err
+			}
+		}
+		if typedValue.patternProperty != nil {
+			return j.checkContainsSupportIn(typedValue.patternProperty.valueType)
+		}
+	case *ArrayType:
+		for _, itemType := range typedValue.itemTypes() {
+			if err := j.checkContainsSupportIn(itemType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
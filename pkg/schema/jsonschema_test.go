@@ -0,0 +1,1196 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"carvel.dev/ytt/pkg/schema"
+	"carvel.dev/ytt/pkg/template"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"github.com/k14s/starlark-go/resolve"
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaDocument_defaults(t *testing.T) {
+	propertyOf := func(t *testing.T, valueType schema.Type) string {
+		docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{
+			{Key: "key", ValueType: valueType},
+		}}}
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		bs, err := jsonSchemaDoc.AsDocument().AsYAMLBytes()
+		require.NoError(t, err)
+		return string(bs)
+	}
+	expectedFor := func(property string) string {
+		return `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  key:
+` + property
+	}
+
+	t.Run("are given for non-nullable scalars", func(t *testing.T) {
+		scalarType := &schema.ScalarType{ValueType: schema.StringType}
+		scalarType.SetDefaultValue("value")
+
+		require.Equal(t, expectedFor(`    type: string
+    default: value
+required:
+- key
+`), propertyOf(t, scalarType))
+	})
+	t.Run("are omitted for non-nullable scalars, when null", func(t *testing.T) {
+		scalarType := &schema.ScalarType{ValueType: schema.StringType}
+		scalarType.SetDefaultValue(nil)
+
+		require.Equal(t, expectedFor(`    type: string
+required:
+- key
+`), propertyOf(t, scalarType))
+	})
+	t.Run("are given for nullable scalars, even when null", func(t *testing.T) {
+		nullType := &schema.NullType{ValueType: &schema.ScalarType{ValueType: schema.StringType}}
+		nullType.SetDefaultValue(nil)
+
+		require.Equal(t, expectedFor(`    type:
+    - string
+    - "null"
+    default: null
+`), propertyOf(t, nullType))
+	})
+	t.Run("are given for values declared as null, with no other type", func(t *testing.T) {
+		nullType := &schema.NullType{}
+		nullType.SetDescription("Reserved for future use")
+
+		require.Equal(t, expectedFor(`    type: "null"
+    description: Reserved for future use
+    default: null
+`), propertyOf(t, nullType))
+	})
+}
+
+func TestJSONSchemaDocument_KeywordLess(t *testing.T) {
+	scalarType := &schema.ScalarType{ValueType: schema.IntType}
+	scalarType.SetDefaultValue(int64(80))
+	scalarType.SetTitle("Port")
+	scalarType.SetDescription("port to listen on")
+	docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{
+		{Key: "port", ValueType: scalarType},
+	}}}
+
+	// documentation first, then the type, then everything else (as by default)
+	rank := func(keyword string) int {
+		switch keyword {
+		case "title", "description":
+			return 0
+		case "type":
+			return 1
+		}
+		return 2
+	}
+	keywordLess := func(keyword, otherKeyword string) bool {
+		if rank(keyword) != rank(otherKeyword) {
+			return rank(keyword) < rank(otherKeyword)
+		}
+		return schema.DefaultKeywordLess(keyword, otherKeyword)
+	}
+	jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{KeywordLess: keywordLess})
+	require.NoError(t, err)
+
+	bs, err := jsonSchemaDoc.AsDocument().AsYAMLBytes()
+	require.NoError(t, err)
+	require.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  port:
+    title: Port
+    description: port to listen on
+    type: integer
+    default: 80
+required:
+- port
+`, string(bs))
+}
+
+func TestJSONSchemaDocument_KeyRenamer(t *testing.T) {
+	nullType := &schema.NullType{ValueType: &schema.ScalarType{ValueType: schema.StringType}}
+	docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{
+		{Key: "listen_port", ValueType: &schema.ScalarType{ValueType: schema.IntType}},
+		{Key: "tls_config", ValueType: &schema.MapType{Items: []*schema.MapItemType{
+			{Key: "cert_file", ValueType: &schema.ScalarType{ValueType: schema.StringType}},
+		}}},
+		{Key: "host_name", ValueType: nullType},
+	}}}
+
+	snakeToCamel := func(key string) string {
+		words := strings.Split(key, "_")
+		for i := 1; i < len(words); i++ {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+		return strings.Join(words, "")
+	}
+	jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{KeyRenamer: snakeToCamel})
+	require.NoError(t, err)
+
+	bs, err := jsonSchemaDoc.AsDocument().AsYAMLBytes()
+	require.NoError(t, err)
+	require.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  listenPort:
+    type: integer
+  tlsConfig:
+    type: object
+    additionalProperties: false
+    properties:
+      certFile:
+        type: string
+    required:
+    - certFile
+  hostName:
+    type:
+    - string
+    - "null"
+    default: null
+required:
+- listenPort
+- tlsConfig
+`, string(bs))
+}
+
+func TestJSONSchemaDocument_stableOrder(t *testing.T) {
+	// keys required (i.e. without a default), declared out of order and renamed out of order again.
+	var items []*schema.MapItemType
+	for i := 200; i > 0; i-- {
+		items = append(items, &schema.MapItemType{Key: fmt.Sprintf("key_%03d", i), ValueType: &schema.ScalarType{ValueType: schema.StringType}})
+	}
+	docType := &schema.DocumentType{ValueType: &schema.MapType{Items: items}}
+	reverseDigits := func(key string) string {
+		digits := []rune(strings.TrimPrefix(key, "key_"))
+		for i, k := 0, len(digits)-1; i < k; i, k = i+1, k-1 {
+			digits[i], digits[k] = digits[k], digits[i]
+		}
+		return "key" + string(digits)
+	}
+
+	var first []byte
+	for run := 0; run < 50; run++ {
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{KeyRenamer: reverseDigits})
+		require.NoError(t, err)
+		doc := jsonSchemaDoc.AsDocument()
+
+		var required []string
+		for _, item := range doc.Value.(*yamlmeta.Map).Items {
+			if item.Key == "required" {
+				for _, key := range item.Value.([]interface{}) {
+					required = append(required, key.(string))
+				}
+			}
+		}
+		require.Len(t, required, 200)
+		require.True(t, sort.StringsAreSorted(required), "required keys are not sorted: %v", required)
+
+		bs, err := doc.AsYAMLBytes()
+		require.NoError(t, err)
+		if run == 0 {
+			first = bs
+			continue
+		}
+		require.Equal(t, string(first), string(bs), "run %d generated a different document", run)
+	}
+}
+
+func TestJSONSchemaDocument_AsJSON(t *testing.T) {
+	scalarType := &schema.ScalarType{ValueType: schema.StringType}
+	scalarType.SetDefaultValue("<none>")
+	docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{
+		{Key: "name", ValueType: scalarType},
+		{Key: "ports", ValueType: &schema.ArrayType{ItemsType: &schema.ArrayItemType{ValueType: &schema.ScalarType{ValueType: schema.IntType}}}},
+	}}}
+	docType.ValueType.(*schema.MapType).Items[1].SetDefaultValue(&yamlmeta.Array{})
+	jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{NoDefaults: true})
+	require.NoError(t, err)
+
+	indented := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "description": "Schema for data values, generated by ytt",
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "name": {
+      "type": "string"
+    },
+    "ports": {
+      "type": "array",
+      "items": {
+        "type": "integer"
+      }
+    }
+  },
+  "required": [
+    "name"
+  ]
+}`
+
+	t.Run("indents by spaces", func(t *testing.T) {
+		bs, err := jsonSchemaDoc.AsJSON(2)
+		require.NoError(t, err)
+		require.True(t, json.Valid(bs))
+		require.Equal(t, indented, string(bs))
+	})
+	t.Run("indents by tabs", func(t *testing.T) {
+		bs, err := jsonSchemaDoc.AsJSON(schema.JSONTabIndent)
+		require.NoError(t, err)
+		require.True(t, json.Valid(bs))
+		require.Equal(t, regexp.MustCompile(`(?m)^(  )+`).ReplaceAllStringFunc(indented, func(spaces string) string {
+			return strings.Repeat("\t", len(spaces)/2)
+		}), string(bs))
+	})
+	t.Run("is compact, without indentation", func(t *testing.T) {
+		bs, err := jsonSchemaDoc.AsJSON(0)
+		require.NoError(t, err)
+		require.True(t, json.Valid(bs))
+		require.Equal(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema","description":"Schema for data values, generated by ytt",`+
+			`"type":"object","additionalProperties":false,"properties":{"name":{"type":"string"},"ports":{"type":"array",`+
+			`"items":{"type":"integer"}}},"required":["name"]}`, string(bs))
+	})
+	t.Run("keeps HTML characters as they are", func(t *testing.T) {
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		bs, err := jsonSchemaDoc.AsJSON(0)
+		require.NoError(t, err)
+		require.Contains(t, string(bs), `"default":"<none>"`)
+	})
+}
+
+func TestJSONSchemaDocument_Embedded(t *testing.T) {
+	scalarType := &schema.ScalarType{ValueType: schema.StringType}
+	scalarType.SetDefaultValue("app")
+	item := &schema.MapItemType{Key: "name", ValueType: scalarType}
+	item.SetDefaultValue("app")
+	docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{item}}}
+
+	jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{
+		ID:          "https://example.com/values.json",
+		Description: "Values of the app",
+		Embedded:    true,
+	})
+	require.NoError(t, err)
+	bs, err := jsonSchemaDoc.AsDocument().AsYAMLBytes()
+	require.NoError(t, err)
+
+	expected := `type: object
+additionalProperties: false
+properties:
+  name:
+    type: string
+    default: app
+`
+	require.Equal(t, expected, string(bs))
+}
+
+func TestJSONSchemaDocument_AsDocumentWithTransforms(t *testing.T) {
+	docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{
+		{Key: "name", ValueType: &schema.ScalarType{ValueType: schema.StringType}},
+	}}}
+	jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{Embedded: true})
+	require.NoError(t, err)
+
+	var applied []string
+	addOwner := func(doc *yamlmeta.Document) error {
+		applied = append(applied, "addOwner")
+		root := doc.Value.(*yamlmeta.Map)
+		root.Items = append(root.Items, &yamlmeta.MapItem{Key: "x-owner", Value: "platform-team"})
+		return nil
+	}
+	fail := func(*yamlmeta.Document) error {
+		applied = append(applied, "fail")
+		return fmt.Errorf("no owner for schema")
+	}
+
+	t.Run("applies each transform, in order", func(t *testing.T) {
+		applied = nil
+		doc, err := jsonSchemaDoc.AsDocumentWithTransforms(addOwner)
+		require.NoError(t, err)
+		bs, err := doc.AsYAMLBytes()
+		require.NoError(t, err)
+		require.Equal(t, `type: object
+additionalProperties: false
+properties:
+  name:
+    type: string
+required:
+- name
+x-owner: platform-team
+`, string(bs))
+		require.Equal(t, []string{"addOwner"}, applied)
+	})
+	t.Run("fails with the error of the first transform to fail, applying no others", func(t *testing.T) {
+		applied = nil
+		_, err := jsonSchemaDoc.AsDocumentWithTransforms(fail, addOwner)
+		require.EqualError(t, err, "Transforming JSON Schema (by transform 1 of 2): no owner for schema")
+		require.Equal(t, []string{"fail"}, applied)
+	})
+}
+
+func TestJSONSchemaDocument_CanonicalBytes(t *testing.T) {
+	allowLambdas(t)
+
+	docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(`
+zone: b
+db:
+  primary:
+    host: ""
+  replica:
+    host: ""
+weights: [1.0]
+tags:
+  team: ""
+`), yamlmeta.DocSetOpts{AssociatedName: "schema.yml"})
+	require.NoError(t, err)
+	items := docSet.Items[0].Value.(*yamlmeta.Map).Items
+	items[0].SetAnnotations(template.NodeAnnotations{
+		schema.AnnotationValidation: template.NodeAnnotation{Kwargs: []starlark.Tuple{
+			{starlark.String("one_of"), starlark.NewList([]starlark.Value{starlark.String("c"), starlark.String("a"), starlark.String("b")})},
+		}},
+	})
+	items[2].SetAnnotations(template.NodeAnnotations{schema.AnnotationNullable: template.NodeAnnotation{}})
+	docType, err := schema.NewDocumentType(docSet.Items[0])
+	require.NoError(t, err)
+
+	t.Run("is the same each time the schema is generated", func(t *testing.T) {
+		var first, firstCanonical []byte
+		for i := 0; i < 100; i++ {
+			jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+			require.NoError(t, err)
+			bs, err := jsonSchemaDoc.AsJSON(2)
+			require.NoError(t, err)
+			canonical, err := jsonSchemaDoc.CanonicalBytes()
+			require.NoError(t, err)
+			if i == 0 {
+				first, firstCanonical = bs, canonical
+				continue
+			}
+			require.Equal(t, string(first), string(bs), "generation #%d", i+1)
+			require.Equal(t, string(firstCanonical), string(canonical), "generation #%d", i+1)
+		}
+	})
+	t.Run("sorts keys and allowed values", func(t *testing.T) {
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+		canonical, err := jsonSchemaDoc.CanonicalBytes()
+		require.NoError(t, err)
+		require.True(t, json.Valid(canonical))
+		require.Equal(t, `{"$defs":{"Type1":{"additionalProperties":false,"properties":{"host":{"default":"","type":"string"}},"type":"object"}},`+
+			`"$schema":"https://json-schema.org/draft/2020-12/schema","additionalProperties":false,`+
+			`"description":"Schema for data values, generated by ytt","properties":{"db":{"additionalProperties":false,`+
+			`"properties":{"primary":{"$ref":"#/$defs/Type1"},"replica":{"$ref":"#/$defs/Type1"}},"type":"object"},`+
+			`"tags":{"additionalProperties":false,"properties":{"team":{"default":"","type":"string"}},"type":"object"},`+
+			`"weights":{"default":null,"items":{"default":1,"type":"number"},"type":["array","null"]},`+
+			`"zone":{"default":"b","enum":["a","b","c"],"type":"string"}},"type":"object"}`, string(canonical))
+	})
+	t.Run("regardless of the order of keywords", func(t *testing.T) {
+		ordered, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{PreserveOrder: true})
+		require.NoError(t, err)
+		unordered, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		orderedBytes, err := ordered.CanonicalBytes()
+		require.NoError(t, err)
+		unorderedBytes, err := unordered.CanonicalBytes()
+		require.NoError(t, err)
+		require.Equal(t, string(unorderedBytes), string(orderedBytes))
+	})
+}
+
+func TestJSONSchemaDocuments(t *testing.T) {
+	docTypeWith := func(key string, valueType schema.Type, defaultValue interface{}) *schema.DocumentType {
+		valueType.SetDefaultValue(defaultValue)
+		item := &schema.MapItemType{Key: key, ValueType: valueType}
+		item.SetDefaultValue(defaultValue)
+		return &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{item}}}
+	}
+	asYAML := func(t *testing.T, docTypes ...*schema.DocumentType) string {
+		jsonSchemaDocs, err := schema.NewJSONSchemaDocuments(docTypes, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		bs, err := jsonSchemaDocs.AsDocument().AsYAMLBytes()
+		require.NoError(t, err)
+		return string(bs)
+	}
+
+	t.Run("offers a choice between unlike documents", func(t *testing.T) {
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for documents, generated by ytt
+oneOf:
+- type: object
+  additionalProperties: false
+  properties:
+    name:
+      type: string
+      default: app
+- type: object
+  additionalProperties: false
+  properties:
+    replicas:
+      type: integer
+      default: 1
+`
+		require.Equal(t, expected, asYAML(t,
+			docTypeWith("name", &schema.ScalarType{ValueType: schema.StringType}, "app"),
+			docTypeWith("replicas", &schema.ScalarType{ValueType: schema.IntType}, 1),
+		))
+	})
+	t.Run("describes alike documents once", func(t *testing.T) {
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for documents, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  name:
+    type: string
+    default: app
+`
+		require.Equal(t, expected, asYAML(t,
+			docTypeWith("name", &schema.ScalarType{ValueType: schema.StringType}, "app"),
+			docTypeWith("name", &schema.ScalarType{ValueType: schema.StringType}, "app"),
+		))
+	})
+	t.Run("declares repeated maps of each document apart", func(t *testing.T) {
+		endpointsOf := func(keys ...string) *schema.DocumentType {
+			var items []*schema.MapItemType
+			for _, key := range keys {
+				host := &schema.MapItemType{Key: "host", ValueType: &schema.ScalarType{ValueType: schema.StringType}}
+				host.SetDefaultValue("")
+				host.GetValueType().SetDefaultValue("")
+				item := &schema.MapItemType{Key: key, ValueType: &schema.MapType{Items: []*schema.MapItemType{host}}}
+				item.SetDefaultValue(&yamlmeta.Map{})
+				items = append(items, item)
+			}
+			return &schema.DocumentType{ValueType: &schema.MapType{Items: items}}
+		}
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for documents, generated by ytt
+oneOf:
+- type: object
+  additionalProperties: false
+  properties:
+    primary:
+      $ref: '#/$defs/Document1Type1'
+    replica:
+      $ref: '#/$defs/Document1Type1'
+- type: object
+  additionalProperties: false
+  properties:
+    internal:
+      $ref: '#/$defs/Document2Type1'
+    external:
+      $ref: '#/$defs/Document2Type1'
+$defs:
+  Document1Type1:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+  Document2Type1:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+`
+		require.Equal(t, expected, asYAML(t, endpointsOf("primary", "replica"), endpointsOf("internal", "external")))
+	})
+	t.Run("requires at least one document", func(t *testing.T) {
+		_, err := schema.NewJSONSchemaDocuments(nil, schema.JSONSchemaOpts{})
+		require.EqualError(t, err, "Expected at least one document to describe")
+	})
+}
+
+func TestJSONSchemaBundle(t *testing.T) {
+	docTypeWith := func(key string, valueType schema.Type, defaultValue interface{}) *schema.DocumentType {
+		valueType.SetDefaultValue(defaultValue)
+		item := &schema.MapItemType{Key: key, ValueType: valueType}
+		item.SetDefaultValue(defaultValue)
+		return &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{item}}}
+	}
+	endpointsOf := func(keys ...string) *schema.DocumentType {
+		var items []*schema.MapItemType
+		for _, key := range keys {
+			host := &schema.MapItemType{Key: "host", ValueType: &schema.ScalarType{ValueType: schema.StringType}}
+			host.SetDefaultValue("")
+			host.GetValueType().SetDefaultValue("")
+			item := &schema.MapItemType{Key: key, ValueType: &schema.MapType{Items: []*schema.MapItemType{host}}}
+			item.SetDefaultValue(&yamlmeta.Map{})
+			items = append(items, item)
+		}
+		return &schema.DocumentType{ValueType: &schema.MapType{Items: items}}
+	}
+
+	t.Run("defines the schema of each library, all of which the root requires", func(t *testing.T) {
+		bundle, err := schema.NewJSONSchemaBundle(map[string]*schema.DocumentType{
+			"web": docTypeWith("name", &schema.ScalarType{ValueType: schema.StringType}, "app"),
+			"db":  endpointsOf("primary", "replica"),
+		}, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		bs, err := bundle.AsDocument().AsYAMLBytes()
+		require.NoError(t, err)
+
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values of libraries, generated by ytt
+allOf:
+- $ref: '#/$defs/db'
+- $ref: '#/$defs/web'
+$defs:
+  db:
+    type: object
+    additionalProperties: false
+    properties:
+      primary:
+        $ref: '#/$defs/dbType1'
+      replica:
+        $ref: '#/$defs/dbType1'
+  web:
+    type: object
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        default: app
+  dbType1:
+    type: object
+    additionalProperties: false
+    properties:
+      host:
+        type: string
+        default: ""
+`
+		require.Equal(t, expected, string(bs))
+	})
+	t.Run("inlines the reference to a single library, when flattening single combinations", func(t *testing.T) {
+		docTypes := map[string]*schema.DocumentType{
+			"web": docTypeWith("name", &schema.ScalarType{ValueType: schema.StringType}, "app"),
+		}
+		bundle, err := schema.NewJSONSchemaBundle(docTypes, schema.JSONSchemaOpts{FlattenSingleOneOf: true})
+		require.NoError(t, err)
+		bs, err := bundle.AsDocument().AsYAMLBytes()
+		require.NoError(t, err)
+
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values of libraries, generated by ytt
+$ref: '#/$defs/web'
+$defs:
+  web:
+    type: object
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        default: app
+`
+		require.Equal(t, expected, string(bs))
+
+		t.Run("except in draft-07, where keywords alongside a reference are ignored", func(t *testing.T) {
+			bundle, err := schema.NewJSONSchemaBundle(docTypes, schema.JSONSchemaOpts{FlattenSingleOneOf: true, Draft: schema.JSONSchemaDraft07})
+			require.NoError(t, err)
+			bs, err := bundle.AsDocument().AsYAMLBytes()
+			require.NoError(t, err)
+			require.Contains(t, string(bs), "allOf:\n- $ref: '#/definitions/web'\n")
+		})
+	})
+	t.Run("requires at least one library", func(t *testing.T) {
+		_, err := schema.NewJSONSchemaBundle(nil, schema.JSONSchemaOpts{})
+		require.EqualError(t, err, "Expected at least one library to describe")
+	})
+}
+
+func TestJSONSchemaDocument_AsSingleFileDocument(t *testing.T) {
+	docOf := func(t *testing.T, yml string) *yamlmeta.Document {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(yml), yamlmeta.DocSetOpts{AssociatedName: "test.yml"})
+		require.NoError(t, err)
+		return docSet.Items[0]
+	}
+	jsonSchemaOf := func(t *testing.T, uri string) *schema.JSONSchemaDocument {
+		schemaDoc := docOf(t, `
+requests:
+  cpu: 100m
+`)
+		schemaDoc.Value.(*yamlmeta.Map).Items[0].SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationExternalRef: template.NodeAnnotation{Args: starlark.Tuple{starlark.String(uri)}},
+		})
+		docType, err := schema.NewDocumentType(schemaDoc)
+		require.NoError(t, err)
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+		return jsonSchemaDoc
+	}
+
+	t.Run("declares fetched external schemas among the definitions, referring to them internally", func(t *testing.T) {
+		var fetched []string
+		fetch := func(uri string) (*yamlmeta.Document, error) {
+			fetched = append(fetched, uri)
+			switch uri {
+			case "https://example.com/schemas/requests.json":
+				return docOf(t, `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "https://example.com/schemas/requests.json", "type": "object", "properties": {"cpu": {"$ref": "quantity.json"}, "memory": {"$ref": "#/properties/cpu"}}}`), nil
+			case "https://example.com/schemas/quantity.json":
+				return docOf(t, `{"type": "string"}`), nil
+			}
+			return nil, fmt.Errorf("not found")
+		}
+
+		doc, err := jsonSchemaOf(t, "https://example.com/schemas/requests.json").AsSingleFileDocument(fetch)
+		require.NoError(t, err)
+		bs, err := doc.AsYAMLBytes()
+		require.NoError(t, err)
+
+		expected := `$schema: https://json-schema.org/draft/2020-12/schema
+description: Schema for data values, generated by ytt
+type: object
+additionalProperties: false
+properties:
+  requests:
+    $ref: '#/$defs/requests'
+$defs:
+  requests:
+    type: object
+    properties:
+      cpu:
+        $ref: '#/$defs/quantity'
+      memory:
+        $ref: '#/$defs/requests/properties/cpu'
+  quantity:
+    type: string
+`
+		require.Equal(t, expected, string(bs))
+		require.Equal(t, []string{"https://example.com/schemas/requests.json", "https://example.com/schemas/quantity.json"}, fetched)
+	})
+	t.Run("refers to within the definition of an external schema, by JSON Pointer", func(t *testing.T) {
+		fetch := func(string) (*yamlmeta.Document, error) {
+			return docOf(t, `{"$defs": {"requests": {"type": "object"}}}`), nil
+		}
+		doc, err := jsonSchemaOf(t, "https://example.com/k8s.json#/$defs/requests").AsSingleFileDocument(fetch)
+		require.NoError(t, err)
+		bs, err := doc.AsYAMLBytes()
+		require.NoError(t, err)
+		require.Contains(t, string(bs), "    $ref: '#/$defs/k8s/$defs/requests'\n")
+	})
+	t.Run("fails when an external schema cannot be fetched", func(t *testing.T) {
+		fetch := func(string) (*yamlmeta.Document, error) {
+			return nil, fmt.Errorf("fetching is not enabled")
+		}
+		_, err := jsonSchemaOf(t, "https://example.com/requests.json").AsSingleFileDocument(fetch)
+		require.EqualError(t, err, "Fetching external schema 'https://example.com/requests.json': fetching is not enabled")
+	})
+}
+
+func TestJSONSchemaDocument_Validate(t *testing.T) {
+	allowLambdas(t)
+
+	docOf := func(t *testing.T, yml string) *yamlmeta.Document {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(yml), yamlmeta.DocSetOpts{AssociatedName: "test.yml"})
+		require.NoError(t, err)
+		return docSet.Items[0]
+	}
+	validated := func(node yamlmeta.Node, kwargs ...starlark.Tuple) {
+		node.SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationValidation: template.NodeAnnotation{Kwargs: kwargs},
+		})
+	}
+	jsonSchemaOf := func(t *testing.T, schemaDoc *yamlmeta.Document) *schema.JSONSchemaDocument {
+		docType, err := schema.NewDocumentType(schemaDoc)
+		require.NoError(t, err)
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+		return jsonSchemaDoc
+	}
+	messagesOf := func(errs []error) []string {
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+
+	t.Run("accepts a conforming document", func(t *testing.T) {
+		jsonSchemaDoc := jsonSchemaOf(t, docOf(t, `
+foo:
+  bar: 0
+  baz: [""]
+`))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+foo:
+  bar: 42
+  baz: [a, b]
+`))
+		require.Empty(t, errs)
+	})
+	t.Run("reports type mismatches, by path", func(t *testing.T) {
+		jsonSchemaDoc := jsonSchemaOf(t, docOf(t, `
+foo:
+  bar: 0
+  baz: [""]
+`))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+foo:
+  bar: not a number
+  baz: [a, 1]
+`))
+		require.Equal(t, []string{
+			"values.foo.bar: expected integer, got string",
+			"values.foo.baz[1]: expected string, got integer",
+		}, messagesOf(errs))
+	})
+	t.Run("reports missing required keys", func(t *testing.T) {
+		// a non-nullable value without a default must be given
+		docType := &schema.DocumentType{ValueType: &schema.MapType{Items: []*schema.MapItemType{
+			{Key: "foo", ValueType: &schema.MapType{Items: []*schema.MapItemType{
+				{Key: "bar", ValueType: &schema.ScalarType{ValueType: schema.StringType}},
+				{Key: "baz", ValueType: &schema.NullType{ValueType: &schema.ScalarType{ValueType: schema.StringType}}},
+			}}},
+		}}}
+		docType.ValueType.(*schema.MapType).Items[0].SetDefaultValue(&yamlmeta.Map{})
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+foo:
+  baz: hello
+`))
+		require.Equal(t, []string{"values.foo.bar: missing required key"}, messagesOf(errs))
+	})
+	t.Run("reports unexpected keys", func(t *testing.T) {
+		jsonSchemaDoc := jsonSchemaOf(t, docOf(t, `
+foo: ""
+`))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+foo: ""
+bar: ""
+`))
+		require.Equal(t, []string{"values.bar: unexpected key"}, messagesOf(errs))
+	})
+	t.Run("accepts keys declared from (overlaid) data values", func(t *testing.T) {
+		docType, err := schema.NewDocumentType(docOf(t, `
+db:
+  host: ""
+`))
+		require.NoError(t, err)
+		values := docOf(t, `
+db:
+  host: primary
+  port: 5432
+`)
+		extended, err := docType.WithKeysOf(values)
+		require.NoError(t, err)
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(extended, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+
+		require.Empty(t, jsonSchemaDoc.Validate(values))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+db:
+  port: not a number
+`))
+		require.Equal(t, []string{"values.db.port: expected integer, got string"}, messagesOf(errs))
+
+		// the schema extended is left as it was.
+		original, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"values.db.port: unexpected key"}, messagesOf(original.Validate(values)))
+	})
+	t.Run("reports keys not matching the key pattern", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+ports: {}
+`)
+		ports := schemaDoc.Value.(*yamlmeta.Map).Items[0]
+		ports.SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationAllowExtraProperties: template.NodeAnnotation{Args: starlark.Tuple{starlark.MakeInt(0)}},
+			schema.AnnotationKeyPattern:           template.NodeAnnotation{Args: starlark.Tuple{starlark.String("^[a-z]+$")}},
+		})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+ports:
+  http: 80
+  HTTPS: 443
+  metrics_1: 9090
+`))
+		require.Equal(t, []string{
+			"values.ports.HTTPS: expected a key matching ^[a-z]+$",
+			"values.ports.metrics_1: expected a key matching ^[a-z]+$",
+		}, messagesOf(errs))
+	})
+	t.Run("reports values not among those allowed", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+level: info
+`)
+		level := schemaDoc.Value.(*yamlmeta.Map).Items[0]
+		validated(level, starlark.Tuple{starlark.String("one_of"), starlark.NewList([]starlark.Value{
+			starlark.String("debug"), starlark.String("info"), starlark.String("error"),
+		})})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+level: debug
+`)))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+level: trace
+`))
+		require.Equal(t, []string{"values.level: expected one of [debug info error], got trace"}, messagesOf(errs))
+	})
+	t.Run("reports numbers not a multiple of that required", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+memory_mb: 64
+`)
+		validated(schemaDoc.Value.(*yamlmeta.Map).Items[0], starlark.Tuple{starlark.String("multiple_of"), starlark.MakeInt(8)})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+memory_mb: 128
+`)))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+memory_mb: 100
+`))
+		require.Equal(t, []string{"values.memory_mb: expected a multiple of 8, got 100"}, messagesOf(errs))
+	})
+	t.Run("reports numbers not a multiple of a fractional number required", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+ratio: 0.1
+`)
+		validated(schemaDoc.Value.(*yamlmeta.Map).Items[0], starlark.Tuple{starlark.String("multiple_of"), starlark.Float(0.1)})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+ratio: 0.3
+`)))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+ratio: 0.35
+`))
+		require.Equal(t, []string{"values.ratio: expected a multiple of 0.1, got 0.35"}, messagesOf(errs))
+	})
+	t.Run("reports items of an array missing keys of the shape their discriminator tags them as", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+listeners:
+- protocol: https
+  port: 443
+`)
+		schemaDoc.Value.(*yamlmeta.Map).Items[0].Value.(*yamlmeta.Array).Items[0].SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationDiscriminator: template.NodeAnnotation{Args: starlark.Tuple{starlark.String("protocol")}},
+		})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+listeners:
+- protocol: https
+  port: 8443
+- protocol: http
+`)))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+listeners:
+- protocol: https
+`))
+		require.Equal(t, []string{"values.listeners[0].port: missing key required when protocol is https"}, messagesOf(errs))
+	})
+	t.Run("reports maps with too few or too many keys", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+labels:
+  app: ""
+  tier: ""
+`)
+		validated(schemaDoc.Value.(*yamlmeta.Map).Items[0],
+			starlark.Tuple{starlark.String("min_props"), starlark.MakeInt(1)},
+			starlark.Tuple{starlark.String("max_props"), starlark.MakeInt(1)})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+labels:
+  app: web
+`)))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+labels:
+  app: web
+  tier: frontend
+`))
+		require.Equal(t, []string{"values.labels: expected <= 1 keys, got 2"}, messagesOf(errs))
+		errs = jsonSchemaDoc.Validate(docOf(t, `
+labels: {}
+`))
+		require.Equal(t, []string{"values.labels: expected >= 1 keys, got 0"}, messagesOf(errs))
+	})
+	t.Run("accepts extra keys of maps exported without additionalProperties", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+spec:
+  replicas: 1
+`)
+		schemaDoc.Value.(*yamlmeta.Map).Items[0].SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationNoAdditionalPropsKey: template.NodeAnnotation{},
+		})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+spec:
+  replicas: 2
+  paused: true
+`)))
+	})
+	t.Run("accepts a single item in place of an array that allows it", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+hosts: [""]
+`)
+		schemaDoc.Value.(*yamlmeta.Map).Items[0].SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationScalarOrArray: template.NodeAnnotation{},
+		})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+hosts: a.com
+`)))
+		require.Empty(t, jsonSchemaDoc.Validate(docOf(t, `
+hosts: [a.com, b.com]
+`)))
+		errs := jsonSchemaDoc.Validate(docOf(t, `
+hosts: 1
+`))
+		require.Equal(t, []string{"values.hosts: expected string, got integer"}, messagesOf(errs))
+	})
+	t.Run("reports as ValidateAgainstJSONSchema() does against the schema exported", func(t *testing.T) {
+		schemaDoc := docOf(t, `
+port: 8080
+ratio: 0.5
+`)
+		validated(schemaDoc.Value.(*yamlmeta.Map).Items[0],
+			starlark.Tuple{starlark.String("min"), starlark.MakeInt(1)},
+			starlark.Tuple{starlark.String("max"), starlark.MakeInt(65536)},
+			starlark.Tuple{starlark.String("exclusive"), starlark.True})
+		validated(schemaDoc.Value.(*yamlmeta.Map).Items[1], starlark.Tuple{starlark.String("max"), starlark.Float(1)})
+		jsonSchemaDoc := jsonSchemaOf(t, schemaDoc)
+
+		for _, values := range []string{"port: 1\nratio: 1.5", "port: 65536\nratio: 1", "port: 8080\nratio: 0.5"} {
+			expected := messagesOf(schema.ValidateAgainstJSONSchema(jsonSchemaDoc.AsDocument(), docOf(t, values)))
+			require.Equal(t, expected, messagesOf(jsonSchemaDoc.Validate(docOf(t, values))), values)
+		}
+		require.Equal(t, []string{
+			"values.port: expected a value > 1, got 1",
+			"values.ratio: expected a value <= 1, got 1.5",
+		}, messagesOf(jsonSchemaDoc.Validate(docOf(t, "port: 1\nratio: 1.5"))))
+	})
+}
+
+func TestSchemaDiff(t *testing.T) {
+	docOf := func(t *testing.T, yml string) *yamlmeta.Document {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(yml), yamlmeta.DocSetOpts{AssociatedName: "test.yml"})
+		require.NoError(t, err)
+		return docSet.Items[0]
+	}
+	jsonSchemaOf := func(t *testing.T, yml string) *yamlmeta.Document {
+		docType, err := schema.NewDocumentType(docOf(t, yml))
+		require.NoError(t, err)
+		jsonSchemaDoc, err := schema.NewJSONSchemaDocument(docType, schema.JSONSchemaOpts{})
+		require.NoError(t, err)
+		return jsonSchemaDoc.AsDocument()
+	}
+
+	t.Run("reports nothing for identical schemas", func(t *testing.T) {
+		yml := `
+db:
+  host: ""
+  port: 5432
+`
+		require.Empty(t, schema.SchemaDiff(jsonSchemaOf(t, yml), jsonSchemaOf(t, yml)))
+	})
+	t.Run("reports an added optional property as non-breaking", func(t *testing.T) {
+		changes := schema.SchemaDiff(jsonSchemaOf(t, `
+db:
+  host: ""
+`), jsonSchemaOf(t, `
+db:
+  host: ""
+  port: 5432
+`))
+		require.Equal(t, []schema.Change{
+			{Path: "values.db.port", Kind: schema.PropertyAdded, Breaking: false},
+		}, changes)
+		require.Equal(t, "non-breaking: values.db.port: property added", changes[0].String())
+	})
+	t.Run("reports a removed required property as breaking", func(t *testing.T) {
+		changes := schema.SchemaDiff(docOf(t, `
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+  port:
+    type: integer
+required: [host, port]
+`), docOf(t, `
+type: object
+additionalProperties: false
+properties:
+  host:
+    type: string
+required: [host]
+`))
+		require.Equal(t, []schema.Change{
+			{Path: "values.port", Kind: schema.PropertyRemoved, Breaking: true},
+		}, changes)
+		require.Equal(t, "breaking: values.port: property removed", changes[0].String())
+	})
+	t.Run("classifies changes of type and constraints", func(t *testing.T) {
+		changes := schema.SchemaDiff(docOf(t, `
+type: object
+properties:
+  name:
+    type: string
+    maxLength: 63
+  replicas:
+    type: integer
+    minimum: 1
+  ratio:
+    type: integer
+`), docOf(t, `
+type: object
+properties:
+  name:
+    type: string
+    maxLength: 32
+  replicas:
+    type: integer
+  ratio:
+    type: number
+`))
+		var descriptions []string
+		for _, change := range changes {
+			descriptions = append(descriptions, change.String())
+		}
+		require.Equal(t, []string{
+			"breaking: values.name: constraint tightened (maxLength: 63 -> 32)",
+			"non-breaking: values.replicas: constraint loosened (minimum: 1 -> (none))",
+			"non-breaking: values.ratio: type changed from [integer] to [number]",
+		}, descriptions)
+	})
+}
+
+func TestValidateAgainstJSONSchema(t *testing.T) {
+	docOf := func(t *testing.T, yml string) *yamlmeta.Document {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(yml), yamlmeta.DocSetOpts{AssociatedName: "test.yml"})
+		require.NoError(t, err)
+		return docSet.Items[0]
+	}
+	messagesOf := func(errs []error) []string {
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+	schemaDoc := docOf(t, `
+$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+additionalProperties: false
+required: [name]
+properties:
+  name:
+    type: string
+    minLength: 2
+    pattern: ^[a-z]+$
+  port:
+    type: integer
+    minimum: 1
+    exclusiveMaximum: 65536
+  mode:
+    enum: [fast, safe]
+  tags:
+    type: array
+    uniqueItems: true
+    items:
+      type: string
+  db:
+    $ref: '#/$defs/db'
+  labels:
+    type: object
+    additionalProperties:
+      type: string
+$defs:
+  db:
+    type: object
+    properties:
+      host:
+        type: string
+      replicas:
+        type: [integer, "null"]
+`)
+
+	t.Run("accepts a conforming document", func(t *testing.T) {
+		errs := schema.ValidateAgainstJSONSchema(schemaDoc, docOf(t, `
+name: app
+port: 8080
+mode: safe
+tags: [a, b]
+db:
+  host: localhost
+  replicas: null
+labels:
+  team: core
+`))
+		require.Empty(t, errs)
+	})
+	t.Run("reports each nonconforming value, by path", func(t *testing.T) {
+		errs := schema.ValidateAgainstJSONSchema(schemaDoc, docOf(t, `
+port: 65536
+mode: slow
+tags: [a, a]
+db:
+  host: 1
+  replicas: 1.5
+labels:
+  team: 1
+extra: true
+`))
+		require.Equal(t, []string{
+			"values.port: expected a value < 65536, got 65536",
+			"values.mode: expected one of [fast safe], got slow",
+			"values.tags[1]: expected unique items, got a duplicate",
+			"values.db.host: expected string, got integer",
+			"values.db.replicas: expected integer or null, got number",
+			"values.labels.team: expected string, got integer",
+			"values.extra: unexpected key",
+			"values.name: missing required key",
+		}, messagesOf(errs))
+	})
+	t.Run("reports constraints on strings", func(t *testing.T) {
+		errs := schema.ValidateAgainstJSONSchema(schemaDoc, docOf(t, `
+name: A
+`))
+		require.Equal(t, []string{
+			"values.name: expected length >= 2, got 1",
+			`values.name: expected a value matching ^[a-z]+$, got "A"`,
+		}, messagesOf(errs))
+	})
+}
+
+// allowLambdas allows Starlark lambdas (as rules are), which are otherwise allowed once templates are compiled, for
+// the duration of `t`.
+func allowLambdas(t *testing.T) {
+	allowed := resolve.AllowLambda
+	resolve.AllowLambda = true
+	t.Cleanup(func() { resolve.AllowLambda = allowed })
+}
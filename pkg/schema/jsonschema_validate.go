@@ -0,0 +1,393 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"carvel.dev/ytt/pkg/validations"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"carvel.dev/ytt/pkg/yttlibrary"
+)
+
+// Validate checks `doc` against this schema (i.e. the type information of `docType`), reporting each value that
+// does not conform, by its path (e.g. "values.foo.bar: expected integer, got string").
+//
+// Beyond types, keys must be present when required (see requiredKeysOf()), depended on by keys present, or required by
+// the map's condition (if met, including that of the items of an array told apart by a discriminator), and match their map's key pattern (if any). Values must satisfy those validations
+// exported as JSON Schema keywords (lengths, numbers of keys, bounds, multiples, patterns and allowed values);
+// conditional validations are not checked.
+func (j *JSONSchemaDocument) Validate(doc *yamlmeta.Document) []error {
+	return j.validate("values", j.docType, doc.Value)
+}
+
+func (j *JSONSchemaDocument) validate(path string, schemaVal Type, value interface{}) []error {
+	errs := j.validateType(path, schemaVal.GetValueType(), value)
+	if len(errs) > 0 {
+		return errs
+	}
+	if validation := schemaVal.GetValidation(); validation != nil {
+		errs = append(errs, j.validateAgainst(path, validation, value)...)
+	}
+	return errs
+}
+
+func (j *JSONSchemaDocument) validateType(path string, schemaVal Type, value interface{}) []error {
+	switch typedValue := schemaVal.(type) {
+	case *NullType:
+		if value == nil {
+			return nil
+		}
+		if typedValue.GetValueType() == nil {
+			return []error{mismatchError(path, "null", value)}
+		}
+		if scalarType, isScalar := typedValue.GetValueType().(*ScalarType); isScalar {
+			return checkType(path, []string{j.openAPITypeFor(scalarType), "null"}, value)
+		}
+		return j.validateType(path, typedValue.GetValueType(), value)
+
+	case *AnyType:
+		return nil
+
+	case *MapType:
+		if typedValue.externalRef != "" {
+			// described by a schema published elsewhere (see @schema/external-ref).
+			return nil
+		}
+		mapVal, ok := value.(*yamlmeta.Map)
+		if !ok {
+			return []error{mismatchError(path, "object", value)}
+		}
+		var errs []error
+		required := map[interface{}]bool{}
+		for _, key := range j.requiredKeysOf(typedValue) {
+			required[key] = true
+		}
+		given := map[interface{}]interface{}{}
+		for _, item := range mapVal.Items {
+			given[item.Key] = item.Value
+			itemPath := fmt.Sprintf("%s.%v", path, item.Key)
+			if typedValue.keyPattern != "" {
+				if keyErrs := checkKeyPattern(itemPath, fmt.Sprintf("%v", item.Key), typedValue.keyPattern); len(keyErrs) > 0 {
+					errs = append(errs, keyErrs...)
+					continue
+				}
+			}
+			itemType := typedValue.findItem(item.Key)
+			switch {
+			case itemType != nil:
+				errs = append(errs, j.validate(itemPath, itemType, item.Value)...)
+			case typedValue.patternProperty.matches(item.Key):
+				errs = append(errs, j.validateType(itemPath, typedValue.patternProperty.valueType, item.Value)...)
+			case !typedValue.allowExtraProperties && !typedValue.noAdditionalPropsKey:
+				// (exported without "additionalProperties", a map permits extra keys: see @schema/no-additional-properties-key)
+				errs = append(errs, fmt.Errorf("%s: unexpected key", itemPath))
+			case typedValue.extraPropertiesType != nil:
+				errs = append(errs, j.validateType(itemPath, typedValue.extraPropertiesType, item.Value)...)
+			}
+			delete(required, item.Key)
+		}
+		for _, item := range typedValue.Items {
+			if required[item.Key] {
+				errs = append(errs, missingKeyError(path, item.Key))
+			}
+		}
+		for _, dependency := range typedValue.dependentRequired {
+			if _, found := given[dependency.key]; !found {
+				continue
+			}
+			for _, key := range dependency.requires {
+				if _, found := given[key]; !found {
+					errs = append(errs, missingDependencyError(path, key, dependency.key))
+				}
+			}
+		}
+		if typedValue.condition != nil {
+			errs = append(errs, checkCondition(path, typedValue.condition, mapVal)...)
+		}
+		return errs
+
+	case *ArrayType:
+		arrayVal, ok := value.(*yamlmeta.Array)
+		if !ok && typedValue.scalarOrArray {
+			// a single item given in place of the array (see @schema/scalar-or-array).
+			return j.validate(path, typedValue.GetValueType(), value)
+		}
+		if !ok {
+			return []error{mismatchError(path, "array", value)}
+		}
+		var errs []error
+		if typedValue.tupleItems != nil && len(arrayVal.Items) != len(typedValue.tupleItems) {
+			errs = append(errs, fmt.Errorf("%s: expected %d items (of the tuple), got %d", path, len(typedValue.tupleItems), len(arrayVal.Items)))
+		}
+		for i, item := range arrayVal.Items {
+			itemType := typedValue.GetValueType()
+			if typedValue.tupleItems != nil {
+				if i >= len(typedValue.tupleItems) {
+					break
+				}
+				itemType = typedValue.tupleItems[i]
+			}
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			itemErrs := j.validate(itemPath, itemType, item.Value)
+			if condition := discriminatedCondition(itemType.GetValueType()); condition != nil && typedValue.tupleItems == nil && len(itemErrs) == 0 {
+				if mapVal, isMap := item.Value.(*yamlmeta.Map); isMap {
+					itemErrs = checkCondition(itemPath, condition, mapVal)
+				}
+			}
+			errs = append(errs, itemErrs...)
+		}
+		return errs
+
+	case *ScalarType:
+		return checkType(path, []string{j.openAPITypeFor(typedValue)}, value)
+
+	default:
+		panic(fmt.Sprintf("Unrecognized type %T", schemaVal))
+	}
+}
+
+// validateAgainst checks `value` (already known to be of the expected type) satisfies the "simple" rules of
+// `validation`.
+func (j *JSONSchemaDocument) validateAgainst(path string, validation *validations.NodeValidation, value interface{}) []error {
+	if value == nil {
+		return nil
+	}
+	var errs []error
+	if length, hasLength := lengthOf(value); hasLength {
+		minLength, hasMin := validation.HasSimpleMinLength()
+		maxLength, hasMax := validation.HasSimpleMaxLength()
+		errs = append(errs, checkLength(path, length, orNil(minLength, hasMin), orNil(maxLength, hasMax))...)
+	}
+	if mapVal, isMap := value.(*yamlmeta.Map); isMap {
+		minProperties, hasMin := validation.HasSimpleMinProperties()
+		maxProperties, hasMax := validation.HasSimpleMaxProperties()
+		errs = append(errs, checkKeyCount(path, int64(len(mapVal.Items)), orNil(minProperties, hasMin), orNil(maxProperties, hasMax))...)
+	}
+	if min, found := validation.HasSimpleMin(); found {
+		errs = append(errs, checkBound(path, value, min, false, validation.HasExclusiveBounds())...)
+	}
+	if max, found := validation.HasSimpleMax(); found {
+		errs = append(errs, checkBound(path, value, max, true, validation.HasExclusiveBounds())...)
+	}
+	if multipleOf, found := validation.HasSimpleMultipleOf(); found {
+		errs = append(errs, checkMultipleOf(path, value, multipleOf)...)
+	}
+	if str, isString := value.(string); isString {
+		patterns, _ := validation.HasSimplePatterns()
+		for _, pattern := range patterns {
+			errs = append(errs, checkPattern(path, str, pattern, false)...)
+		}
+		notPatterns, _ := validation.HasSimpleNotPatterns()
+		for _, pattern := range notPatterns {
+			errs = append(errs, checkPattern(path, str, pattern, true)...)
+		}
+	}
+	if allowed, found := validation.HasSimpleOneOf(); found {
+		errs = append(errs, checkAllowed(path, allowed, value)...)
+	}
+	return errs
+}
+
+// The checks below are those of a single keyword (or rule of a validation), shared by Validate() and
+// ValidateAgainstJSONSchema(); each reports `value` (at `path`) if it does not satisfy that keyword.
+
+// checkType checks `value` is of one of `types` (an integer being a number, as well).
+func checkType(path string, types []string, value interface{}) []error {
+	found := jsonTypeOf(value)
+	for _, typ := range types {
+		if typ == found || (typ == "number" && found == "integer") {
+			return nil
+		}
+	}
+	return []error{mismatchError(path, strings.Join(types, " or "), value)}
+}
+
+// checkLength checks `length` (of a string, an array, or a map) is within `min` and `max` (either nil, if unbounded).
+func checkLength(path string, length int64, min, max interface{}) []error {
+	var errs []error
+	if bound, isNumber := asFloat(min); isNumber && float64(length) < bound {
+		errs = append(errs, fmt.Errorf("%s: expected length >= %v, got %d", path, min, length))
+	}
+	if bound, isNumber := asFloat(max); isNumber && float64(length) > bound {
+		errs = append(errs, fmt.Errorf("%s: expected length <= %v, got %d", path, max, length))
+	}
+	return errs
+}
+
+// checkKeyCount checks `count` (of the keys of a map) is within `min` and `max` (either nil, if unbounded).
+func checkKeyCount(path string, count int64, min, max interface{}) []error {
+	var errs []error
+	if bound, isNumber := asFloat(min); isNumber && float64(count) < bound {
+		errs = append(errs, fmt.Errorf("%s: expected >= %v keys, got %d", path, min, count))
+	}
+	if bound, isNumber := asFloat(max); isNumber && float64(count) > bound {
+		errs = append(errs, fmt.Errorf("%s: expected <= %v keys, got %d", path, max, count))
+	}
+	return errs
+}
+
+// checkBound checks a number is no less (no greater, if `upper`) than `bound`; nor equal to it, if `exclusive`.
+func checkBound(path string, value, bound interface{}, upper, exclusive bool) []error {
+	number, isNumber := asFloat(value)
+	boundNum, isBound := asFloat(bound)
+	if !isNumber || !isBound {
+		return nil
+	}
+	op, violated := ">", number < boundNum
+	if upper {
+		op, violated = "<", number > boundNum
+	}
+	if exclusive {
+		violated = violated || number == boundNum
+	} else {
+		op += "="
+	}
+	if !violated {
+		return nil
+	}
+	return []error{fmt.Errorf("%s: expected a value %s %v, got %v", path, op, bound, value)}
+}
+
+// checkMultipleOf checks a number is a multiple of `multipleOf`.
+func checkMultipleOf(path string, value, multipleOf interface{}) []error {
+	number, isNumber := asFloat(value)
+	divisor, isDivisor := asFloat(multipleOf)
+	if !isNumber || !isDivisor || divisor <= 0 || yttlibrary.IsMultipleOf(number, divisor) {
+		return nil
+	}
+	return []error{fmt.Errorf("%s: expected a multiple of %v, got %v", path, multipleOf, value)}
+}
+
+// checkCondition checks the map `mapVal` has the keys `condition` requires, if its key is given with its value.
+func checkCondition(path string, condition *keyCondition, mapVal *yamlmeta.Map) []error {
+	given := map[interface{}]interface{}{}
+	for _, item := range mapVal.Items {
+		given[item.Key] = item.Value
+	}
+	if value, found := given[condition.key]; !found || !containsValue([]interface{}{condition.equals}, value) {
+		return nil
+	}
+	var errs []error
+	for _, key := range condition.requires {
+		if _, found := given[key]; !found {
+			errs = append(errs, fmt.Errorf("%s.%s: missing key required when %s is %v", path, key, condition.key, condition.equals))
+		}
+	}
+	return errs
+}
+
+// checkPattern checks a string matches `pattern` (or, if `negated`, does not).
+func checkPattern(path, str, pattern string, negated bool) []error {
+	matched, err := regexp.MatchString(pattern, str)
+	if err != nil || matched != negated {
+		return nil
+	}
+	if negated {
+		return []error{fmt.Errorf("%s: expected a value not matching %s, got %q", path, pattern, str)}
+	}
+	return []error{fmt.Errorf("%s: expected a value matching %s, got %q", path, pattern, str)}
+}
+
+// checkAllowed checks `value` is one of `allowed`.
+func checkAllowed(path string, allowed []interface{}, value interface{}) []error {
+	if containsValue(allowed, value) {
+		return nil
+	}
+	return []error{fmt.Errorf("%s: expected one of %v, got %v", path, allowed, asComparable(value))}
+}
+
+// checkKeyPattern checks the key at `path` matches `pattern`.
+func checkKeyPattern(path, key, pattern string) []error {
+	if matched, err := regexp.MatchString(pattern, key); err == nil && !matched {
+		return []error{fmt.Errorf("%s: expected a key matching %s", path, pattern)}
+	}
+	return nil
+}
+
+func missingKeyError(path string, key interface{}) error {
+	return fmt.Errorf("%s.%v: missing required key", path, key)
+}
+
+func missingDependencyError(path string, key, dependentKey interface{}) error {
+	return fmt.Errorf("%s.%v: missing key required by %v", path, key, dependentKey)
+}
+
+// orNil gives `value` if `found`, nil otherwise (i.e. unbounded, see checkLength()).
+func orNil(value int64, found bool) interface{} {
+	if !found {
+		return nil
+	}
+	return value
+}
+
+func mismatchError(path, expected string, value interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %s", path, expected, jsonTypeOf(value))
+}
+
+// jsonTypeOf names the JSON Schema type of `value`.
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case float32, float64:
+		return "number"
+	case *yamlmeta.Map:
+		return "object"
+	case *yamlmeta.Array:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func lengthOf(value interface{}) (int64, bool) {
+	switch typedValue := value.(type) {
+	case string:
+		return int64(len([]rune(typedValue))), true
+	case *yamlmeta.Map:
+		return int64(len(typedValue.Items)), true
+	case *yamlmeta.Array:
+		return int64(len(typedValue.Items)), true
+	}
+	return 0, false
+}
+
+func asFloat(value interface{}) (float64, bool) {
+	switch jsonTypeOf(value) {
+	case "integer", "number":
+		return reflect.ValueOf(value).Convert(reflect.TypeOf(float64(0))).Float(), true
+	}
+	return 0, false
+}
+
+// containsValue indicates whether `value` is among `values` (numbers being equal regardless of their Go type).
+func containsValue(values []interface{}, value interface{}) bool {
+	if node, ok := value.(yamlmeta.Node); ok {
+		value = node.DeepCopyAsInterface()
+	}
+	for _, candidate := range values {
+		candidateNum, candidateIsNum := asFloat(candidate)
+		valueNum, valueIsNum := asFloat(value)
+		if candidateIsNum && valueIsNum {
+			if candidateNum == valueNum {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+
+	"carvel.dev/ytt/pkg/filepos"
+)
+
+// Warning is a finding of LintSchema(): a value of a schema that is likely typed less strictly than intended.
+type Warning struct {
+	// Path is the path to the value (as given to a TypeVisitor, e.g. "foo.bar[]"); empty for the document's value.
+	Path     string
+	Position *filepos.Position
+	Message  string
+}
+
+// String describes the warning, by the path to (and the position of) the value it is about.
+func (w Warning) String() string {
+	path := w.Path
+	if path == "" {
+		path = "(document)"
+	}
+	return fmt.Sprintf("%s (%s): %s", path, w.Position.AsCompactString(), w.Message)
+}
+
+// LintSchema reports each value of `docType` that is of any type (see @schema/type any=True): being unconstrained,
+// such a value is exported as permitting every type, which is rarely the intent of the author. Warnings are given in
+// the order the values are declared.
+func LintSchema(docType *DocumentType) []Warning {
+	linter := &anyTypeLinter{}
+	// (the linter never aborts the traversal)
+	_ = docType.Walk(linter)
+	return linter.warnings
+}
+
+// anyTypeLinter notes a Warning for each value of any type visited.
+type anyTypeLinter struct {
+	warnings []Warning
+}
+
+func (l *anyTypeLinter) VisitMap(*MapType, Type, string) error       { return nil }
+func (l *anyTypeLinter) VisitArray(*ArrayType, Type, string) error   { return nil }
+func (l *anyTypeLinter) VisitScalar(*ScalarType, Type, string) error { return nil }
+func (l *anyTypeLinter) VisitNull(*NullType, Type, string) error     { return nil }
+func (l *anyTypeLinter) VisitAny(typ *AnyType, _ Type, path string) error {
+	l.warnings = append(l.warnings, Warning{
+		Path:     path,
+		Position: typ.GetDefinitionPosition(),
+		Message:  "value is of any type, so permits any value; consider typing it more strictly (e.g. by its default, or via @schema/type)",
+	})
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"testing"
+
+	"carvel.dev/ytt/pkg/schema"
+	"carvel.dev/ytt/pkg/template"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintSchema(t *testing.T) {
+	docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(`
+name: ""
+labels: {}
+db:
+  port: 5432
+  options: ""
+`), yamlmeta.DocSetOpts{AssociatedName: "schema.yml"})
+	require.NoError(t, err)
+	anyType := template.NodeAnnotations{
+		schema.AnnotationType: template.NodeAnnotation{Kwargs: []starlark.Tuple{{starlark.String("any"), starlark.Bool(true)}}},
+	}
+	root := docSet.Items[0].Value.(*yamlmeta.Map)
+	root.Items[1].SetAnnotations(anyType)
+	root.Items[2].Value.(*yamlmeta.Map).Items[1].SetAnnotations(anyType)
+	docType, err := schema.NewDocumentType(docSet.Items[0])
+	require.NoError(t, err)
+
+	warnings := schema.LintSchema(docType)
+	require.Len(t, warnings, 2)
+	require.Equal(t, "labels", warnings[0].Path)
+	require.Equal(t, "db.options", warnings[1].Path)
+	require.Equal(t, "db.options (schema.yml:6): value is of any type, so permits any value; consider typing it more strictly (e.g. by its default, or via @schema/type)", warnings[1].String())
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+)
+
+// MergeTypes combines `a` and `b` (e.g. the schemas of data values from different sources) into a type describing
+// values of either shape: maps declare the keys of both, arrays have items of the merged type of each, values are
+// nullable if either is, and a value of any type stays so.
+//
+// Where both declare something (e.g. a key), `a` takes precedence for what cannot be merged: defaults, documentation,
+// validations and other annotated properties. Neither `a` nor `b` is modified.
+//
+// Returns an error if scalars differ in type, or if a value is a map in one and an array (or a scalar) in the other.
+func MergeTypes(a, b Type) (Type, error) {
+	return mergeTypes(a, b, "")
+}
+
+func mergeTypes(a, b Type, path string) (Type, error) {
+	if _, isAny := a.(*AnyType); isAny {
+		return a, nil
+	}
+	if _, isAny := b.(*AnyType); isAny {
+		return b, nil
+	}
+	if nullType, isNullable := a.(*NullType); isNullable {
+		return mergeIntoNullable(nullType, b, path)
+	}
+	if nullType, isNullable := b.(*NullType); isNullable {
+		merged, err := mergeTypes(a, nullType.GetValueType(), path)
+		if err != nil {
+			return nil, err
+		}
+		return &NullType{ValueType: merged, Position: nullType.Position, documentation: nullType.documentation}, nil
+	}
+
+	switch typedA := a.(type) {
+	case *DocumentType:
+		typedB, ok := b.(*DocumentType)
+		if !ok {
+			return nil, mergeConflictError(a, b, path)
+		}
+		valueType, err := mergeTypes(typedA.GetValueType(), typedB.GetValueType(), path)
+		if err != nil {
+			return nil, err
+		}
+		merged := *typedA
+		merged.ValueType = valueType
+		merged.defaultValue = valueType.GetDefaultValue()
+		if merged.validations == nil {
+			merged.validations = typedB.validations
+		}
+		return &merged, nil
+
+	case *MapType:
+		typedB, ok := b.(*MapType)
+		if !ok {
+			return nil, mergeConflictError(a, b, path)
+		}
+		merged := *typedA
+		merged.Items = nil
+		for _, item := range typedA.Items {
+			otherItem := typedB.findItem(item.Key)
+			if otherItem == nil {
+				merged.Items = append(merged.Items, item)
+				continue
+			}
+			mergedItem, err := mergeMapItemTypes(item, otherItem, pathToKey(path, item.Key))
+			if err != nil {
+				return nil, err
+			}
+			merged.Items = append(merged.Items, mergedItem)
+		}
+		for _, item := range typedB.Items {
+			if typedA.findItem(item.Key) == nil {
+				merged.Items = append(merged.Items, item)
+			}
+		}
+		return &merged, nil
+
+	case *ArrayType:
+		typedB, ok := b.(*ArrayType)
+		if !ok {
+			return nil, mergeConflictError(a, b, path)
+		}
+		itemA, itemB := typedA.GetValueType().(*ArrayItemType), typedB.GetValueType().(*ArrayItemType)
+		valueType, err := mergeTypes(itemA.GetValueType(), itemB.GetValueType(), path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		mergedItem := *itemA
+		mergedItem.ValueType = valueType
+		if mergedItem.validations == nil {
+			mergedItem.validations = itemB.validations
+		}
+		merged := *typedA
+		merged.ItemsType = &mergedItem
+		return &merged, nil
+
+	case *ScalarType:
+		typedB, ok := b.(*ScalarType)
+		if !ok || typedA.ValueType != typedB.ValueType {
+			return nil, mergeConflictError(a, b, path)
+		}
+		return a, nil
+
+	default:
+		panic(fmt.Sprintf("Unrecognized type %T", a))
+	}
+}
+
+func mergeIntoNullable(nullType *NullType, b Type, path string) (Type, error) {
+	other := b
+	if otherNull, isNullable := b.(*NullType); isNullable {
+		other = otherNull.GetValueType()
+	}
+	merged, err := mergeTypes(nullType.GetValueType(), other, path)
+	if err != nil {
+		return nil, err
+	}
+	result := *nullType
+	result.ValueType = merged
+	return &result, nil
+}
+
+func mergeMapItemTypes(a, b *MapItemType, path string) (*MapItemType, error) {
+	valueType, err := mergeTypes(a.GetValueType(), b.GetValueType(), path)
+	if err != nil {
+		return nil, err
+	}
+	merged := *a
+	merged.ValueType = valueType
+	if _, isMap := valueType.(*MapType); isMap {
+		// the default of a map is made of those of its keys, which now include those of `b`.
+		merged.defaultValue = valueType.GetDefaultValue()
+	}
+	if merged.validations == nil {
+		merged.validations = b.validations
+	}
+	return &merged, nil
+}
+
+func mergeConflictError(a, b Type, path string) error {
+	if path == "" {
+		return fmt.Errorf("Unable to merge schemas of documents: %s in one, %s in the other", a.String(), b.String())
+	}
+	return fmt.Errorf("Unable to merge schemas at '%s': %s in one, %s in the other", path, a.String(), b.String())
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"testing"
+
+	"carvel.dev/ytt/pkg/schema"
+	"carvel.dev/ytt/pkg/template"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTypes(t *testing.T) {
+	docTypeOf := func(t *testing.T, schemaYAML string) *schema.DocumentType {
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(schemaYAML), yamlmeta.DocSetOpts{AssociatedName: "schema.yml"})
+		require.NoError(t, err)
+		docType, err := schema.NewDocumentType(docSet.Items[0])
+		require.NoError(t, err)
+		return docType
+	}
+	scalarPathsOf := func(t *testing.T, typ schema.Type) []string {
+		counter := &scalarCounter{}
+		require.NoError(t, typ.(*schema.DocumentType).Walk(counter))
+		return counter.paths
+	}
+
+	t.Run("declares the keys of both maps, those of the first first", func(t *testing.T) {
+		a := docTypeOf(t, `
+name: app
+db:
+  port: 5432
+`)
+		b := docTypeOf(t, `
+db:
+  host: localhost
+  port: 3306
+replicas: 1
+`)
+		merged, err := schema.MergeTypes(a, b)
+		require.NoError(t, err)
+		require.Equal(t, []string{"name", "db.port", "db.host", "replicas"}, scalarPathsOf(t, merged))
+
+		defaults := merged.GetDefaultValue().(*yamlmeta.Document).Value.(*yamlmeta.Map)
+		require.Equal(t, "app", defaults.Items[0].Value)
+		db := defaults.Items[1].Value.(*yamlmeta.Map)
+		require.Len(t, db.Items, 2)
+		require.Equal(t, 5432, db.Items[0].Value)
+		require.Equal(t, "localhost", db.Items[1].Value)
+		require.Equal(t, 1, defaults.Items[2].Value)
+
+		require.Len(t, a.GetValueType().(*schema.MapType).Items, 2, "the first type is left as is")
+	})
+
+	t.Run("merges the item types of arrays", func(t *testing.T) {
+		a := docTypeOf(t, `
+servers:
+- host: ""
+`)
+		b := docTypeOf(t, `
+servers:
+- port: 80
+`)
+		merged, err := schema.MergeTypes(a, b)
+		require.NoError(t, err)
+		require.Equal(t, []string{"servers[].host", "servers[].port"}, scalarPathsOf(t, merged))
+	})
+
+	t.Run("is nullable where either type is", func(t *testing.T) {
+		a := docTypeOf(t, `
+name: ""
+`)
+		docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(`name: ""`), yamlmeta.DocSetOpts{AssociatedName: "schema.yml"})
+		require.NoError(t, err)
+		docSet.Items[0].Value.(*yamlmeta.Map).Items[0].SetAnnotations(template.NodeAnnotations{
+			schema.AnnotationNullable: template.NodeAnnotation{},
+		})
+		b, err := schema.NewDocumentType(docSet.Items[0])
+		require.NoError(t, err)
+
+		merged, err := schema.MergeTypes(a, b)
+		require.NoError(t, err)
+		name := merged.GetValueType().(*schema.MapType).Items[0]
+		require.IsType(t, &schema.NullType{}, name.GetValueType())
+	})
+
+	t.Run("fails when scalars differ in type", func(t *testing.T) {
+		a := docTypeOf(t, `
+db:
+  port: 5432
+`)
+		b := docTypeOf(t, `
+db:
+  port: "5432"
+`)
+		_, err := schema.MergeTypes(a, b)
+		require.EqualError(t, err, "Unable to merge schemas at 'db.port': integer in one, string in the other")
+	})
+
+	t.Run("fails when a map is an array in the other", func(t *testing.T) {
+		a := docTypeOf(t, `
+servers:
+  main: ""
+`)
+		b := docTypeOf(t, `
+servers:
+- ""
+`)
+		_, err := schema.MergeTypes(a, b)
+		require.EqualError(t, err, "Unable to merge schemas at 'servers': map in one, array in the other")
+	})
+}
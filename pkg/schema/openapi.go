@@ -6,6 +6,7 @@ package schema
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"carvel.dev/ytt/pkg/yamlmeta"
 )
@@ -18,6 +19,8 @@ const (
 	formatProp             = "format"
 	nullableProp           = "nullable"
 	deprecatedProp         = "deprecated"
+	readOnlyProp           = "readOnly"
+	writeOnlyProp          = "writeOnly"
 	descriptionProp        = "description"
 	exampleDescriptionProp = "x-example-description"
 	exampleProp            = "example"
@@ -26,61 +29,175 @@ const (
 	defaultProp            = "default"
 	minProp                = "minimum"
 	maxProp                = "maximum"
+	exclusiveMinProp       = "exclusiveMinimum"
+	exclusiveMaxProp       = "exclusiveMaximum"
+	multipleOfProp         = "multipleOf"
 	minLenProp             = "minLength" // for strings
 	maxLenProp             = "maxLength"
 	minItemsProp           = "minItems" // for arrays
 	maxItemsProp           = "maxItems"
 	minPropertiesProp      = "minProperties" // for objects
 	maxPropertiesProp      = "maxProperties"
+	uniqueItemsProp        = "uniqueItems"
 	enumProp               = "enum"
+	discriminatorProp      = "discriminator"
+	propertyNameProp       = "propertyName"
 )
 
 var propOrder = map[string]int{
-	titleProp:              0,
-	typeProp:               1,
-	additionalPropsProp:    2,
-	formatProp:             3,
-	nullableProp:           4,
-	deprecatedProp:         5,
-	descriptionProp:        6,
-	exampleDescriptionProp: 7,
-	exampleProp:            8,
-	itemsProp:              9,
-	propertiesProp:         10,
-	defaultProp:            11,
-	minProp:                12,
-	maxProp:                13,
-	minLenProp:             14,
-	maxLenProp:             15,
-	minItemsProp:           16,
-	maxItemsProp:           17,
-	minPropertiesProp:      18,
-	maxPropertiesProp:      19,
-	enumProp:               20,
+	refProp:                0,
+	idProp:                 1,
+	id04Prop:               2,
+	anchorProp:             3,
+	titleProp:              4,
+	typeProp:               5,
+	additionalPropsProp:    6,
+	propertyNamesProp:      7,
+	formatProp:             8,
+	contentEncodingProp:    9,
+	contentMediaTypeProp:   10,
+	nullableProp:           11,
+	deprecatedProp:         12,
+	readOnlyProp:           13,
+	writeOnlyProp:          14,
+	descriptionProp:        15,
+	commentProp:            16,
+	exampleDescriptionProp: 17,
+	exampleProp:            18,
+	examplesProp:           19,
+	prefixItemsProp:        20,
+	itemsProp:              21,
+	additionalItemsProp:    22,
+	propertiesProp:         23,
+	patternPropertiesProp:  24,
+	discriminatorProp:      25,
+	requiredProp:           26,
+	dependentRequiredProp:  27,
+	dependenciesProp:       28,
+	ifProp:                 29,
+	thenProp:               30,
+	defaultProp:            31,
+	minProp:                32,
+	maxProp:                33,
+	exclusiveMinProp:       34,
+	exclusiveMaxProp:       35,
+	multipleOfProp:         36,
+	minLenProp:             37,
+	maxLenProp:             38,
+	minItemsProp:           39,
+	maxItemsProp:           40,
+	uniqueItemsProp:        41,
+	containsProp:           42,
+	minContainsProp:        43,
+	maxContainsProp:        44,
+	minPropertiesProp:      45,
+	maxPropertiesProp:      46,
+	enumProp:               47,
+	constProp:              48,
+	patternProp:            49,
+	allOfProp:              50,
+	anyOfProp:              51,
+	notProp:                52,
+	defsProp:               53,
+	defs07Prop:             54,
+}
+
+// DefaultKeywordLess orders keywords as in OpenAPI documents (see propOrder); keywords not listed there come first,
+// except vendor extensions (see @schema/extension), which come last.
+//
+// Keywords are sorted stably, so that those it considers equal keep the order in which they were generated.
+func DefaultKeywordLess(keyword, otherKeyword string) bool {
+	return keywordRank(propOrder, keyword) < keywordRank(propOrder, otherKeyword)
+}
+
+// keywordRank places `keyword` according to `order`: keywords not listed there first, vendor extensions last.
+func keywordRank(order map[string]int, keyword string) int {
+	if rank, found := order[keyword]; found {
+		return rank
+	}
+	if strings.HasPrefix(keyword, extensionPrefix) {
+		return len(order)
+	}
+	return 0
 }
 
 type openAPIKeys []*yamlmeta.MapItem
 
 func (o openAPIKeys) Len() int { return len(o) }
 func (o openAPIKeys) Less(i, j int) bool {
-	return propOrder[o[i].Key.(string)] < propOrder[o[j].Key.(string)]
+	return DefaultKeywordLess(o[i].Key.(string), o[j].Key.(string))
 }
 func (o openAPIKeys) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
 
+// openAPISchemasPointer is the base of references to the schemas declared among the components of an OpenAPI document.
+const openAPISchemasPointer = "#/components/schemas/"
+
+// OpenAPIParameterLocations lists all locations of parameters (see OpenAPIOpts.ParametersIn).
+var OpenAPIParameterLocations = []string{"query", "path", "header", "cookie"}
+
+// OpenAPIOpts configures the OpenAPI document generated from a DocumentType
+type OpenAPIOpts struct {
+	// Refs declares maps repeated throughout the schema (or named via @schema/schema-name) once, among the schemas of
+	// the components (e.g. "#/components/schemas/Type1"), and refers to them there, rather than inlining every map.
+	Refs bool
+	// ParametersIn, when given (one of OpenAPIParameterLocations), declares each data value as a parameter (of that
+	// location) among the components, rather than the data values as a schema. Only scalar data values can be.
+	ParametersIn string
+	// NoPasswordFormat omits the `format: password` otherwise given to write-only strings (see writeOnlyString()), so
+	// that forms mask them.
+	NoPasswordFormat bool
+}
+
 // OpenAPIDocument holds the document type used for creating an OpenAPI document
 type OpenAPIDocument struct {
-	docType *DocumentType
+	docType    *DocumentType
+	opts       OpenAPIOpts
+	components *schemaDefs
 }
 
 // NewOpenAPIDocument creates an instance of an OpenAPIDocument based on the given DocumentType
 func NewOpenAPIDocument(docType *DocumentType) *OpenAPIDocument {
-	return &OpenAPIDocument{docType}
+	return &OpenAPIDocument{docType: docType}
+}
+
+// NewOpenAPIDocumentWithOpts creates an instance of an OpenAPIDocument based on the given DocumentType, configured by
+// `opts`.
+//
+// Returns an error if `opts` sets Refs and the same schema name (see @schema/schema-name) is given to maps that differ,
+// or sets ParametersIn to an unknown location or while some data value is not a scalar.
+func NewOpenAPIDocumentWithOpts(docType *DocumentType, opts OpenAPIOpts) (*OpenAPIDocument, error) {
+	doc := &OpenAPIDocument{docType: docType, opts: opts}
+	if opts.ParametersIn != "" {
+		if err := doc.checkParameters(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Refs {
+		if err := doc.findRepeatedMapTypes().checkSchemaNames(); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
 }
 
 // AsDocument generates a new AST of this OpenAPI v3.0.x document, populating the `schemas:` section with the
-// type information contained in `docType`.
+// type information contained in `docType` (and, if Refs is set, with the maps it refers to).
 func (o *OpenAPIDocument) AsDocument() *yamlmeta.Document {
-	openAPIProperties := o.calculateProperties(o.docType)
+	o.components = nil
+	var components *yamlmeta.MapItem
+	if o.opts.ParametersIn != "" {
+		components = &yamlmeta.MapItem{Key: "parameters", Value: o.parameters()}
+	} else {
+		if o.opts.Refs {
+			o.findRepeatedMapTypes()
+		}
+		openAPIProperties := o.calculateProperties(o.docType)
+		schemas := []*yamlmeta.MapItem{{Key: "dataValues", Value: openAPIProperties}}
+		if components := o.components.asMap(); components != nil {
+			schemas = append(schemas, components.Items...)
+		}
+		components = &yamlmeta.MapItem{Key: "schemas", Value: &yamlmeta.Map{Items: schemas}}
+	}
 
 	return &yamlmeta.Document{Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
 		{Key: "openapi", Value: "3.0.0"},
@@ -89,28 +206,94 @@ func (o *OpenAPIDocument) AsDocument() *yamlmeta.Document {
 			{Key: titleProp, Value: "Schema for data values, generated by ytt"},
 		}}},
 		{Key: "paths", Value: &yamlmeta.Map{}},
-		{Key: "components", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
-			{Key: "schemas", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
-				{Key: "dataValues", Value: openAPIProperties},
-			}}},
-		}}},
+		{Key: "components", Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{components}}},
 	}}}
 }
 
+// checkParameters checks that each data value can be declared as a parameter (see ParametersIn): that it is a scalar
+// (if nullable), within a map.
+func (o *OpenAPIDocument) checkParameters() error {
+	found := false
+	for _, location := range OpenAPIParameterLocations {
+		found = found || o.opts.ParametersIn == location
+	}
+	if !found {
+		return fmt.Errorf("Unknown OpenAPI parameter location '%s' (supported locations: %s)", o.opts.ParametersIn, strings.Join(OpenAPIParameterLocations, ", "))
+	}
+	mapType, isMap := o.docType.GetValueType().(*MapType)
+	if !isMap {
+		return fmt.Errorf("Expected data values to be a map, to be declared as OpenAPI parameters, but was %s", o.docType.GetValueType().String())
+	}
+	for _, item := range mapType.Items {
+		valueType := item.GetValueType()
+		if nullType, isNullable := valueType.(*NullType); isNullable && nullType.GetValueType() != nil {
+			valueType = nullType.GetValueType()
+		}
+		if _, isScalar := valueType.(*ScalarType); !isScalar {
+			return fmt.Errorf("Expected data value '%v' to be a scalar, to be declared as an OpenAPI parameter, but was %s", item.Key, item.GetValueType().String())
+		}
+	}
+	return nil
+}
+
+// parameters declares each data value as a parameter (see ParametersIn), named after its key and described by the
+// schema of a property; only a parameter in the path is required (otherwise, one not given defaults as its data
+// value does).
+func (o *OpenAPIDocument) parameters() *yamlmeta.Map {
+	parameters := &yamlmeta.Map{}
+	for _, item := range o.docType.GetValueType().(*MapType).Items {
+		parameters.Items = append(parameters.Items, &yamlmeta.MapItem{Key: item.Key, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{
+			{Key: "name", Value: item.Key},
+			{Key: "in", Value: o.opts.ParametersIn},
+			{Key: requiredProp, Value: o.opts.ParametersIn == "path"},
+			{Key: "schema", Value: o.calculateProperties(item)},
+		}}})
+	}
+	return parameters
+}
+
+// findRepeatedMapTypes generates the schema with every map inlined, recording the fingerprint of each map.
+func (o *OpenAPIDocument) findRepeatedMapTypes() *schemaDefs {
+	o.components = newSchemaDefs("")
+	o.calculateProperties(o.docType)
+	o.components.collecting = false
+	return o.components
+}
+
+// withKeywords adds `keywords` to `schema`.
+//
+// In OpenAPI v3.0, keywords alongside a "$ref" are ignored; there, a reference is wrapped so that the keywords apply.
+func (o *OpenAPIDocument) withKeywords(schema *yamlmeta.Map, keywords []*yamlmeta.MapItem) *yamlmeta.Map {
+	if len(keywords) == 0 {
+		return schema
+	}
+	if hasKey(schema, refProp) {
+		schema = &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: allOfProp, Value: []interface{}{schema}}}}
+	}
+	schema.Items = append(schema.Items, keywords...)
+	return schema
+}
+
 func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.Map {
 	switch typedValue := schemaVal.(type) {
 	case *DocumentType:
 		result := o.calculateProperties(typedValue.GetValueType())
 		result.Items = append(result.Items, o.convertValidations(typedValue)...)
-		sort.Sort(openAPIKeys(result.Items))
+		sort.Stable(openAPIKeys(result.Items))
 		return result
 
 	case *MapType:
+		if typedValue.unwrapped && len(typedValue.Items) == 1 {
+			// the wrapper (see @schema/unwrap) is left out: its only key is described in its place.
+			return o.calculateProperties(typedValue.Items[0])
+		}
 		var items openAPIKeys
 		items = append(items, o.collectDocumentation(typedValue)...)
 		items = append(items, o.convertValidations(typedValue)...)
 		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: "object"})
-		items = append(items, &yamlmeta.MapItem{Key: additionalPropsProp, Value: false})
+		if !typedValue.noAdditionalPropsKey {
+			items = append(items, &yamlmeta.MapItem{Key: additionalPropsProp, Value: o.additionalPropertiesOf(typedValue)})
+		}
 
 		var properties []*yamlmeta.MapItem
 		for _, i := range typedValue.Items {
@@ -118,14 +301,21 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 			properties = append(properties, &mi)
 		}
 		items = append(items, &yamlmeta.MapItem{Key: propertiesProp, Value: &yamlmeta.Map{Items: properties}})
+		if typedValue.discriminator != "" {
+			// there are no variants (i.e. "oneOf") to map the values of the discriminator to; only its name is given.
+			discriminator := &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: propertyNameProp, Value: typedValue.discriminator}}}
+			items = append(items, &yamlmeta.MapItem{Key: discriminatorProp, Value: discriminator})
+		}
 
-		sort.Sort(items)
-		return &yamlmeta.Map{Items: items}
+		sort.Stable(items)
+		if o.components == nil || typedValue == o.docType.GetValueType() {
+			return &yamlmeta.Map{Items: items}
+		}
+		return o.components.reference(typedValue, &yamlmeta.Map{Items: items}, openAPISchemasPointer)
 
 	case *MapItemType:
-		result := o.calculateProperties(typedValue.GetValueType())
-		result.Items = append(result.Items, o.convertValidations(typedValue)...)
-		sort.Sort(openAPIKeys(result.Items))
+		result := o.withKeywords(o.calculateProperties(typedValue.GetValueType()), o.convertValidations(typedValue))
+		sort.Stable(openAPIKeys(result.Items))
 		return result
 
 	case *ArrayType:
@@ -135,11 +325,27 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: "array"})
 		items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: typedValue.GetDefaultValue()})
 
+		if typedValue.tupleItems != nil {
+			// OpenAPI 3.0 has no tuples: each item is of the type of any of those of the tuple, which are all given.
+			var schemas []interface{}
+			for _, itemType := range typedValue.tupleItems {
+				schemas = append(schemas, o.calculateProperties(itemType.GetValueType()))
+			}
+			items = append(items, &yamlmeta.MapItem{Key: itemsProp, Value: &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: anyOfProp, Value: schemas}}}})
+			for _, lengthProp := range []string{minItemsProp, maxItemsProp} {
+				if !hasKey(&yamlmeta.Map{Items: items}, lengthProp) {
+					items = append(items, &yamlmeta.MapItem{Key: lengthProp, Value: len(schemas)})
+				}
+			}
+			sort.Stable(items)
+			return &yamlmeta.Map{Items: items}
+		}
+
 		valueType := typedValue.GetValueType().(*ArrayItemType)
 		properties := o.calculateProperties(valueType.GetValueType())
 		items = append(items, &yamlmeta.MapItem{Key: itemsProp, Value: properties})
 
-		sort.Sort(items)
+		sort.Stable(items)
 		return &yamlmeta.Map{Items: items}
 
 	case *ScalarType:
@@ -151,23 +357,44 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 		typeString := o.openAPITypeFor(typedValue)
 		items = append(items, &yamlmeta.MapItem{Key: typeProp, Value: typeString})
 
-		if typedValue.String() == "float" {
+		if typedValue.String() == "float" && typeString == "number" {
 			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: "float"})
 		}
+		if typedValue.format != nil {
+			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: typedValue.format.format})
+		} else if !o.opts.NoPasswordFormat && writeOnlyString(typedValue) {
+			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: passwordFormat})
+		}
 
-		sort.Sort(items)
+		sort.Stable(items)
 		return &yamlmeta.Map{Items: items}
 
 	case *NullType:
 		var items openAPIKeys
 		items = append(items, o.collectDocumentation(typedValue)...)
 		items = append(items, o.convertValidations(typedValue)...)
+		// OpenAPI v3.0 has no "null" type; the value keeps its one type, marked "nullable" (unlike in JSON Schema, see
+		// JSONSchemaDocument.calculateProperties()).
 		items = append(items, &yamlmeta.MapItem{Key: nullableProp, Value: true})
+		if typedValue.GetValueType() == nil {
+			// declared as null, with no other type: lacking a "null" type, null is the only value enumerated.
+			items = append(items, &yamlmeta.MapItem{Key: enumProp, Value: []interface{}{nil}})
+			items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: nil})
+			sort.Stable(items)
+			return &yamlmeta.Map{Items: items}
+		}
 
 		properties := o.calculateProperties(typedValue.GetValueType())
+		if hasKey(properties, refProp) {
+			// (as keywords alongside a "$ref" are ignored)
+			properties = &yamlmeta.Map{Items: []*yamlmeta.MapItem{{Key: allOfProp, Value: []interface{}{properties}}}}
+		}
 		items = append(items, properties.Items...)
+		if !o.opts.NoPasswordFormat && writeOnlyString(typedValue) && !hasKey(properties, formatProp) {
+			items = append(items, &yamlmeta.MapItem{Key: formatProp, Value: passwordFormat})
+		}
 
-		sort.Sort(items)
+		sort.Stable(items)
 		return &yamlmeta.Map{Items: items}
 
 	case *AnyType:
@@ -177,7 +404,7 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 		items = append(items, &yamlmeta.MapItem{Key: nullableProp, Value: true})
 		items = append(items, &yamlmeta.MapItem{Key: defaultProp, Value: typedValue.GetDefaultValue()})
 
-		sort.Sort(items)
+		sort.Stable(items)
 		return &yamlmeta.Map{Items: items}
 
 	default:
@@ -185,25 +412,91 @@ func (o *OpenAPIDocument) calculateProperties(schemaVal interface{}) *yamlmeta.M
 	}
 }
 
+// additionalPropertiesOf describes which keys (beyond those declared) `mapType` permits: none, any, or those whose
+// values are of the type given via @schema/allow-extra-properties.
+func (o *OpenAPIDocument) additionalPropertiesOf(mapType *MapType) interface{} {
+	if !mapType.allowExtraProperties {
+		return false
+	}
+	if mapType.extraPropertiesType == nil {
+		return true
+	}
+	return o.calculateProperties(mapType.extraPropertiesType)
+}
+
 func (*OpenAPIDocument) collectDocumentation(typedValue Type) []*yamlmeta.MapItem {
 	var items []*yamlmeta.MapItem
 	if typedValue.GetTitle() != "" {
 		items = append(items, &yamlmeta.MapItem{Key: titleProp, Value: typedValue.GetTitle()})
 	}
-	if typedValue.GetDescription() != "" {
-		items = append(items, &yamlmeta.MapItem{Key: descriptionProp, Value: typedValue.GetDescription()})
+	if description := describe(typedValue); description != "" {
+		items = append(items, &yamlmeta.MapItem{Key: descriptionProp, Value: description})
 	}
 	if isDeprecated, _ := typedValue.IsDeprecated(); isDeprecated {
 		items = append(items, &yamlmeta.MapItem{Key: deprecatedProp, Value: isDeprecated})
 	}
+	items = append(items, accessKeywords(typedValue)...)
 	examples := typedValue.GetExamples()
 	if len(examples) != 0 {
 		items = append(items, &yamlmeta.MapItem{Key: exampleDescriptionProp, Value: examples[0].description})
 		items = append(items, &yamlmeta.MapItem{Key: exampleProp, Value: examples[0].example})
 	}
+	items = append(items, extensionKeywords(typedValue)...)
+	return items
+}
+
+// accessKeywords marks `typedValue` as read-only or write-only, if so annotated (vocabulary shared by OpenAPI v3.0 and
+// JSON Schema).
+func accessKeywords(typedValue Type) []*yamlmeta.MapItem {
+	var items []*yamlmeta.MapItem
+	readOnly, writeOnly := typedValue.GetAccess()
+	if readOnly {
+		items = append(items, &yamlmeta.MapItem{Key: readOnlyProp, Value: true})
+	}
+	if writeOnly {
+		items = append(items, &yamlmeta.MapItem{Key: writeOnlyProp, Value: true})
+	}
+	return items
+}
+
+// passwordFormat is the format of write-only strings (see writeOnlyString()).
+const passwordFormat = "password"
+
+// writeOnlyString indicates whether `typedValue` is a write-only (see @schema/write-only) string (or null, if nullable),
+// not given a format via @schema/format: a secret, which forms mask when given `format: password`.
+func writeOnlyString(typedValue Type) bool {
+	if _, writeOnly := typedValue.GetAccess(); !writeOnly {
+		return false
+	}
+	scalarType, isScalar := typedValue.(*ScalarType)
+	if nullType, isNullable := typedValue.(*NullType); isNullable {
+		scalarType, isScalar = nullType.GetValueType().(*ScalarType)
+	}
+	return isScalar && scalarType.ValueType == StringType && scalarType.format == nil
+}
+
+// extensionKeywords gives the vendor extensions of `typedValue` (see @schema/extension), in the order given
+// (vocabulary shared by OpenAPI v3.0 and JSON Schema).
+func extensionKeywords(typedValue Type) []*yamlmeta.MapItem {
+	var items []*yamlmeta.MapItem
+	for _, extension := range typedValue.GetExtensions() {
+		items = append(items, &yamlmeta.MapItem{Key: extension.name, Value: extension.value})
+	}
 	return items
 }
 
+// describe produces the description of `typedValue`, followed by its deprecation notice (if any).
+func describe(typedValue Type) string {
+	description := typedValue.GetDescription()
+	if isDeprecated, notice := typedValue.IsDeprecated(); isDeprecated && notice != "" {
+		if description != "" {
+			description += "\n"
+		}
+		description += "Deprecated: " + notice
+	}
+	return description
+}
+
 // convertValidations converts the starlark validation map to a list of OpenAPI properties
 func (*OpenAPIDocument) convertValidations(schemaVal Type) []*yamlmeta.MapItem {
 	validation := schemaVal.GetValidation()
@@ -239,11 +532,33 @@ func (*OpenAPIDocument) convertValidations(schemaVal Type) []*yamlmeta.MapItem {
 			}
 		}
 	}
+	if _, isMap := schemaVal.GetValueType().(*MapType); isMap {
+		if value, found := validation.HasSimpleMinProperties(); found {
+			items = append(items, &yamlmeta.MapItem{Key: minPropertiesProp, Value: value})
+		}
+		if value, found := validation.HasSimpleMaxProperties(); found {
+			items = append(items, &yamlmeta.MapItem{Key: maxPropertiesProp, Value: value})
+		}
+	}
 	if value, found := validation.HasSimpleMin(); found {
 		items = append(items, &yamlmeta.MapItem{Key: minProp, Value: value})
+		if validation.HasExclusiveBounds() {
+			items = append(items, &yamlmeta.MapItem{Key: exclusiveMinProp, Value: true})
+		}
 	}
 	if value, found := validation.HasSimpleMax(); found {
 		items = append(items, &yamlmeta.MapItem{Key: maxProp, Value: value})
+		if validation.HasExclusiveBounds() {
+			items = append(items, &yamlmeta.MapItem{Key: exclusiveMaxProp, Value: true})
+		}
+	}
+	if value, found := validation.HasSimpleMultipleOf(); found {
+		items = append(items, &yamlmeta.MapItem{Key: multipleOfProp, Value: value})
+	}
+	if validation.HasSimpleUnique() {
+		if _, isArray := schemaVal.GetValueType().(*ArrayType); isArray {
+			items = append(items, &yamlmeta.MapItem{Key: uniqueItemsProp, Value: true})
+		}
 	}
 	if value, found := validation.HasSimpleOneOf(); found {
 		items = append(items, &yamlmeta.MapItem{Key: enumProp, Value: value})
@@ -252,6 +567,10 @@ func (*OpenAPIDocument) convertValidations(schemaVal Type) []*yamlmeta.MapItem {
 }
 
 func (*OpenAPIDocument) openAPITypeFor(astType *ScalarType) string {
+	if astType.typeName != "" {
+		// given via @schema/type
+		return astType.typeName
+	}
 	switch astType.ValueType {
 	case StringType:
 		return "string"
@@ -23,7 +23,7 @@ func NewDocumentType(doc *yamlmeta.Document) (*DocumentType, error) {
 		return nil, err
 	}
 
-	v, err := getValidation(doc)
+	v, err := getValidation(doc, typeOfValue)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +58,7 @@ func NewMapItemType(item *yamlmeta.MapItem) (*MapItemType, error) {
 		return nil, err
 	}
 
-	v, err := getValidation(item)
+	v, err := getValidation(item, typeOfValue)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +87,24 @@ func NewArrayType(a *yamlmeta.Array) (*ArrayType, error) {
 	return &ArrayType{ItemsType: arrayItemType, defaultValue: &yamlmeta.Array{}, Position: a.Position}, nil
 }
 
+// NewTupleType creates the type of an array of exactly the items of `a`, each typed after the item at its position
+// (see @schema/tuple). Unlike that of other arrays, its default value is made of those of its items.
+func NewTupleType(a *yamlmeta.Array) (*ArrayType, error) {
+	tupleType := &ArrayType{Position: a.Position}
+	defaultValue := &yamlmeta.Array{Position: a.Position}
+	for _, item := range a.Items {
+		itemType, err := NewArrayItemType(item)
+		if err != nil {
+			return nil, err
+		}
+		tupleType.tupleItems = append(tupleType.tupleItems, itemType)
+		defaultValue.Items = append(defaultValue.Items, &yamlmeta.ArrayItem{Value: itemType.defaultValue, Position: item.Position})
+	}
+	tupleType.ItemsType = tupleType.tupleItems[0]
+	tupleType.defaultValue = defaultValue
+	return tupleType, nil
+}
+
 func NewArrayItemType(item *yamlmeta.ArrayItem) (*ArrayItemType, error) {
 	typeOfValue, err := getType(item)
 	if err != nil {
@@ -98,7 +116,7 @@ func NewArrayItemType(item *yamlmeta.ArrayItem) (*ArrayItemType, error) {
 		return nil, err
 	}
 
-	v, err := getValidation(item)
+	v, err := getValidation(item, typeOfValue)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +157,74 @@ func getType(node yamlmeta.Node) (Type, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = setExtraPropertiesFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setPatternPropertyFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setNoAdditionalPropsKeyFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setUnwrapFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setTypeNameFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setUnitFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setFormatFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setScalarOrArrayFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setNullableItemsFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setContentFromAnns(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setEnumDescriptionsFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setKeyPatternFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setSchemaNameFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setExternalRefFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setDependentRequiredFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setDiscriminatorFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
+	err = setConditionFromAnn(node, typeOfValue)
+	if err != nil {
+		return nil, err
+	}
 
 	return typeOfValue, nil
 }
@@ -158,13 +244,17 @@ func getValue(node yamlmeta.Node, t Type) (interface{}, error) {
 	return t.GetDefaultValue(), nil
 }
 
-func getValidation(node yamlmeta.Node) (*validations.NodeValidation, error) {
+func getValidation(node yamlmeta.Node, typeOfValue Type) (*validations.NodeValidation, error) {
 	validationAnn, err := processValidationAnnotation(node)
 	if err != nil {
 		return nil, err
 	}
 
 	if validationAnn != nil {
+		err = validationAnn.checkAppliesTo(node, typeOfValue)
+		if err != nil {
+			return nil, err
+		}
 		return validationAnn.GetValidation(), nil
 	}
 	return nil, nil
@@ -5,6 +5,7 @@ package schema
 
 import (
 	"fmt"
+	"regexp"
 
 	"carvel.dev/ytt/pkg/filepos"
 	"carvel.dev/ytt/pkg/validations"
@@ -25,10 +26,16 @@ type Type interface {
 	SetDescription(string)
 	GetTitle() string
 	SetTitle(string)
+	GetComment() string
+	SetComment(string)
 	GetExamples() []Example
 	SetExamples([]Example)
 	IsDeprecated() (bool, string)
 	SetDeprecated(bool, string)
+	GetAccess() (readOnly bool, writeOnly bool)
+	SetAccess(readOnly bool, writeOnly bool)
+	GetExtensions() []Extension
+	SetExtensions([]Extension)
 	GetValidation() *validations.NodeValidation
 	String() string
 }
@@ -54,6 +61,20 @@ type MapType struct {
 	Items         []*MapItemType
 	Position      *filepos.Position
 	documentation documentation
+
+	allowExtraProperties bool
+	extraPropertiesType  Type             // when nil (and extra properties are allowed), extra values can be of any type
+	patternProperty      *patternProperty // when not nil, keys (beyond those declared) matching a pattern are permitted
+	keyPattern           string           // when not empty, every key must match this regular expression
+	noAdditionalPropsKey bool             // whether "additionalProperties" is left out when exported
+	unwrapped            bool             // whether (having a single key) it is exported as the value of that key
+
+	schemaName        string          // when not empty, the name under which this map is defined when exported
+	schemaID          string          // when not empty, the URI identifying the definition of this map (in JSON Schema)
+	externalRef       string          // when not empty, the URI of the schema this map is exported as (in place of its own)
+	dependentRequired []keyDependency // keys that, when given, require others
+	condition         *keyCondition   // keys required when another has a given value
+	discriminator     string          // when not empty, the key whose value tells apart the shapes of this map (in OpenAPI)
 }
 
 type MapItemType struct {
@@ -69,6 +90,9 @@ type ArrayType struct {
 	Position      *filepos.Position
 	defaultValue  interface{}
 	documentation documentation
+
+	scalarOrArray bool   // whether, when exported, a single item is also allowed in place of the array
+	tupleItems    []Type // of each position, when a tuple (see @schema/tuple); ItemsType is then the first of them
 }
 
 type ArrayItemType struct {
@@ -83,6 +107,14 @@ type ScalarType struct {
 	Position      *filepos.Position
 	defaultValue  interface{}
 	documentation documentation
+
+	format   *FormatAnnotation // only strings (and, as their width, integers) are given a format
+	typeName string            // for numbers, the type given via @schema/type (e.g. "integer"), if any
+
+	contentEncoding  string // for strings, the encoding of their content (e.g. "base64")
+	contentMediaType string // for strings, the media type of their content (e.g. "application/json")
+
+	enumDescriptions []enumDescription // documents the values allowed via one_of=, if any
 }
 
 type AnyType struct {
@@ -105,6 +137,25 @@ const (
 	BoolType   = false
 )
 
+// matches indicates whether `key` is one of the keys described by this patternProperty (if any).
+func (p *patternProperty) matches(key interface{}) bool {
+	if p == nil {
+		return false
+	}
+	matched, err := regexp.MatchString(p.pattern, fmt.Sprintf("%v", key))
+	return err == nil && matched
+}
+
+// findItem returns the declaration of the item with the key `key`, if any.
+func (m *MapType) findItem(key interface{}) *MapItemType {
+	for _, item := range m.Items {
+		if item.Key == key {
+			return item
+		}
+	}
+	return nil
+}
+
 // GetValueType provides the type of the value
 func (t *DocumentType) GetValueType() Type {
 	return t.ValueType
@@ -125,6 +176,14 @@ func (a ArrayType) GetValueType() Type {
 	return a.ItemsType
 }
 
+// itemTypes provides the types of the items: of each position, when a tuple; otherwise, the one of every item.
+func (a ArrayType) itemTypes() []Type {
+	if a.tupleItems != nil {
+		return a.tupleItems
+	}
+	return []Type{a.ItemsType}
+}
+
 // GetValueType provides the type of the value
 func (a ArrayItemType) GetValueType() Type {
 	return a.ValueType
@@ -422,6 +481,80 @@ func (n *NullType) SetTitle(title string) {
 	n.documentation.title = title
 }
 
+// GetComment provides the comment for maintainers
+func (t *DocumentType) GetComment() string {
+	return ""
+}
+
+// GetComment provides the comment for maintainers
+func (m *MapType) GetComment() string {
+	return m.documentation.comment
+}
+
+// GetComment provides the comment for maintainers
+func (t *MapItemType) GetComment() string {
+	return ""
+}
+
+// GetComment provides the comment for maintainers
+func (a *ArrayType) GetComment() string {
+	return a.documentation.comment
+}
+
+// GetComment provides the comment for maintainers
+func (a *ArrayItemType) GetComment() string {
+	return ""
+}
+
+// GetComment provides the comment for maintainers
+func (s *ScalarType) GetComment() string {
+	return s.documentation.comment
+}
+
+// GetComment provides the comment for maintainers
+func (a *AnyType) GetComment() string {
+	return a.documentation.comment
+}
+
+// GetComment provides the comment for maintainers
+func (n *NullType) GetComment() string {
+	return n.documentation.comment
+}
+
+// SetComment sets the comment (for maintainers) of the type
+func (t *DocumentType) SetComment(_ string) {}
+
+// SetComment sets the comment (for maintainers) of the type
+func (m *MapType) SetComment(comment string) {
+	m.documentation.comment = comment
+}
+
+// SetComment sets the comment (for maintainers) of the type
+func (t *MapItemType) SetComment(_ string) {}
+
+// SetComment sets the comment (for maintainers) of the type
+func (a *ArrayType) SetComment(comment string) {
+	a.documentation.comment = comment
+}
+
+// SetComment sets the comment (for maintainers) of the type
+func (a *ArrayItemType) SetComment(_ string) {}
+
+// SetComment sets the comment (for maintainers) of the type
+func (s *ScalarType) SetComment(comment string) {
+	s.documentation.comment = comment
+}
+
+// SetComment sets the comment (for maintainers) of the type
+func (a *AnyType) SetComment(comment string) {
+	a.documentation.comment = comment
+}
+
+// SetComment sets the comment (for maintainers) of the type
+func (n *NullType) SetComment(comment string) {
+	n.documentation.comment = comment
+}
+
 // GetExamples provides descriptive example information
 func (t *DocumentType) GetExamples() []Example {
 	return nil
@@ -576,6 +709,159 @@ func (n *NullType) SetDeprecated(deprecated bool, notice string) {
 	n.documentation.deprecated = deprecated
 }
 
+// GetAccess provides the readOnly and writeOnly field values
+func (t *DocumentType) GetAccess() (bool, bool) {
+	return false, false
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (m *MapType) GetAccess() (bool, bool) {
+	return m.documentation.readOnly, m.documentation.writeOnly
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (t *MapItemType) GetAccess() (bool, bool) {
+	return false, false
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (a *ArrayType) GetAccess() (bool, bool) {
+	return a.documentation.readOnly, a.documentation.writeOnly
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (a *ArrayItemType) GetAccess() (bool, bool) {
+	return false, false
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (s *ScalarType) GetAccess() (bool, bool) {
+	return s.documentation.readOnly, s.documentation.writeOnly
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (a *AnyType) GetAccess() (bool, bool) {
+	return a.documentation.readOnly, a.documentation.writeOnly
+}
+
+// GetAccess provides the readOnly and writeOnly field values
+func (n *NullType) GetAccess() (bool, bool) {
+	return n.documentation.readOnly, n.documentation.writeOnly
+}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (t *DocumentType) SetAccess(_ bool, _ bool) {}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (m *MapType) SetAccess(readOnly bool, writeOnly bool) {
+	m.documentation.readOnly = readOnly
+	m.documentation.writeOnly = writeOnly
+}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (t *MapItemType) SetAccess(_ bool, _ bool) {}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (a *ArrayType) SetAccess(readOnly bool, writeOnly bool) {
+	a.documentation.readOnly = readOnly
+	a.documentation.writeOnly = writeOnly
+}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (a *ArrayItemType) SetAccess(_ bool, _ bool) {}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (s *ScalarType) SetAccess(readOnly bool, writeOnly bool) {
+	s.documentation.readOnly = readOnly
+	s.documentation.writeOnly = writeOnly
+}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (a *AnyType) SetAccess(readOnly bool, writeOnly bool) {
+	a.documentation.readOnly = readOnly
+	a.documentation.writeOnly = writeOnly
+}
+
+// SetAccess sets the readOnly and writeOnly field values
+func (n *NullType) SetAccess(readOnly bool, writeOnly bool) {
+	n.documentation.readOnly = readOnly
+	n.documentation.writeOnly = writeOnly
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (t *DocumentType) GetExtensions() []Extension {
+	return nil
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (m *MapType) GetExtensions() []Extension {
+	return m.documentation.extensions
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (t *MapItemType) GetExtensions() []Extension {
+	return nil
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (a *ArrayType) GetExtensions() []Extension {
+	return a.documentation.extensions
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (a *ArrayItemType) GetExtensions() []Extension {
+	return nil
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (s *ScalarType) GetExtensions() []Extension {
+	return s.documentation.extensions
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (a *AnyType) GetExtensions() []Extension {
+	return a.documentation.extensions
+}
+
+// GetExtensions provides the vendor extensions given via @schema/extension
+func (n *NullType) GetExtensions() []Extension {
+	return n.documentation.extensions
+}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (t *DocumentType) SetExtensions(_ []Extension) {}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (m *MapType) SetExtensions(exts []Extension) {
+	m.documentation.extensions = exts
+}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (t *MapItemType) SetExtensions(_ []Extension) {}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (a *ArrayType) SetExtensions(exts []Extension) {
+	a.documentation.extensions = exts
+}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (a *ArrayItemType) SetExtensions(_ []Extension) {}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (s *ScalarType) SetExtensions(exts []Extension) {
+	s.documentation.extensions = exts
+}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (a *AnyType) SetExtensions(exts []Extension) {
+	a.documentation.extensions = exts
+}
+
+// SetExtensions sets the vendor extensions given via @schema/extension
+func (n *NullType) SetExtensions(exts []Extension) {
+	n.documentation.extensions = exts
+}
+
 // GetValidation provides the validation from @schema/validation for a node
 func (t *DocumentType) GetValidation() *validations.NodeValidation {
 	return t.validations
@@ -0,0 +1,213 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tsRootName is the name under which the data values are declared in TypeScript.
+const tsRootName = "DataValues"
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScriptDocument holds the document type used for creating TypeScript type definitions (e.g. for programs that
+// generate data values, to have them type-checked as they are written).
+type TypeScriptDocument struct {
+	docType *DocumentType
+
+	declarations []string            // of each interface, in the order they are first referred to
+	declared     map[*MapType]string // name of the interface declared for each map
+	names        map[string]bool     // names already taken
+}
+
+// NewTypeScriptDocument creates an instance of a TypeScriptDocument based on the given DocumentType
+func NewTypeScriptDocument(docType *DocumentType) *TypeScriptDocument {
+	return &TypeScriptDocument{docType: docType}
+}
+
+// AsBytes generates the TypeScript declarations of the type information contained in `docType`: the data values are
+// declared as "DataValues", a map as an interface named after the path to it (e.g. "DataValuesDbPorts" for the map at
+// key "ports" of the map at key "db"), or after its @schema/schema-name. Arrays are declared as `T[]` (tuples as
+// `[A, B]`), scalars as their primitive type, nullable values as `T | null` and values allowed via one_of= as the union of those literals.
+func (t *TypeScriptDocument) AsBytes() []byte {
+	t.declarations = nil
+	t.declared = map[*MapType]string{}
+	t.names = map[string]bool{}
+
+	rootType := t.docType.GetValueType()
+	if root := t.typeOfValue(t.docType, tsRootName); root != tsRootName {
+		// the data values are not a map: they are given a name of their own.
+		t.declarations = append([]string{fmt.Sprintf("export type %s = %s;\n", tsRootName, root)}, t.declarations...)
+	} else if desc := rootType.GetDescription(); desc != "" {
+		t.declarations[0] = tsDocComment(desc, "") + t.declarations[0]
+	}
+	return []byte("// Types of data values, generated by ytt\n\n" + strings.Join(t.declarations, "\n"))
+}
+
+// typeOfValue gives the TypeScript type of the value described by `schemaVal` (a DocumentType, MapItemType or
+// ArrayItemType): the literals allowed by its validation (via one_of=), if any and its value is a scalar; otherwise,
+// the type of its value.
+func (t *TypeScriptDocument) typeOfValue(schemaVal Type, name string) string {
+	valueType := schemaVal.GetValueType()
+	nullType, isNullable := valueType.(*NullType)
+	if isNullable {
+		valueType = nullType.GetValueType()
+	}
+	if _, isScalar := valueType.(*ScalarType); isScalar && schemaVal.GetValidation() != nil {
+		if values, found := schemaVal.GetValidation().HasSimpleOneOf(); found {
+			if isNullable && !containsNull(values) {
+				values = append(values, nil)
+			}
+			var literals []string
+			for _, value := range values {
+				literals = append(literals, asJSON(value))
+			}
+			return strings.Join(literals, " | ")
+		}
+	}
+	return t.typeOf(schemaVal.GetValueType(), name)
+}
+
+func (t *TypeScriptDocument) typeOf(typ Type, name string) string {
+	switch typedType := typ.(type) {
+	case *MapType:
+		return t.declareInterface(typedType, name)
+
+	case *ArrayType:
+		if typedType.tupleItems != nil {
+			var itemTypes []string
+			for i, tupleItem := range typedType.tupleItems {
+				itemTypes = append(itemTypes, t.typeOfValue(tupleItem, fmt.Sprintf("%sItem%d", name, i+1)))
+			}
+			return "[" + strings.Join(itemTypes, ", ") + "]"
+		}
+		itemType := t.typeOfValue(typedType.GetValueType(), name+"Item")
+		arrayType := itemType + "[]"
+		if strings.Contains(itemType, " | ") {
+			arrayType = "(" + itemType + ")[]"
+		}
+		if typedType.scalarOrArray {
+			// either a single item, or the array of them (see @schema/scalar-or-array).
+			return itemType + " | " + arrayType
+		}
+		return arrayType
+
+	case *ScalarType:
+		switch typedType.ValueType.(type) {
+		case string:
+			return "string"
+		case int64, float64:
+			return "number"
+		case bool:
+			return "boolean"
+		default:
+			panic(fmt.Sprintf("Unrecognized scalar type %T", typedType.ValueType))
+		}
+
+	case *NullType:
+		if typedType.GetValueType() == nil {
+			// declared as null, with no other type.
+			return "null"
+		}
+		return t.typeOf(typedType.GetValueType(), name) + " | null"
+
+	case *AnyType:
+		return "any"
+
+	default:
+		panic(fmt.Sprintf("Unrecognized type %T", typ))
+	}
+}
+
+// declareInterface declares (once) the interface describing `mapType`, named `name` unless the map is given a schema
+// name, and returns the name under which it is declared.
+func (t *TypeScriptDocument) declareInterface(mapType *MapType, name string) string {
+	if declaredName, found := t.declared[mapType]; found {
+		return declaredName
+	}
+	if mapType.schemaName != "" {
+		name = tsTypeName(mapType.schemaName)
+		if t.names[name] {
+			// maps of the same schema name are alike (see checkSchemaNames()).
+			return name
+		}
+	}
+	name = t.uniqueName(name)
+	t.declared[mapType] = name
+	t.names[name] = true
+
+	// reserved ahead of the interfaces of its values, so that declarations read from the outermost in.
+	index := len(t.declarations)
+	t.declarations = append(t.declarations, "")
+
+	var members []string
+	for _, item := range mapType.Items {
+		member := fmt.Sprintf("  %s: %s;\n", tsPropertyName(item.Key), t.typeOfValue(item, name+tsTypeName(fmt.Sprintf("%v", item.Key))))
+		if desc := item.GetValueType().GetDescription(); desc != "" {
+			member = tsDocComment(desc, "  ") + member
+		}
+		members = append(members, member)
+	}
+	if mapType.allowExtraProperties || mapType.patternProperty != nil {
+		// the declared keys must also conform to the type of any other key.
+		members = append(members, "  [key: string]: any;\n")
+	}
+	t.declarations[index] = fmt.Sprintf("export interface %s {\n%s}\n", name, strings.Join(members, ""))
+	return name
+}
+
+// uniqueName gives `name`, suffixed with a number if it is already taken (e.g. by the path to another map).
+func (t *TypeScriptDocument) uniqueName(name string) string {
+	unique := name
+	for i := 2; t.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	return unique
+}
+
+// tsTypeName converts `s` (e.g. a key) to PascalCase, e.g. "db_host" to "DbHost".
+func tsTypeName(s string) string {
+	var name strings.Builder
+	upperNext := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+// tsPropertyName gives `key` as a property name: as is when it is an identifier, otherwise quoted.
+func tsPropertyName(key interface{}) string {
+	name := fmt.Sprintf("%v", key)
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return asJSON(name)
+}
+
+// tsDocComment formats `desc` as a documentation comment, indented by `indent`.
+func tsDocComment(desc, indent string) string {
+	lines := strings.Split(strings.ReplaceAll(desc, "*/", "*\\/"), "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	var comment strings.Builder
+	comment.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		comment.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	comment.WriteString(indent + " */\n")
+	return comment.String()
+}
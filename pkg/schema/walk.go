@@ -0,0 +1,78 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+)
+
+// TypeVisitor performs an operation on each type of a schema, while traversing it (see DocumentType.Walk()).
+//
+// Each method is given the type visited, the type containing it (nil, for the document's value), and the path to the
+// value described by that type (e.g. "foo.bar[]" for the items of the array at key "bar" of the map at key "foo").
+type TypeVisitor interface {
+	VisitMap(typ *MapType, parent Type, path string) error
+	VisitArray(typ *ArrayType, parent Type, path string) error
+	VisitScalar(typ *ScalarType, parent Type, path string) error
+	VisitNull(typ *NullType, parent Type, path string) error
+	VisitAny(typ *AnyType, parent Type, path string) error
+}
+
+// Walk traverses the types of this schema, recursively, depth-first, invoking `v` on each: a map before its
+// (declared) keys, an array before its items, a nullable value before the type of its non-null values.
+// If `v` returns non-nil error, the traversal is aborted.
+func (t *DocumentType) Walk(v TypeVisitor) error {
+	return walkType(t.GetValueType(), nil, "", v)
+}
+
+func walkType(typ Type, parent Type, path string, v TypeVisitor) error {
+	switch typedType := typ.(type) {
+	case *MapType:
+		if err := v.VisitMap(typedType, parent, path); err != nil {
+			return err
+		}
+		for _, item := range typedType.Items {
+			if err := walkType(item.GetValueType(), typedType, pathToKey(path, item.Key), v); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ArrayType:
+		if err := v.VisitArray(typedType, parent, path); err != nil {
+			return err
+		}
+		for i, itemType := range typedType.tupleItems {
+			if err := walkType(itemType.GetValueType(), typedType, fmt.Sprintf("%s[%d]", path, i), v); err != nil {
+				return err
+			}
+		}
+		if typedType.tupleItems != nil {
+			return nil
+		}
+		return walkType(typedType.GetValueType().GetValueType(), typedType, path+"[]", v)
+
+	case *ScalarType:
+		return v.VisitScalar(typedType, parent, path)
+
+	case *NullType:
+		if err := v.VisitNull(typedType, parent, path); err != nil {
+			return err
+		}
+		return walkType(typedType.GetValueType(), typedType, path, v)
+
+	case *AnyType:
+		return v.VisitAny(typedType, parent, path)
+
+	default:
+		panic(fmt.Sprintf("Unrecognized type %T", typ))
+	}
+}
+
+func pathToKey(path string, key interface{}) string {
+	if path == "" {
+		return fmt.Sprintf("%v", key)
+	}
+	return fmt.Sprintf("%s.%v", path, key)
+}
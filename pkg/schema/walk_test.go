@@ -0,0 +1,49 @@
+// Copyright 2024 The Carvel Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"testing"
+
+	"carvel.dev/ytt/pkg/schema"
+	"carvel.dev/ytt/pkg/template"
+	"carvel.dev/ytt/pkg/yamlmeta"
+	"github.com/stretchr/testify/require"
+)
+
+// scalarCounter notes the path of each scalar visited.
+type scalarCounter struct {
+	paths []string
+}
+
+func (s *scalarCounter) VisitMap(*schema.MapType, schema.Type, string) error     { return nil }
+func (s *scalarCounter) VisitArray(*schema.ArrayType, schema.Type, string) error { return nil }
+func (s *scalarCounter) VisitNull(*schema.NullType, schema.Type, string) error   { return nil }
+func (s *scalarCounter) VisitAny(*schema.AnyType, schema.Type, string) error     { return nil }
+func (s *scalarCounter) VisitScalar(_ *schema.ScalarType, _ schema.Type, path string) error {
+	s.paths = append(s.paths, path)
+	return nil
+}
+
+func TestDocumentType_Walk(t *testing.T) {
+	docSet, err := yamlmeta.NewDocumentSetFromBytes([]byte(`
+name: ""
+db:
+  port: 5432
+  replicas:
+  - host: ""
+    weight: 1.0
+nickname: ""
+`), yamlmeta.DocSetOpts{AssociatedName: "schema.yml"})
+	require.NoError(t, err)
+	docSet.Items[0].Value.(*yamlmeta.Map).Items[2].SetAnnotations(template.NodeAnnotations{
+		schema.AnnotationNullable: template.NodeAnnotation{},
+	})
+	docType, err := schema.NewDocumentType(docSet.Items[0])
+	require.NoError(t, err)
+
+	counter := &scalarCounter{}
+	require.NoError(t, docType.Walk(counter))
+	require.Equal(t, []string{"name", "db.port", "db.replicas[].host", "db.replicas[].weight", "nickname"}, counter.paths)
+}
@@ -5,6 +5,7 @@ package validations
 
 import (
 	"fmt"
+	"regexp"
 
 	"carvel.dev/ytt/pkg/filepos"
 	"carvel.dev/ytt/pkg/template"
@@ -16,14 +17,24 @@ import (
 const (
 	AnnotationAssertValidate template.AnnotationName = "assert/validate"
 
-	KwargWhen       string = "when"
-	KwargMinLength  string = "min_len"
-	KwargMaxLength  string = "max_len"
-	KwargMin        string = "min"
-	KwargMax        string = "max"
-	KwargNotNull    string = "not_null"
-	KwargOneNotNull string = "one_not_null"
-	KwargOneOf      string = "one_of"
+	KwargWhen          string = "when"
+	KwargMinLength     string = "min_len"
+	KwargMaxLength     string = "max_len"
+	KwargMinProperties string = "min_props"
+	KwargMaxProperties string = "max_props"
+	KwargMin           string = "min"
+	KwargMax           string = "max"
+	KwargExclusive     string = "exclusive"
+	KwargMultipleOf    string = "multiple_of"
+	KwargPattern       string = "pattern"
+	KwargNotPattern    string = "not_pattern"
+	KwargUnique        string = "unique"
+	KwargContains      string = "contains"
+	KwargMinContains   string = "min_contains"
+	KwargMaxContains   string = "max_contains"
+	KwargNotNull       string = "not_null"
+	KwargOneNotNull    string = "one_not_null"
+	KwargOneOf         string = "one_of"
 )
 
 // ProcessAssertValidateAnns checks Assert annotations on data values and stores them on a Node as Validations.
@@ -149,10 +160,65 @@ func newValidationKwargs(kwargs []starlark.Tuple, annPos *filepos.Position) (val
 				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMaxLength, value[1].Type(), annPos.AsCompactString())
 			}
 			processedKwargs.maxLength = &v
+		case KwargMinProperties:
+			v, err := starlark.NumberToInt(value[1])
+			if err != nil {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMinProperties, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.minProperties = &v
+		case KwargMaxProperties:
+			v, err := starlark.NumberToInt(value[1])
+			if err != nil {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a number, but was %s (at %s)", KwargMaxProperties, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.maxProperties = &v
 		case KwargMin:
 			processedKwargs.min = value[1]
 		case KwargMax:
 			processedKwargs.max = value[1]
+		case KwargExclusive:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargExclusive, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.exclusive = bool(v)
+		case KwargMultipleOf:
+			if !isPositiveNumber(value[1]) {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a positive number, but was %s (at %s)", KwargMultipleOf, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.multipleOf = value[1]
+		case KwargPattern:
+			v, err := patternsFrom(value[1])
+			if err != nil {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be %s (at %s)", KwargPattern, err, annPos.AsCompactString())
+			}
+			processedKwargs.patterns = v
+		case KwargNotPattern:
+			v, err := patternsFrom(value[1])
+			if err != nil {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be %s (at %s)", KwargNotPattern, err, annPos.AsCompactString())
+			}
+			processedKwargs.notPatterns = v
+		case KwargUnique:
+			v, ok := value[1].(starlark.Bool)
+			if !ok {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a boolean, but was %s (at %s)", KwargUnique, value[1].Type(), annPos.AsCompactString())
+			}
+			processedKwargs.unique = bool(v)
+		case KwargContains:
+			processedKwargs.contains = value[1]
+		case KwargMinContains:
+			v, ok := value[1].(starlark.Int)
+			if !ok || v.Sign() < 0 {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a non-negative integer, but was %s (at %s)", KwargMinContains, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.minContains = &v
+		case KwargMaxContains:
+			v, ok := value[1].(starlark.Int)
+			if !ok || v.Sign() < 0 {
+				return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be a non-negative integer, but was %s (at %s)", KwargMaxContains, value[1].String(), annPos.AsCompactString())
+			}
+			processedKwargs.maxContains = &v
 		case KwargNotNull:
 			v, ok := value[1].(starlark.Bool)
 			if !ok {
@@ -182,5 +248,53 @@ func newValidationKwargs(kwargs []starlark.Tuple, annPos *filepos.Position) (val
 			return validationKwargs{}, fmt.Errorf("unknown keyword argument %q (at %s)", kwargName, annPos.AsCompactString())
 		}
 	}
+	if processedKwargs.exclusive && processedKwargs.min == nil && processedKwargs.max == nil {
+		return validationKwargs{}, fmt.Errorf("expected keyword argument %q to be given along with %q and/or %q (at %s)", KwargExclusive, KwargMin, KwargMax, annPos.AsCompactString())
+	}
+	if processedKwargs.contains == nil && (processedKwargs.minContains != nil || processedKwargs.maxContains != nil) {
+		return validationKwargs{}, fmt.Errorf("expected keyword arguments %q and %q to be given along with %q (at %s)", KwargMinContains, KwargMaxContains, KwargContains, annPos.AsCompactString())
+	}
 	return processedKwargs, nil
 }
+
+// isPositiveNumber indicates whether `value` is an int or float greater than zero.
+func isPositiveNumber(value starlark.Value) bool {
+	switch number := value.(type) {
+	case starlark.Int:
+		return number.Sign() > 0
+	case starlark.Float:
+		return number > 0
+	}
+	return false
+}
+
+// patternsFrom extracts the regular expression(s) given as either a string or a sequence of strings.
+func patternsFrom(value starlark.Value) ([]string, error) {
+	var values []starlark.Value
+	switch typedValue := value.(type) {
+	case starlark.String:
+		values = []starlark.Value{typedValue}
+	case starlark.Sequence:
+		iter := starlark.Iterate(typedValue)
+		defer iter.Done()
+		var x starlark.Value
+		for iter.Next(&x) {
+			values = append(values, x)
+		}
+	default:
+		return nil, fmt.Errorf("a string or a sequence of strings, but was %s", value.Type())
+	}
+
+	var patterns []string
+	for _, val := range values {
+		pattern, ok := val.(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("a string or a sequence of strings, but contained %s", val.Type())
+		}
+		if _, err := regexp.Compile(string(pattern)); err != nil {
+			return nil, fmt.Errorf("a valid regular expression, but %s", err)
+		}
+		patterns = append(patterns, string(pattern))
+	}
+	return patterns, nil
+}
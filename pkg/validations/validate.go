@@ -46,14 +46,24 @@ func byPriority(rules []rule) []rule {
 
 // validationKwargs represent the optional keyword arguments and their values in a validationRun annotation.
 type validationKwargs struct {
-	when       starlark.Callable
-	minLength  *starlark.Int // 0 len("") == 0, this always passes
-	maxLength  *starlark.Int
-	min        starlark.Value
-	max        starlark.Value
-	notNull    bool
-	oneNotNull starlark.Value // valid values are either starlark.Sequence or starlark.Bool
-	oneOf      starlark.Sequence
+	when          starlark.Callable
+	minLength     *starlark.Int // 0 len("") == 0, this always passes
+	maxLength     *starlark.Int
+	minProperties *starlark.Int // of maps only, the number of keys
+	maxProperties *starlark.Int
+	min           starlark.Value
+	max           starlark.Value
+	exclusive     bool // whether min and max are themselves excluded from the allowed range
+	multipleOf    starlark.Value
+	patterns      []string
+	notPatterns   []string       // regular expressions a string must not match
+	unique        bool           // whether the items of a sequence must be distinct
+	contains      starlark.Value // the one value that (some of) the items of a sequence must be equal to
+	minContains   *starlark.Int  // how many items must be equal to it (at least one, if not given)
+	maxContains   *starlark.Int
+	notNull       bool
+	oneNotNull    starlark.Value // valid values are either starlark.Sequence or starlark.Bool
+	oneOf         starlark.Sequence
 }
 
 // Run takes a root Node, and threadName, and validates each Node in the tree.
@@ -138,6 +148,38 @@ func (v NodeValidation) HasSimpleMaxLength() (int64, bool) {
 	return 0, false
 }
 
+// HasSimpleMinProperties indicates presence of min props validation (of the number of keys of a map) and its
+// associated value.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleMinProperties() (int64, bool) {
+	if v.kwargs.when != nil {
+		return 0, false
+	}
+	if v.kwargs.minProperties != nil {
+		value, ok := v.kwargs.minProperties.Int64()
+		if ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// HasSimpleMaxProperties indicates presence of max props validation (of the number of keys of a map) and its
+// associated value.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleMaxProperties() (int64, bool) {
+	if v.kwargs.when != nil {
+		return 0, false
+	}
+	if v.kwargs.maxProperties != nil {
+		value, ok := v.kwargs.maxProperties.Int64()
+		if ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
 // HasSimpleMin indicates presence of min validation and its associated value.
 // Returns false if validation is conditional (via when=).
 func (v NodeValidation) HasSimpleMin() (interface{}, bool) {
@@ -168,6 +210,107 @@ func (v NodeValidation) HasSimpleMax() (interface{}, bool) {
 	return nil, false
 }
 
+// HasSimpleNotNull indicates presence of not-null validation.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleNotNull() bool {
+	if v.kwargs.when != nil {
+		return false
+	}
+	return v.kwargs.notNull
+}
+
+// HasSimpleMultipleOf indicates presence of multiple-of validation and the number the value must be a multiple of.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleMultipleOf() (interface{}, bool) {
+	if v.kwargs.when != nil {
+		return nil, false
+	}
+	if v.kwargs.multipleOf != nil {
+		value, err := core.NewStarlarkValue(v.kwargs.multipleOf).AsGoValue()
+		if err == nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// HasExclusiveBounds indicates whether the values given for min and max are themselves out of bounds.
+func (v NodeValidation) HasExclusiveBounds() bool {
+	return v.kwargs.exclusive
+}
+
+// HasSimplePatterns indicates presence of pattern validation and the regular expression(s) to be matched.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimplePatterns() ([]string, bool) {
+	if v.kwargs.when != nil {
+		return nil, false
+	}
+	return v.kwargs.patterns, len(v.kwargs.patterns) > 0
+}
+
+// HasSimpleNotPatterns indicates presence of not_pattern validation and the regular expression(s) not to be matched.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleNotPatterns() ([]string, bool) {
+	if v.kwargs.when != nil {
+		return nil, false
+	}
+	return v.kwargs.notPatterns, len(v.kwargs.notPatterns) > 0
+}
+
+// HasSimpleUnique indicates presence of a validation that items are unique.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleUnique() bool {
+	if v.kwargs.when != nil {
+		return false
+	}
+	return v.kwargs.unique
+}
+
+// HasSimpleContains indicates presence of a contains validation and the value that items must be equal to.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleContains() (interface{}, bool) {
+	if v.kwargs.when != nil {
+		return nil, false
+	}
+	if v.kwargs.contains != nil {
+		value, err := core.NewStarlarkValue(v.kwargs.contains).AsGoValue()
+		if err == nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// HasSimpleMinContains indicates presence of a minimum number of items equal to the value of a contains validation.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleMinContains() (int64, bool) {
+	if v.kwargs.when != nil {
+		return 0, false
+	}
+	if v.kwargs.minContains != nil {
+		value, ok := v.kwargs.minContains.Int64()
+		if ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// HasSimpleMaxContains indicates presence of a maximum number of items equal to the value of a contains validation.
+// Returns false if validation is conditional (via when=).
+func (v NodeValidation) HasSimpleMaxContains() (int64, bool) {
+	if v.kwargs.when != nil {
+		return 0, false
+	}
+	if v.kwargs.maxContains != nil {
+		value, ok := v.kwargs.maxContains.Int64()
+		if ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
 // HasSimpleOneOf indicates presence of one-of validation and its allowed values.
 // Returns false if validation is conditional (via when=).
 func (v NodeValidation) HasSimpleOneOf() ([]interface{}, bool) {
@@ -192,6 +335,15 @@ func (v NodeValidation) HasSimpleOneOf() ([]interface{}, bool) {
 	return nil, false
 }
 
+// Messages lists the description of each rule of this validation (of what constitutes a valid value), in order.
+func (v NodeValidation) Messages() []string {
+	var messages []string
+	for _, r := range v.rules {
+		messages = append(messages, r.msg)
+	}
+	return messages
+}
+
 // Validate runs the assertions in the rules with the node's value as arguments IF
 // the ValidationKwargs conditional options pass.
 //
@@ -311,16 +463,82 @@ func (v validationKwargs) asRules() []rule {
 			assertion: yttlibrary.NewAssertMaxLen(*v.maxLength).CheckFunc(),
 		})
 	}
-	if v.min != nil {
+	if v.minProperties != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a map of >= %v keys", *v.minProperties),
+			assertion: yttlibrary.NewAssertMinProps(*v.minProperties).CheckFunc(),
+		})
+	}
+	if v.maxProperties != nil {
 		rules = append(rules, rule{
-			msg:       fmt.Sprintf("a value >= %v", v.min),
-			assertion: yttlibrary.NewAssertMin(v.min).CheckFunc(),
+			msg:       fmt.Sprintf("a map of <= %v keys", *v.maxProperties),
+			assertion: yttlibrary.NewAssertMaxProps(*v.maxProperties).CheckFunc(),
 		})
 	}
+	if v.min != nil {
+		if v.exclusive {
+			rules = append(rules, rule{
+				msg:       fmt.Sprintf("a value > %v", v.min),
+				assertion: yttlibrary.NewAssertExclusiveMin(v.min).CheckFunc(),
+			})
+		} else {
+			rules = append(rules, rule{
+				msg:       fmt.Sprintf("a value >= %v", v.min),
+				assertion: yttlibrary.NewAssertMin(v.min).CheckFunc(),
+			})
+		}
+	}
 	if v.max != nil {
+		if v.exclusive {
+			rules = append(rules, rule{
+				msg:       fmt.Sprintf("a value < %v", v.max),
+				assertion: yttlibrary.NewAssertExclusiveMax(v.max).CheckFunc(),
+			})
+		} else {
+			rules = append(rules, rule{
+				msg:       fmt.Sprintf("a value <= %v", v.max),
+				assertion: yttlibrary.NewAssertMax(v.max).CheckFunc(),
+			})
+		}
+	}
+	if v.multipleOf != nil {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a multiple of %v", v.multipleOf),
+			assertion: yttlibrary.NewAssertMultipleOf(v.multipleOf).CheckFunc(),
+		})
+	}
+	for _, pattern := range v.patterns {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a value matching %s", pattern),
+			assertion: yttlibrary.NewAssertMatches(pattern).CheckFunc(),
+		})
+	}
+	for _, pattern := range v.notPatterns {
+		rules = append(rules, rule{
+			msg:       fmt.Sprintf("a value not matching %s", pattern),
+			assertion: yttlibrary.NewAssertNotMatches(pattern).CheckFunc(),
+		})
+	}
+	if v.unique {
+		rules = append(rules, rule{
+			msg:       "a sequence of unique items",
+			assertion: yttlibrary.NewAssertUnique().CheckFunc(),
+		})
+	}
+	if v.contains != nil {
+		minContains, maxContains := starlark.MakeInt(1), starlark.Value(starlark.None)
+		msg := fmt.Sprintf("a sequence of >= 1 items equal to %s", v.contains.String())
+		if v.minContains != nil {
+			minContains = *v.minContains
+			msg = fmt.Sprintf("a sequence of >= %v items equal to %s", *v.minContains, v.contains.String())
+		}
+		if v.maxContains != nil {
+			maxContains = *v.maxContains
+			msg += fmt.Sprintf(" (and <= %v)", *v.maxContains)
+		}
 		rules = append(rules, rule{
-			msg:       fmt.Sprintf("a value <= %v", v.max),
-			assertion: yttlibrary.NewAssertMax(v.max).CheckFunc(),
+			msg:       msg,
+			assertion: yttlibrary.NewAssertContains(v.contains, minContains, maxContains).CheckFunc(),
 		})
 	}
 	if v.notNull {
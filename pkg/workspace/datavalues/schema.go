@@ -18,6 +18,8 @@ type Schema struct {
 	Source     *yamlmeta.Document
 	defaultDVs *yamlmeta.Document
 	DocType    *schema.DocumentType
+
+	acceptsKeysOfDataValues bool
 }
 
 // SchemaEnvelope is addressing and usage bookkeeping for a Schema — for which library this Schema is intended.
@@ -91,8 +93,31 @@ func getSchemaLibRef(libRefs ExtractLibRefs, doc *yamlmeta.Document) ([]ref.Libr
 // AssignType decorates `doc` with type metadata sourced from this Schema.
 // If `doc` does not conform to the AST structure of this Schema, the returned TypeCheck contains the violations.
 // No other type check is performed.
+//
+// If this Schema accepts the keys of data values (see ThatAcceptsKeysOfDataValues()), `doc` is assigned the type of
+// this Schema extended with the keys of `doc` it lacks (which this Schema itself does not declare).
 func (s *Schema) AssignType(doc *yamlmeta.Document) schema.TypeCheck {
-	return s.DocType.AssignTypeTo(doc)
+	docType := s.DocType
+	if s.acceptsKeysOfDataValues {
+		var err error
+		docType, err = docType.WithKeysOf(doc)
+		if err != nil {
+			return schema.TypeCheck{Violations: []error{err}}
+		}
+	}
+	return docType.AssignTypeTo(doc)
+}
+
+// ThatAcceptsKeysOfDataValues produces a Schema identical to this one (sharing its DocumentType), except that keys of
+// data values it does not declare are typed as inferred from their values (see schema.DocumentType.WithKeysOf()),
+// rather than reported as violations.
+func (s *Schema) ThatAcceptsKeysOfDataValues() *Schema {
+	return &Schema{
+		Source:                  s.Source,
+		defaultDVs:              s.defaultDVs,
+		DocType:                 s.DocType,
+		acceptsKeysOfDataValues: true,
+	}
 }
 
 // DefaultDataValues returns a copy of the default values declared in this Schema.
@@ -5,6 +5,7 @@ package yttlibrary
 
 import (
 	"fmt"
+	"math"
 
 	"carvel.dev/ytt/pkg/orderedmap"
 	"carvel.dev/ytt/pkg/template/core"
@@ -263,6 +264,125 @@ func (m AssertModule) Max(_ *starlark.Thread, _ *starlark.Builtin, args starlark
 	return maxFunc, nil
 }
 
+// NewAssertExclusiveMin produces an Assertion that a given value is greater than "min".
+func NewAssertExclusiveMin(min starlark.Value) *Assertion {
+	return NewAssertionFromSource(
+		"assert.exclusive_min",
+		`lambda val: yaml.decode(yaml.encode(val)) > yaml.decode(yaml.encode(min)) or fail("value <= {}".format(yaml.decode(yaml.encode(min))))`,
+		starlark.StringDict{"min": min, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NewAssertExclusiveMax produces an Assertion that a given value is less than "max".
+func NewAssertExclusiveMax(max starlark.Value) *Assertion {
+	return NewAssertionFromSource(
+		"assert.exclusive_max",
+		`lambda val: yaml.decode(yaml.encode(val)) < yaml.decode(yaml.encode(max)) or fail("value >= {}".format(yaml.decode(yaml.encode(max))))`,
+		starlark.StringDict{"max": max, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NewAssertMatches produces an Assertion that a given string matches the regular expression "pattern".
+func NewAssertMatches(pattern string) *Assertion {
+	return NewAssertionFromSource(
+		"assert.matches",
+		`lambda val: regexp.match(pattern, val) or fail("value does not match {}".format(pattern))`,
+		starlark.StringDict{"pattern": starlark.String(pattern), "regexp": RegexpAPI["regexp"]},
+	)
+}
+
+// NewAssertNotMatches produces an Assertion that a given string does not match the regular expression "pattern".
+func NewAssertNotMatches(pattern string) *Assertion {
+	return NewAssertionFromSource(
+		"assert.not_matches",
+		`lambda val: not regexp.match(pattern, val) or fail("value matches {}".format(pattern))`,
+		starlark.StringDict{"pattern": starlark.String(pattern), "regexp": RegexpAPI["regexp"]},
+	)
+}
+
+// NewAssertMultipleOf produces an Assertion that a given value is a number divisible by "multipleOf".
+func NewAssertMultipleOf(multipleOf starlark.Value) *Assertion {
+	return NewAssertionFromSource(
+		"assert.multiple_of",
+		`lambda val: (is_multiple(val, multiple_of) or fail("value is not a multiple of {}".format(multiple_of))) if type(val) in ("int", "float") else fail("value is a {}, not a number".format(type(val)))`,
+		starlark.StringDict{"multiple_of": multipleOf, "is_multiple": starlark.NewBuiltin("is_multiple", isMultiple)},
+	)
+}
+
+// isMultiple is a starlark builtin reporting whether its first (numeric) argument is a multiple of its second.
+func isMultiple(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	var number, divisor starlark.Value
+	if err := starlark.UnpackPositionalArgs("is_multiple", args, nil, 2, &number, &divisor); err != nil {
+		return starlark.None, err
+	}
+	numberInt, isNumberInt := number.(starlark.Int)
+	divisorInt, isDivisorInt := divisor.(starlark.Int)
+	if isNumberInt && isDivisorInt {
+		if divisorInt.Sign() == 0 {
+			return starlark.False, nil
+		}
+		return starlark.Bool(numberInt.Mod(divisorInt).Sign() == 0), nil
+	}
+	numberFloat, ok := starlark.AsFloat(number)
+	if !ok {
+		return starlark.None, fmt.Errorf("expected a number, but was %s", number.Type())
+	}
+	divisorFloat, ok := starlark.AsFloat(divisor)
+	if !ok {
+		return starlark.None, fmt.Errorf("expected a number, but was %s", divisor.Type())
+	}
+	return starlark.Bool(IsMultipleOf(numberFloat, divisorFloat)), nil
+}
+
+// IsMultipleOf reports whether "number" is a multiple of "divisor".
+//
+// Floating-point division is inexact (e.g. 0.3 / 0.1 is 2.9999999999999996), so a quotient within a relative
+// tolerance of a whole number counts as such.
+func IsMultipleOf(number, divisor float64) bool {
+	if divisor == 0 {
+		return false
+	}
+	quotient := number / divisor
+	return math.Abs(quotient-math.Round(quotient)) <= 1e-9*math.Max(1, math.Abs(quotient))
+}
+
+// NewAssertMinProps produces an Assertion that a given value is a map of at least "minimum" keys.
+func NewAssertMinProps(minimum starlark.Int) *Assertion {
+	return NewAssertionFromSource(
+		"assert.min_props",
+		`lambda val: (len(val) >= minimum or fail("value has {} keys".format(len(val)))) if type(yaml.decode(yaml.encode(val))) == "dict" else fail("value is a {}, not a map".format(type(yaml.decode(yaml.encode(val)))))`,
+		starlark.StringDict{"minimum": minimum, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NewAssertMaxProps produces an Assertion that a given value is a map of at most "maximum" keys.
+func NewAssertMaxProps(maximum starlark.Int) *Assertion {
+	return NewAssertionFromSource(
+		"assert.max_props",
+		`lambda val: (len(val) <= maximum or fail("value has {} keys".format(len(val)))) if type(yaml.decode(yaml.encode(val))) == "dict" else fail("value is a {}, not a map".format(type(yaml.decode(yaml.encode(val)))))`,
+		starlark.StringDict{"maximum": maximum, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NewAssertUnique produces an Assertion that a given value is a sequence without duplicate items.
+func NewAssertUnique() *Assertion {
+	return NewAssertionFromSource(
+		"assert.unique",
+		`lambda val: len(val) == len({yaml.encode(item): None for item in val}) or fail("value contains duplicate items")`,
+		starlark.StringDict{"yaml": YAMLAPI["yaml"]},
+	)
+}
+
+// NewAssertContains produces an Assertion that a given value is a sequence of which at least "minimum" items (and, unless
+// "maximum" is None, at most "maximum") are equal to "contains".
+func NewAssertContains(contains starlark.Value, minimum starlark.Int, maximum starlark.Value) *Assertion {
+	return NewAssertionFromSource(
+		"assert.contains",
+		`lambda val: (lambda count: (count >= minimum and (maximum == None or count <= maximum)) or fail("value contains {} matching item(s)".format(count)))(len([item for item in val if yaml.encode(item) == yaml.encode(contains)]))`,
+		starlark.StringDict{"contains": contains, "minimum": minimum, "maximum": maximum, "yaml": YAMLAPI["yaml"]},
+	)
+}
+
 // NewAssertNotNull produces an Assertion that a given value is not null.
 func NewAssertNotNull() *Assertion {
 	return NewAssertionFromSource(
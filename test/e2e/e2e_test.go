@@ -219,6 +219,84 @@ func TestSchema(t *testing.T) {
 	}
 }
 
+func TestSchemaInspect(t *testing.T) {
+	t.Run("exports JSON Schema", func(t *testing.T) {
+		flags := yttFlags{
+			{"--data-values-schema-inspect": ""},
+			{"-o": "json-schema"},
+		}
+		actualOutput := runYtt(t, testInputFiles{"../../examples/schema/schema.yml"}, "", flags, nil)
+
+		expectedOutput, err := os.ReadFile("./assets/schema-inspect-json-schema.json")
+		require.NoError(t, err)
+
+		require.Equal(t, string(expectedOutput), actualOutput)
+	})
+	t.Run("exports JSON Schema draft-07", func(t *testing.T) {
+		flags := yttFlags{
+			{"--data-values-schema-inspect": ""},
+			{"-o": "json-schema"},
+			{"--json-schema-draft": "draft-07"},
+		}
+		actualOutput := runYtt(t, testInputFiles{"../../examples/schema/schema.yml"}, "", flags, nil)
+
+		expectedOutput, err := os.ReadFile("./assets/schema-inspect-json-schema-draft-07.json")
+		require.NoError(t, err)
+
+		require.Equal(t, string(expectedOutput), actualOutput)
+	})
+	t.Run("writes JSON Schema to --output-files", func(t *testing.T) {
+		tempOutputDir, err := os.MkdirTemp(os.TempDir(), "ytt-check-schema-dir")
+		require.NoError(t, err)
+		defer os.RemoveAll(tempOutputDir)
+
+		flags := yttFlags{
+			{"--data-values-schema-inspect": ""},
+			{"-o": "json-schema"},
+			{fmt.Sprintf("--output-files=%s", tempOutputDir): ""},
+		}
+		runYtt(t, testInputFiles{"../../examples/schema/schema.yml"}, "", flags, nil)
+
+		expectedOutput, err := os.ReadFile("./assets/schema-inspect-json-schema.json")
+		require.NoError(t, err)
+
+		actualOutput, err := os.ReadFile(filepath.Join(tempOutputDir, "data-values-schema.json"))
+		require.NoError(t, err)
+
+		require.Equal(t, string(expectedOutput), string(actualOutput))
+	})
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schemas := map[string]string{
+		"JSON Schema": "./assets/schema-inspect-json-schema.json",
+		"ytt schema":  "../../examples/schema/schema.yml",
+	}
+	for kind, schemaPath := range schemas {
+		t.Run(fmt.Sprintf("against a %s, succeeds given conforming values", kind), func(t *testing.T) {
+			command := exec.Command("../../ytt", "schema", "validate", "--schema", schemaPath, "--values", "./assets/schema-validate-values-valid.yml")
+			output, err := command.CombinedOutput()
+			require.NoError(t, err, string(output))
+			require.Equal(t, "", string(output))
+		})
+		t.Run(fmt.Sprintf("against a %s, fails reporting each nonconforming value", kind), func(t *testing.T) {
+			command := exec.Command("../../ytt", "schema", "validate", "--schema", schemaPath, "--values", "./assets/schema-validate-values-invalid.yml")
+			stdError := bytes.NewBufferString("")
+			command.Stderr = stdError
+			_, err := command.Output()
+			require.Error(t, err)
+
+			expectedOutput := fmt.Sprintf(`ytt: Error: Values in './assets/schema-validate-values-invalid.yml' do not conform to schema '%s':
+- values.nothing: expected string or null, got integer
+- values.string: expected string, got array
+- values.int: expected integer, got number
+- values.extra: unexpected key
+`, schemaPath)
+			require.Equal(t, expectedOutput, stdError.String())
+		})
+	}
+}
+
 func TestOverlays(t *testing.T) {
 	dirs := []string{
 		"overlay",